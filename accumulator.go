@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"github.com/IBM/mathlib/driver"
+)
+
+// PairingAccumulator collects pairs whose pairings are multiplied together,
+// e.g. the two sides of a verification equation, and only computes them in
+// Result or Check: with a single Miller loop and final exponentiation on
+// the drivers that implement driver.MultiPairer, and with PairingN followed
+// by FExp on the others.
+type PairingAccumulator struct {
+	curve *Curve
+	p2    []*G2
+	p1    []*G1
+}
+
+// NewPairingAccumulator returns an empty accumulator. It panics with
+// ErrUnsupported on curves without pairings.
+func (c *Curve) NewPairingAccumulator() *PairingAccumulator {
+	if !c.SupportsPairing() {
+		panic(ErrUnsupported)
+	}
+
+	return &PairingAccumulator{curve: c}
+}
+
+// AddPair multiplies the accumulated product by e(g2, g1).
+func (a *PairingAccumulator) AddPair(g2 *G2, g1 *G1) {
+	a.p2 = append(a.p2, g2.Copy())
+	a.p1 = append(a.p1, g1.Copy())
+}
+
+// AddPairInverse multiplies the accumulated product by the inverse of
+// e(g2, g1), i.e. by e(g2, -g1).
+func (a *PairingAccumulator) AddPairInverse(g2 *G2, g1 *G1) {
+	neg := g1.Copy()
+	neg.Neg()
+
+	a.p2 = append(a.p2, g2.Copy())
+	a.p1 = append(a.p1, neg)
+}
+
+// Reset drops the pairs added so far, so that the accumulator can be used
+// for another product.
+func (a *PairingAccumulator) Reset() {
+	for i := range a.p1 {
+		a.p2[i], a.p1[i] = nil, nil
+	}
+	a.p2, a.p1 = a.p2[:0], a.p1[:0]
+}
+
+// Result returns the product of the pairings of the pairs added so far,
+// final exponentiation included; it is the unity of Gt if there are none.
+func (a *PairingAccumulator) Result() *Gt {
+	c := a.curve
+
+	if len(a.p1) == 0 {
+		return c.IdentityGt()
+	}
+
+	mp, ok := c.c.(driver.MultiPairer)
+	if !ok {
+		res, err := c.PairingN(a.p2, a.p1)
+		if err != nil {
+			panic(err)
+		}
+		return c.FExp(res)
+	}
+
+	p2 := make([]driver.G2, len(a.p2))
+	p1 := make([]driver.G1, len(a.p1))
+	for i := range a.p1 {
+		p2[i] = a.p2[i].g2
+		p1[i] = a.p1[i].g1
+	}
+
+	return &Gt{gt: mp.MultiPairing(p2, p1), curve: c}
+}
+
+// Check reports whether the product of the pairings of the pairs added so
+// far is the unity of Gt, as verification equations require.
+func (a *PairingAccumulator) Check() bool {
+	return a.Result().IsUnity()
+}
+
+// PairingEngine is a PairingAccumulator shaped after the Engine of the
+// kilic library, for code that verifies many equations in a row: Check or
+// Result, then Reset and the pairs of the next one.
+type PairingEngine struct {
+	PairingAccumulator
+}
+
+// NewPairingEngine returns an empty engine. It panics with ErrUnsupported
+// on curves without pairings.
+func (c *Curve) NewPairingEngine() *PairingEngine {
+	return &PairingEngine{PairingAccumulator: *c.NewPairingAccumulator()}
+}
+
+// AddNegPair is AddPairInverse, named as in the kilic library.
+func (e *PairingEngine) AddNegPair(g2 *G2, g1 *G1) {
+	e.AddPairInverse(g2, g1)
+}
+
+// PairingCheckNeg reports whether e(a2, a1) * e(b2, -b1) is the unity of
+// Gt, i.e. whether e(a2, a1) = e(b2, b1), e.g. PairingCheckNeg(pk, H, g,
+// sig) for a BLS signature sig on H under the public key pk = g^sk. b1 is
+// not modified. It panics with ErrUnsupported on curves without pairings.
+func (c *Curve) PairingCheckNeg(a2 *G2, a1 *G1, b2 *G2, b1 *G1) bool {
+	e := c.NewPairingEngine()
+	e.AddPair(a2, a1)
+	e.AddNegPair(b2, b1)
+	return e.Check()
+}
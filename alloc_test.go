@@ -0,0 +1,76 @@
+//go:build !race
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The race detector instruments the code with allocations of its own, so
+// that the counts below only hold without it.
+
+func TestInPlaceAllocs(t *testing.T) {
+	for _, id := range []CurveID{BN254, BLS12_377_GURVY, BLS12_381_GURVY, BLS12_381_BBS_GURVY, BLS24_315_GURVY} {
+		c := Curves[id]
+		rng, err := c.Rand()
+		assert.NoError(t, err)
+
+		x, y, r := c.NewRandomZr(rng), c.NewRandomZr(rng), c.NewRandomZr(rng)
+		z := r.Copy()
+		allocs := testing.AllocsPerRun(100, func() {
+			z.Clone(r)
+			z.MulInPlace(x)
+			z.AddInPlace(y)
+			z.SubInPlace(x)
+		})
+		assert.LessOrEqual(t, allocs, float64(1), fmt.Sprintf("failed with curve %T", c.c))
+		assert.True(t, z.Equals(r.Mul(x).Plus(y).Minus(x)), fmt.Sprintf("failed with curve %T", c.c))
+	}
+}
+
+func TestBytesIntoAllocs(t *testing.T) {
+	for _, id := range []CurveID{BN254, BLS12_377_GURVY, BLS12_381_GURVY, BLS12_381_BBS_GURVY, BLS24_315_GURVY} {
+		c := Curves[id]
+		rng, err := c.Rand()
+		assert.NoError(t, err)
+
+		r := c.NewRandomZr(rng)
+		g1, g2, gt := c.GenG1.Mul(r), c.GenG2.Mul(r), c.GenGt.Exp(r)
+		buf := make([]byte, c.GtByteSize)
+
+		for _, into := range []func([]byte) (int, error){g1.BytesInto, g1.CompressedInto, g2.BytesInto, g2.CompressedInto, gt.BytesInto} {
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := into(buf); err != nil {
+					panic(err)
+				}
+			})
+			assert.Zero(t, allocs, fmt.Sprintf("failed with curve %T", c.c))
+		}
+	}
+
+	// the scalars of the BLS12-381 drivers are held in the field of their
+	// library
+	for _, id := range built([]CurveID{BLS12_381, BLS12_381_GURVY, BLS12_381_BBS, BLS12_381_BBS_GURVY}) {
+		c := Curves[id]
+		rng, err := c.Rand()
+		assert.NoError(t, err)
+
+		r := c.NewRandomZr(rng)
+		buf := make([]byte, c.ScalarByteSize)
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := r.BytesInto(buf); err != nil {
+				panic(err)
+			}
+		})
+		assert.Zero(t, allocs, fmt.Sprintf("failed with curve %T", c.c))
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bbs implements the hash_to_scalar and create_generators procedures
+// of the BBS signature scheme, draft-irtf-cfrg-bbs-signatures, for its
+// BLS12-381 ciphersuites. They work on any of the BLS12-381 curves, e.g.
+// BLS12_381_BBS and BLS12_381_BBS_GURVY, which all return the same values.
+package bbs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	math "github.com/IBM/mathlib"
+)
+
+// expandLen is the expand_len of the ciphersuites, ceil((ceil(log2(r))+k)/8)
+// for k = 128.
+const expandLen = 48
+
+// Ciphersuite is one of the BLS12-381 ciphersuites of the draft, which only
+// differ in their hash function.
+type Ciphersuite struct {
+	// ID is the ciphersuite_id.
+	ID string
+
+	expand func(msg, dst []byte, n int) []byte
+}
+
+var (
+	// SHA256 is BLS12-381-SHA-256, which hashes with expand_message_xmd and
+	// SHA-256.
+	SHA256 = &Ciphersuite{ID: "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_", expand: expandMessageXMD}
+
+	// SHAKE256 is BLS12-381-SHAKE-256, which hashes with expand_message_xof
+	// and SHAKE-256.
+	SHAKE256 = &Ciphersuite{ID: "BBS_BLS12381G1_XOF:SHAKE-256_SSWU_RO_", expand: expandMessageXOF}
+)
+
+// APIID returns the api_id of the signature interface of the draft,
+// ciphersuite_id || "H2G_HM2S_", which prefixes its domain separation tags.
+func (s *Ciphersuite) APIID() []byte {
+	return []byte(s.ID + "H2G_HM2S_")
+}
+
+// HashToScalar returns hash_to_scalar(msg, dst), the output of expand_message
+// on msg and dst read as an integer modulo the group order. The draft uses
+// APIID() || "H2S_" as dst for the messages. It fails unless c is one of the
+// BLS12-381 curves.
+func (s *Ciphersuite) HashToScalar(c *math.Curve, msg, dst []byte) (*math.Zr, error) {
+	if err := checkCurve(c); err != nil {
+		return nil, err
+	}
+
+	return c.NewZrFromBytes(s.expand(msg, dst, expandLen)), nil
+}
+
+// CreateGenerators returns create_generators(count, apiID), count points of
+// G1 whose discrete logarithms nobody knows. The signature interface takes
+// Q_1 and then a generator per message from CreateGenerators(c, L+1,
+// APIID()). It fails unless c is one of the BLS12-381 curves.
+func (s *Ciphersuite) CreateGenerators(c *math.Curve, count int, apiID []byte) ([]*math.G1, error) {
+	return s.createGenerators(c, count, apiID, []byte("MESSAGE_GENERATOR_SEED"))
+}
+
+// P1 returns the fixed point P1 of the ciphersuite, which the draft derives
+// like the generators from the seed "BP_MESSAGE_GENERATOR_SEED".
+func (s *Ciphersuite) P1(c *math.Curve) (*math.G1, error) {
+	g, err := s.createGenerators(c, 1, s.APIID(), []byte("BP_MESSAGE_GENERATOR_SEED"))
+	if err != nil {
+		return nil, err
+	}
+
+	return g[0], nil
+}
+
+func (s *Ciphersuite) createGenerators(c *math.Curve, count int, apiID, seed []byte) ([]*math.G1, error) {
+	if err := checkCurve(c); err != nil {
+		return nil, err
+	}
+
+	seedDST := concat(apiID, []byte("SIG_GENERATOR_SEED_"))
+	generatorDST := concat(apiID, []byte("SIG_GENERATOR_DST_"))
+
+	v := s.expand(concat(apiID, seed), seedDST, expandLen)
+	generators := make([]*math.G1, 0, count)
+	for i := 1; i <= count; i++ {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(i))
+		v = s.expand(concat(v, n[:]), seedDST, expandLen)
+
+		g, err := s.hashToCurve(c, v, generatorDST)
+		if err != nil {
+			return nil, err
+		}
+		generators = append(generators, g)
+	}
+
+	return generators, nil
+}
+
+// hashToCurve is the hash_to_curve of RFC 9380 for the BLS12381G1 suite
+// with the hash function of s: the sum of the maps of two field elements
+// then goes through clear_cofactor, which MapToG1FromBytes applies to each.
+func (s *Ciphersuite) hashToCurve(c *math.Curve, msg, dst []byte) (*math.G1, error) {
+	info, err := c.Info()
+	if err != nil {
+		return nil, err
+	}
+	p := new(big.Int).SetBytes(info.BaseFieldModulus)
+
+	// L = ceil((ceil(log2(p)) + k) / 8)
+	const l = 64
+	uniform := s.expand(msg, dst, 2*l)
+
+	res := c.InfinityG1()
+	for i := 0; i < 2; i++ {
+		u := new(big.Int).SetBytes(uniform[i*l : (i+1)*l])
+		u.Mod(u, p)
+
+		q, err := c.MapToG1FromBytes(u.FillBytes(make([]byte, c.CoordByteSize)))
+		if err != nil {
+			return nil, err
+		}
+		res.Add(q)
+	}
+
+	return res, nil
+}
+
+func checkCurve(c *math.Curve) error {
+	switch id := c.GenG1.CurveID(); id {
+	case math.BLS12_381, math.BLS12_381_GURVY, math.BLS12_381_BBS, math.BLS12_381_BBS_GURVY, math.BLS12_381_BLST, math.BLS12_381_CIRCL:
+		return nil
+	default:
+		return fmt.Errorf("bbs: curve %s is not BLS12-381: %w", math.CurveIDToString(id), math.ErrUnsupported)
+	}
+}
+
+func concat(a, b []byte) []byte {
+	return append(append(make([]byte, 0, len(a)+len(b)), a...), b...)
+}
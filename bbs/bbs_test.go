@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	math "github.com/IBM/mathlib"
+	"github.com/stretchr/testify/assert"
+)
+
+var bls12381 = []math.CurveID{
+	math.BLS12_381,
+	math.BLS12_381_GURVY,
+	math.BLS12_381_BBS,
+	math.BLS12_381_BBS_GURVY,
+	math.BLS12_381_BLST,
+	math.BLS12_381_CIRCL,
+}
+
+func TestCreateGenerators(t *testing.T) {
+	// draft-irtf-cfrg-bbs-signatures, fixtures of the ciphersuites: P1, then
+	// Q_1 and the first message generators
+	vectors := []struct {
+		suite      *Ciphersuite
+		p1         string
+		generators []string
+	}{
+		{
+			SHA256,
+			"a8ce256102840821a3e94ea9025e4662b205762f9776b3a766c872b948f1fd225e7c59698588e70d11406d161b4e28c9",
+			[]string{
+				"a9ec65b70a7fbe40c874c9eb041c2cb0a7af36ccec1bea48fa2ba4c2eb67ef7f9ecb17ed27d38d27cdeddff44c8137be",
+				"98cd5313283aaf5db1b3ba8611fe6070d19e605de4078c38df36019fbaad0bd28dd090fd24ed27f7f4d22d5ff5dea7d4",
+				"a31fbe20c5c135bcaa8d9fc4e4ac665cc6db0226f35e737507e803044093f37697a9d452490a970eea6f9ad6c3dcaa3a",
+			},
+		},
+		{
+			SHAKE256,
+			"8929dfbc7e6642c4ed9cba0856e493f8b9d7d5fcb0c31ef8fdcd34d50648a56c795e106e9eada6e0bda386b414150755",
+			[]string{
+				"a9d40131066399fd41af51d883f4473b0dcd7d028d3d34ef17f3241d204e28507d7ecae032afa1d5490849b7678ec1f8",
+			},
+		},
+	}
+
+	for _, id := range bls12381 {
+		c := math.Curves[id]
+		if c == nil {
+			continue
+		}
+
+		for _, v := range vectors {
+			p1, err := v.suite.P1(c)
+			assert.NoError(t, err, math.CurveIDToString(id))
+			assert.Equal(t, v.p1, hex.EncodeToString(p1.Compressed()), math.CurveIDToString(id))
+
+			generators, err := v.suite.CreateGenerators(c, len(v.generators), v.suite.APIID())
+			assert.NoError(t, err, math.CurveIDToString(id))
+			assert.Len(t, generators, len(v.generators))
+			for i, g := range generators {
+				assert.Equal(t, v.generators[i], hex.EncodeToString(g.Compressed()), math.CurveIDToString(id))
+			}
+		}
+	}
+
+	c := math.Curves[math.BLS12_381_BBS]
+
+	// a longer list starts with the shorter one
+	generators, err := SHA256.CreateGenerators(c, 5, SHA256.APIID())
+	assert.NoError(t, err)
+	assert.Len(t, generators, 5)
+	assert.Equal(t, vectors[0].generators[2], hex.EncodeToString(generators[2].Compressed()))
+
+	generators, err = SHA256.CreateGenerators(c, 0, SHA256.APIID())
+	assert.NoError(t, err)
+	assert.Empty(t, generators)
+
+	// the api_id separates the generators
+	generators, err = SHA256.CreateGenerators(c, 1, []byte("another api_id"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, vectors[0].generators[0], hex.EncodeToString(generators[0].Compressed()))
+
+	_, err = SHA256.CreateGenerators(math.Curves[math.BN254], 1, SHA256.APIID())
+	assert.True(t, errors.Is(err, math.ErrUnsupported))
+	_, err = SHAKE256.P1(math.Curves[math.FP256BN_AMCL])
+	assert.True(t, errors.Is(err, math.ErrUnsupported))
+}
+
+func TestHashToScalar(t *testing.T) {
+	msg, err := hex.DecodeString("9872ad089e452c7b6e283dfac2a80d58e8d0ff71cc4d5e310a1debdda4a45f02")
+	assert.NoError(t, err)
+
+	vectors := []struct {
+		suite  *Ciphersuite
+		scalar string
+	}{
+		// computed with an independent implementation
+		{SHA256, "0f90cbee27beb214e6545becb8404640d3612da5d6758dffeccd77ed7169807c"},
+		// draft-irtf-cfrg-bbs-signatures, fixtures of BLS12-381-SHAKE-256
+		{SHAKE256, "0500031f786fde5326aa9370dd7ffe9535ec7a52cf2b8f432cad5d9acfb73cd3"},
+	}
+
+	for _, id := range bls12381 {
+		c := math.Curves[id]
+		if c == nil {
+			continue
+		}
+
+		for _, v := range vectors {
+			dst := append(v.suite.APIID(), "H2S_"...)
+
+			z, err := v.suite.HashToScalar(c, msg, dst)
+			assert.NoError(t, err, math.CurveIDToString(id))
+			assert.Equal(t, v.scalar, hex.EncodeToString(z.Bytes()), math.CurveIDToString(id))
+
+			other, err := v.suite.HashToScalar(c, msg, []byte("another dst"))
+			assert.NoError(t, err, math.CurveIDToString(id))
+			assert.False(t, z.Equals(other), math.CurveIDToString(id))
+		}
+	}
+
+	_, err = SHA256.HashToScalar(math.Curves[math.BLS24_315_GURVY], msg, []byte("dst"))
+	assert.True(t, errors.Is(err, math.ErrUnsupported))
+}
+
+func TestExpandMessage(t *testing.T) {
+	// RFC 9380, Appendix K.1, expand_message_xmd with SHA-256
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	assert.Equal(t, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235", hex.EncodeToString(expandMessageXMD(nil, dst, 0x20)))
+	assert.Equal(t, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615", hex.EncodeToString(expandMessageXMD([]byte("abc"), dst, 0x20)))
+
+	// RFC 9380, Appendix K.6, expand_message_xof with SHAKE256
+	dst = []byte("QUUX-V01-CS02-with-expander-SHAKE256")
+	assert.Equal(t, "2ffc05c48ed32b95d72e807f6eab9f7530dd1c2f013914c8fed38c5ccc15ad76", hex.EncodeToString(expandMessageXOF(nil, dst, 0x20)))
+	assert.Equal(t, "b39e493867e2767216792abce1f2676c197c0692aed061560ead251821808e07", hex.EncodeToString(expandMessageXOF([]byte("abc"), dst, 0x20)))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// expandMessageXMD is expand_message_xmd of RFC 9380, Section 5.3.1, with
+// SHA-256. n must not exceed 255 * 32.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	if len(dst) > 255 {
+		h := sha256.Sum256(concat([]byte("H2C-OVERSIZE-DST-"), dst))
+		dst = h[:]
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	out := append(make([]byte, 0, n+sha256.Size), bi...)
+	for i := 2; len(out) < n; i++ {
+		x := make([]byte, sha256.Size)
+		for j := range x {
+			x[j] = b0[j] ^ bi[j]
+		}
+
+		h.Reset()
+		h.Write(x)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:n]
+}
+
+// expandMessageXOF is expand_message_xof of RFC 9380, Section 5.3.2, with
+// SHAKE-256 at the 128-bit security level.
+func expandMessageXOF(msg, dst []byte, n int) []byte {
+	if len(dst) > 255 {
+		d := make([]byte, 32)
+		h := sha3.NewShake256()
+		h.Write(concat([]byte("H2C-OVERSIZE-DST-"), dst))
+		h.Read(d)
+		dst = d
+	}
+
+	h := sha3.NewShake256()
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n)})
+	h.Write(dst)
+	h.Write([]byte{byte(len(dst))})
+
+	out := make([]byte, n)
+	h.Read(out)
+	return out
+}
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bls implements BLS signatures with signatures in G1 and public
+// keys in G2: the signature on msg under the secret key sk is sk * H(msg),
+// checked against the public key sk * g2 with a pairing. Signatures of
+// several signers on the same message aggregate into one, which is checked
+// against the sum of their public keys at the cost of a single pairing check.
+package bls
+
+import (
+	"fmt"
+
+	math "github.com/IBM/mathlib"
+)
+
+// Sign returns the signature sk * H(msg) of msg, where H is the hash to G1
+// of the curve of sk with the given domain separation tag.
+func Sign(sk *math.Zr, msg, domain []byte) *math.G1 {
+	c := math.Curves[sk.CurveID()]
+
+	return c.HashToG1WithDomain(msg, domain).Mul(sk)
+}
+
+// AggregateSignatures returns the sum of sigs, a signature that
+// FastAggregateVerify checks if all of sigs are on the same message. It
+// fails if sigs is empty or its elements are not all on the same curve.
+func AggregateSignatures(sigs []*math.G1) (*math.G1, error) {
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("bls: no signatures to aggregate")
+	}
+
+	id := sigs[0].CurveID()
+	agg := math.Curves[id].InfinityG1()
+	for i, sig := range sigs {
+		if sig.CurveID() != id {
+			return nil, fmt.Errorf("bls: signature %d is on %s, not %s: %w", i, math.CurveIDToString(sig.CurveID()), math.CurveIDToString(id), math.ErrWrongCurve)
+		}
+		agg.Add(sig)
+	}
+
+	return agg, nil
+}
+
+// FastAggregateVerify reports whether aggSig aggregates, with
+// AggregateSignatures, the signatures on msg under all of pks. It checks
+// e(sum of pks, H(msg)) = e(g2, aggSig), summing the keys with AggregateG2.
+//
+// It returns false if pks is empty, if any of them is the point at infinity,
+// which signs every message with the zero signature and whose inclusion
+// could otherwise go unnoticed, and if the elements are not all on the same
+// pairing-friendly curve. As with any aggregation of public keys, callers
+// must also have checked a proof of possession of the secret key of each
+// of pks, without which a rogue key could cancel out the others.
+func FastAggregateVerify(pks []*math.G2, msg, domain []byte, aggSig *math.G1) bool {
+	if len(pks) == 0 || aggSig == nil {
+		return false
+	}
+
+	id := aggSig.CurveID()
+	c := math.Curves[id]
+	if !c.SupportsPairing() {
+		return false
+	}
+
+	inf := c.InfinityG2()
+	for _, pk := range pks {
+		if pk == nil || pk.CurveID() != id || pk.Equals(inf) {
+			return false
+		}
+	}
+
+	h := c.HashToG1WithDomain(msg, domain)
+
+	return c.PairingCheckNeg(c.AggregateG2(pks), h, c.GenG2, aggSig)
+}
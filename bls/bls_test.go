@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bls
+
+import (
+	"errors"
+	"testing"
+
+	math "github.com/IBM/mathlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastAggregateVerify(t *testing.T) {
+	domain := []byte("MATHLIB-BLS-TEST")
+	msg := []byte("msg")
+
+	for _, c := range math.Curves {
+		if c == nil || !c.SupportsPairing() {
+			continue
+		}
+
+		t.Run(math.CurveIDToString(c.GenG1.CurveID()), func(t *testing.T) {
+			rng, err := c.Rand()
+			assert.NoError(t, err)
+
+			const k = 5
+			pks := make([]*math.G2, k)
+			sigs := make([]*math.G1, k)
+			for i := range pks {
+				sk := c.NewRandomZr(rng)
+				pks[i] = c.GenG2.Mul(sk)
+				sigs[i] = Sign(sk, msg, domain)
+			}
+
+			agg, err := AggregateSignatures(sigs)
+			assert.NoError(t, err)
+			assert.True(t, FastAggregateVerify(pks, msg, domain, agg))
+
+			// a single signer is a plain BLS signature
+			assert.True(t, FastAggregateVerify(pks[:1], msg, domain, sigs[0]))
+
+			// wrong messages, domains, signers and signatures are rejected
+			assert.False(t, FastAggregateVerify(pks, []byte("other msg"), domain, agg))
+			assert.False(t, FastAggregateVerify(pks, msg, []byte("other domain"), agg))
+			assert.False(t, FastAggregateVerify(pks[1:], msg, domain, agg))
+			assert.False(t, FastAggregateVerify(pks, msg, domain, sigs[0]))
+			assert.False(t, FastAggregateVerify(pks, msg, domain, c.InfinityG1()))
+			assert.False(t, FastAggregateVerify(nil, msg, domain, agg))
+			assert.False(t, FastAggregateVerify(pks, msg, domain, nil))
+
+			// the point at infinity signs anything with the zero signature,
+			// keys equal to it are rejected
+			withInf := append([]*math.G2{c.InfinityG2()}, pks...)
+			assert.False(t, FastAggregateVerify(withInf, msg, domain, agg))
+			assert.False(t, FastAggregateVerify([]*math.G2{c.InfinityG2()}, msg, domain, c.InfinityG1()))
+		})
+	}
+
+	_, err := AggregateSignatures(nil)
+	assert.Error(t, err)
+
+	_, err = AggregateSignatures([]*math.G1{math.Curves[math.BLS12_381].GenG1, math.Curves[math.BN254].GenG1})
+	assert.True(t, errors.Is(err, math.ErrWrongCurve))
+
+	// keys of another curve are rejected
+	c := math.Curves[math.BLS12_381]
+	sk := c.NewZrFromInt(42)
+	other := math.Curves[math.BN254]
+	assert.False(t, FastAggregateVerify([]*math.G2{other.GenG2.Mul(other.NewZrFromInt(42))}, msg, domain, Sign(sk, msg, domain)))
+}
@@ -0,0 +1,15 @@
+//go:build mathlib_blst && cgo
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import "github.com/IBM/mathlib/driver/blst"
+
+func newBLSTCurve() *Curve {
+	return newCurve(BLS12_381_BLST, blst.NewBls12_381())
+}
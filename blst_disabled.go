@@ -0,0 +1,15 @@
+//go:build !mathlib_blst || !cgo
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+// newBLSTCurve leaves BLS12_381_BLST out of Curves in builds without the
+// blst driver, rather than backing it with another one.
+func newBLSTCurve() *Curve {
+	return nil
+}
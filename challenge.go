@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	tagBytes byte = iota
+	tagZr
+	tagG1
+	tagG2
+	tagGt
+)
+
+// ChallengeChain derives a sequence of challenges for interactive protocols
+// made non-interactive. Every challenge depends on all the data absorbed so
+// far and on all the previous challenges.
+type ChallengeChain struct {
+	curve *Curve
+	h     hash.Hash
+}
+
+// NewChallengeChain returns a ChallengeChain whose challenges live in the
+// scalar field of c; label separates chains used by different protocols.
+func (c *Curve) NewChallengeChain(label []byte) *ChallengeChain {
+	cc := &ChallengeChain{curve: c, h: sha256.New()}
+	cc.absorb(tagBytes, label)
+
+	return cc
+}
+
+// AbsorbBytes adds arbitrary data to the chain.
+func (cc *ChallengeChain) AbsorbBytes(data []byte) {
+	cc.absorb(tagBytes, data)
+}
+
+func (cc *ChallengeChain) AbsorbZr(z ...*Zr) {
+	for _, e := range z {
+		cc.absorb(tagZr, e.Bytes())
+	}
+}
+
+func (cc *ChallengeChain) AbsorbG1(g ...*G1) {
+	for _, e := range g {
+		cc.absorb(tagG1, e.Bytes())
+	}
+}
+
+func (cc *ChallengeChain) AbsorbG2(g ...*G2) {
+	for _, e := range g {
+		cc.absorb(tagG2, e.Bytes())
+	}
+}
+
+func (cc *ChallengeChain) AbsorbGt(g ...*Gt) {
+	for _, e := range g {
+		cc.absorb(tagGt, e.Bytes())
+	}
+}
+
+// Challenge returns the next challenge. The state is replaced by the digest
+// of everything absorbed so far, so that the following challenges are
+// chained to this one.
+func (cc *ChallengeChain) Challenge() *Zr {
+	digest := cc.h.Sum(nil)
+
+	cc.h.Reset()
+	cc.h.Write(digest)
+
+	return cc.curve.HashToZr(digest)
+}
+
+// absorb writes a type tag and a length prefix ahead of the data so that
+// different sequences of inputs never hash the same.
+func (cc *ChallengeChain) absorb(tag byte, data []byte) {
+	var prefix [9]byte
+	prefix[0] = tag
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(data)))
+
+	cc.h.Write(prefix[:])
+	cc.h.Write(data)
+}
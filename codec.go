@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Encoder concatenates the encodings of points and scalars of a curve, each
+// with a fixed width: CompressedG1ByteSize for G1, CompressedG2ByteSize for
+// G2, GtByteSize for Gt and ScalarByteSize for Zr. The point at infinity is
+// padded with zeros on curves that encode it in fewer bytes. A Decoder bound
+// to the same curve reads the elements back in the same order.
+type Encoder struct {
+	curve *Curve
+	buf   []byte
+	n     int
+	err   error
+}
+
+// NewEncoder returns an empty Encoder for elements of c.
+func (c *Curve) NewEncoder() *Encoder {
+	return &Encoder{curve: c}
+}
+
+// PutG1 appends the compressed encoding of p.
+func (e *Encoder) PutG1(p *G1) {
+	if e.check("G1", p.CurveID()) {
+		e.put(p.Compressed(), e.curve.CompressedG1ByteSize)
+	}
+}
+
+// PutG2 appends the compressed encoding of p.
+func (e *Encoder) PutG2(p *G2) {
+	if e.check("G2", p.CurveID()) {
+		e.put(p.Compressed(), e.curve.CompressedG2ByteSize)
+	}
+}
+
+// PutGt appends the encoding of g, which Gt.Bytes returns.
+func (e *Encoder) PutGt(g *Gt) {
+	if e.check("Gt", g.CurveID()) {
+		e.put(g.Bytes(), e.curve.GtByteSize)
+	}
+}
+
+// PutZr appends the encoding of z, which Zr.Bytes returns.
+func (e *Encoder) PutZr(z *Zr) {
+	if e.check("Zr", z.CurveID()) {
+		e.put(z.Bytes(), e.curve.ScalarByteSize)
+	}
+}
+
+// Bytes returns the encodings appended so far, or the error of the first
+// element that could not be appended, e.g. one of another curve.
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return e.buf, nil
+}
+
+func (e *Encoder) check(elem string, id CurveID) bool {
+	if e.err != nil {
+		return false
+	}
+
+	if id != e.curve.curveID {
+		e.err = fmt.Errorf("mathlib: %s encode on %s: %w: element %d is on %s", elem, curveName(e.curve.curveID), ErrWrongCurve, e.n, curveName(id))
+		return false
+	}
+
+	return true
+}
+
+func (e *Encoder) put(b []byte, size int) {
+	if len(b) > size {
+		e.err = lengthError("encode", e.curve.curveID, len(b), size)
+		return
+	}
+
+	e.buf = append(e.buf, b...)
+	e.buf = append(e.buf, make([]byte, size-len(b))...)
+	e.n++
+}
+
+// Decoder reads back, in order, the elements appended by an Encoder of the
+// same curve; each is checked as by the matching NewXFromCompressed or
+// NewXFromBytes.
+type Decoder struct {
+	curve *Curve
+	buf   []byte
+}
+
+// NewDecoder returns a Decoder reading the elements of c from b.
+func (c *Curve) NewDecoder(b []byte) *Decoder {
+	return &Decoder{curve: c, buf: b}
+}
+
+// G1 reads a point of G1.
+func (d *Decoder) G1() (*G1, error) {
+	b, err := d.next("G1 decode", d.curve.CompressedG1ByteSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.curve.compressedInfinityG1ByteSize != d.curve.CompressedG1ByteSize {
+		b = unpadInfinity(b, d.curve.InfinityG1().Compressed())
+	}
+
+	return d.curve.NewG1FromCompressed(b)
+}
+
+// G2 reads a point of G2.
+func (d *Decoder) G2() (*G2, error) {
+	if !d.curve.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	b, err := d.next("G2 decode", d.curve.CompressedG2ByteSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.curve.compressedInfinityG2ByteSize != d.curve.CompressedG2ByteSize {
+		b = unpadInfinity(b, d.curve.InfinityG2().Compressed())
+	}
+
+	return d.curve.NewG2FromCompressed(b)
+}
+
+// Gt reads an element of Gt.
+func (d *Decoder) Gt() (*Gt, error) {
+	if !d.curve.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	b, err := d.next("Gt decode", d.curve.GtByteSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.curve.NewGtFromBytes(b)
+}
+
+// Zr reads a scalar, which must be smaller than the group order.
+func (d *Decoder) Zr() (*Zr, error) {
+	b, err := d.next("Zr decode", d.curve.ScalarByteSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.curve.NewZrFromBytesStrict(b)
+}
+
+// Finish fails with ErrInvalidLength if bytes are left after the elements
+// read so far.
+func (d *Decoder) Finish() error {
+	if len(d.buf) != 0 {
+		return fmt.Errorf("mathlib: decode on %s: %w: %d trailing bytes", curveName(d.curve.curveID), ErrInvalidLength, len(d.buf))
+	}
+
+	return nil
+}
+
+func (d *Decoder) next(op string, size int) ([]byte, error) {
+	if len(d.buf) < size {
+		return nil, lengthError(op, d.curve.curveID, len(d.buf), size)
+	}
+
+	b := d.buf[:size]
+	d.buf = d.buf[size:]
+	return b, nil
+}
+
+// unpadInfinity returns inf if b is inf padded with zeros, and b otherwise.
+func unpadInfinity(b, inf []byte) []byte {
+	if !bytes.HasPrefix(b, inf) {
+		return b
+	}
+
+	for _, v := range b[len(inf):] {
+		if v != 0 {
+			return b
+		}
+	}
+
+	return inf
+}
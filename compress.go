@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/pkg/errors"
+)
+
+// ElemType identifies the group of a compressed element
+type ElemType int
+
+const (
+	ElemG1 ElemType = iota
+	ElemG2
+	ElemGt
+)
+
+// Compress returns the compressed encoding of a *G1, *G2 or *Gt. G2 elements
+// can only be compressed on curves that support pairings, and Gt elements on
+// curves whose driver implements driver.GtCompressor; the others return
+// ErrUnsupported. Gt elements out of the cyclotomic subgroup, e.g. Miller
+// loop outputs, fail to compress.
+func (c *Curve) Compress(elem interface{}) ([]byte, error) {
+	switch e := elem.(type) {
+	case *G1:
+		return e.Compressed(), nil
+	case *G2:
+		if !c.SupportsPairing() {
+			return nil, ErrUnsupported
+		}
+		return e.Compressed(), nil
+	case *Gt:
+		gc, ok := c.c.(driver.GtCompressor)
+		if !ok {
+			return nil, ErrUnsupported
+		}
+		b, err := gc.CompressedGt(e.gt)
+		if err != nil {
+			return nil, fmt.Errorf("mathlib: Gt compress on %s: %w", curveName(c.curveID), err)
+		}
+		return b, nil
+	default:
+		return nil, errors.Errorf("cannot compress elements of type %T", elem)
+	}
+}
+
+// Decompress is the inverse of Compress: it returns a *G1, *G2 or *Gt
+// depending on tag. Like NewGtFromBytes, it fails with ErrNotInSubgroup on
+// elements out of Gt.
+func (c *Curve) Decompress(tag ElemType, b []byte) (interface{}, error) {
+	switch tag {
+	case ElemG1:
+		p, err := c.NewG1FromCompressed(b)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case ElemG2:
+		p, err := c.NewG2FromCompressed(b)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case ElemGt:
+		gc, ok := c.c.(driver.GtCompressor)
+		if !ok {
+			return nil, ErrUnsupported
+		}
+
+		gt, err := gc.NewGtFromCompressed(b)
+		if err != nil {
+			return nil, decodeError("Gt decompress", c.curveID, err)
+		}
+		if !c.inGt(gt) {
+			return nil, fmt.Errorf("mathlib: Gt decompress on %s: %w", curveName(c.curveID), ErrNotInSubgroup)
+		}
+		return &Gt{gt: gt, curve: c}, nil
+	default:
+		return nil, errors.Errorf("unknown element type %d", tag)
+	}
+}
@@ -9,9 +9,8 @@ package amcl
 import (
 	"crypto/hmac"
 	"crypto/sha256"
+	"fmt"
 	"math/big"
-	"regexp"
-	"strings"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/common"
@@ -25,6 +24,11 @@ type fp256bnGt struct {
 }
 
 func (a *fp256bnGt) Exp(x driver.Zr) driver.Gt {
+	// Pow returns its input, not one, for a zero exponent
+	if common.Normalize(&x.(*common.BaseZr).Int, &modulusBig).Sign() == 0 {
+		return &fp256bnGt{*FP256BN.NewFP12int(1)}
+	}
+
 	return &fp256bnGt{*a.FP12.Pow(bigToMiraclBIGCore(&x.(*common.BaseZr).Int))}
 }
 
@@ -40,6 +44,11 @@ func (a *fp256bnGt) Inverse() {
 	a.FP12.Inverse()
 }
 
+// Conjugate falls back to Inverse, amcl does not export the conjugation.
+func (a *fp256bnGt) Conjugate() {
+	a.Inverse()
+}
+
 func (a *fp256bnGt) Mul(b driver.Gt) {
 	a.FP12.Mul(&b.(*fp256bnGt).FP12)
 }
@@ -57,18 +66,42 @@ func (b *fp256bnGt) Bytes() []byte {
 /*********************************************************************/
 
 func NewFp256bn() *Fp256bn {
-	return &Fp256bn{common.CurveBase{Modulus: modulusBig}}
+	c := &Fp256bn{CurveBase: common.CurveBase{Modulus: modulusBig}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*fp256bnGt).FP12
+	return c
 }
 
 type Fp256bn struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it: FP12 holds
+	// pointers, so these go through NewFP12copy
+	genGt FP256BN.FP12
+}
+
+func (c *Fp256bn) Info() *driver.CurveInfo {
+	return common.FP256BNInfo()
 }
 
+// Pairing returns the unity if a or b is the point at infinity, which Ate
+// only does for b.
 func (*Fp256bn) Pairing(a driver.G2, b driver.G1) driver.Gt {
+	if a.(*fp256bnG2).ECP2.Is_infinity() || b.(*fp256bnG1).ECP.Is_infinity() {
+		return &fp256bnGt{*FP256BN.NewFP12int(1)}
+	}
+
 	return &fp256bnGt{*FP256BN.Ate(&a.(*fp256bnG2).ECP2, &b.(*fp256bnG1).ECP)}
 }
 
-func (*Fp256bn) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+// Pairing2 leaves out the pairs with a point at infinity, see Pairing.
+func (c *Fp256bn) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	switch {
+	case p2a.(*fp256bnG2).ECP2.Is_infinity() || p1a.(*fp256bnG1).ECP.Is_infinity():
+		return c.Pairing(p2b, p1b)
+	case p2b.(*fp256bnG2).ECP2.Is_infinity() || p1b.(*fp256bnG1).ECP.Is_infinity():
+		return c.Pairing(p2a, p1a)
+	}
+
 	return &fp256bnGt{*FP256BN.Ate2(&p2a.(*fp256bnG2).ECP2, &p1a.(*fp256bnG1).ECP, &p2b.(*fp256bnG2).ECP2, &p1b.(*fp256bnG1).ECP)}
 }
 
@@ -76,6 +109,12 @@ func (*Fp256bn) FExp(e driver.Gt) driver.Gt {
 	return &fp256bnGt{*FP256BN.Fexp(&e.(*fp256bnGt).FP12)}
 }
 
+// fp256bnGenG1 and fp256bnGenG2 are compared against by IsGenerator
+var (
+	fp256bnGenG1 = (&Fp256bn{}).GenG1()
+	fp256bnGenG2 = (&Fp256bn{}).GenG2()
+)
+
 func (*Fp256bn) GenG1() driver.G1 {
 	return &fp256bnG1{*FP256BN.NewECPbigs(FP256BN.NewBIGints(FP256BN.CURVE_Gx), FP256BN.NewBIGints(FP256BN.CURVE_Gy))}
 }
@@ -87,7 +126,7 @@ func (*Fp256bn) GenG2() driver.G2 {
 }
 
 func (p *Fp256bn) GenGt() driver.Gt {
-	return &fp256bnGt{*FP256BN.Fexp(FP256BN.Ate(&p.GenG2().(*fp256bnG2).ECP2, &p.GenG1().(*fp256bnG1).ECP))}
+	return &fp256bnGt{*FP256BN.NewFP12copy(&p.genGt)}
 }
 
 func (p *Fp256bn) CoordinateByteSize() int {
@@ -110,6 +149,10 @@ func (p *Fp256bn) CompressedG2ByteSize() int {
 	return 4 * int(FP256BN.MODBYTES)
 }
 
+func (p *Fp256bn) GtByteSize() int {
+	return 12 * int(FP256BN.MODBYTES)
+}
+
 func (p *Fp256bn) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -122,38 +165,85 @@ func (p *Fp256bn) NewG2() driver.G2 {
 	return &fp256bnG2{*FP256BN.NewECP2()}
 }
 
+func (p *Fp256bn) InfinityG1() driver.G1 {
+	return &fp256bnG1{*FP256BN.NewECP()}
+}
+
+func (p *Fp256bn) InfinityG2() driver.G2 {
+	return &fp256bnG2{*FP256BN.NewECP2()}
+}
+
+func (p *Fp256bn) SumG2(points []driver.G2) driver.G2 {
+	acc := p.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
+	}
+
+	return acc
+}
+
 func bigToMiraclBIGCore(bi *big.Int) *FP256BN.BIG {
-	biCopy := bi
+	return FP256BN.FromBytes(common.BigToBytes(common.Normalize(bi, &modulusBig)))
+}
 
-	if bi.Sign() < 0 || bi.Cmp(&modulusBig) > 0 {
-		biCopy = new(big.Int).Set(bi)
-		biCopy = biCopy.Mod(biCopy, &modulusBig)
-		if biCopy.Sign() < 0 {
-			biCopy = biCopy.Add(biCopy, &modulusBig)
+// checkPrefix reports an error unless the first byte of b, the SEC1 tag of
+// the encodings of both AMCL versions, is one of prefixes. ECP_fromBytes and
+// ECP2_fromBytes pick the layout from that byte alone and read out of
+// bounds if it does not agree with the length of b.
+func checkPrefix(b []byte, prefixes ...byte) error {
+	for _, p := range prefixes {
+		if b[0] == p {
+			return nil
 		}
 	}
 
-	return FP256BN.FromBytes(common.BigToBytes(biCopy))
+	return fmt.Errorf("set bytes failed [invalid prefix 0x%02x for length %d]", b[0], len(b))
 }
 
-func (p *Fp256bn) NewG1FromBytes(b []byte) driver.G1 {
-	return &fp256bnG1{*FP256BN.ECP_fromBytes(b)}
+func (p *Fp256bn) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) != p.G1ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x04); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnG1{*FP256BN.ECP_fromBytes(b)}, nil
 }
 
-func (p *Fp256bn) NewG2FromBytes(b []byte) driver.G2 {
-	return &fp256bnG2{*FP256BN.ECP2_fromBytes(b)}
+func (p *Fp256bn) NewG2FromBytes(b []byte) (driver.G2, error) {
+	if len(b) != p.G2ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	return &fp256bnG2{*FP256BN.ECP2_fromBytes(b)}, nil
 }
 
-func (p *Fp256bn) NewG1FromCompressed(b []byte) driver.G1 {
-	return &fp256bnG1{*FP256BN.ECP_fromBytes(b)}
+func (p *Fp256bn) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	if len(b) != p.CompressedG1ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x02, 0x03); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnG1{*FP256BN.ECP_fromBytes(b)}, nil
 }
 
-func (p *Fp256bn) NewG2FromCompressed(b []byte) driver.G2 {
-	return &fp256bnG2{*FP256BN.ECP2_fromBytes(b)}
+func (p *Fp256bn) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	if len(b) != p.CompressedG2ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	return &fp256bnG2{*FP256BN.ECP2_fromBytes(b)}, nil
 }
 
-func (p *Fp256bn) NewGtFromBytes(b []byte) driver.Gt {
-	return &fp256bnGt{*FP256BN.FP12_fromBytes(b)}
+func (p *Fp256bn) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	if len(b) != 12*int(FP256BN.MODBYTES) {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	return &fp256bnGt{*FP256BN.FP12_fromBytes(b)}, nil
 }
 
 func (p *Fp256bn) HashToG1(data []byte) driver.G1 {
@@ -174,6 +264,14 @@ func (p *Fp256bn) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	panic("HashToG2WithDomain is not available for this curve")
 }
 
+func (p *Fp256bn) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	panic("HashToG1WithU is not available for this curve")
+}
+
+func (p *Fp256bn) MapToG1(u []driver.Zr) driver.G1 {
+	panic("MapToG1 is not available for this curve")
+}
+
 /*********************************************************************/
 
 type fp256bnG1 struct {
@@ -210,6 +308,16 @@ func (e *fp256bnG1) IsInfinity() bool {
 	return e.ECP.Is_infinity()
 }
 
+func (e *fp256bnG1) IsGenerator() bool {
+	return e.Equals(fp256bnGenG1)
+}
+
+// IsOnCurve rebuilds the point from its affine coordinates, which yields the
+// point at infinity unless they satisfy the curve equation.
+func (e *fp256bnG1) IsOnCurve() bool {
+	return e.ECP.Is_infinity() || !FP256BN.NewECPbigs(e.ECP.GetX(), e.ECP.GetY()).Is_infinity()
+}
+
 func (e *fp256bnG1) Bytes() []byte {
 	b := make([]byte, 2*int(FP256BN.MODBYTES)+1)
 	e.ECP.ToBytes(b, false)
@@ -226,12 +334,22 @@ func (e *fp256bnG1) Sub(a driver.G1) {
 	e.ECP.Sub(&a.(*fp256bnG1).ECP)
 }
 
-var g1StrRegexp *regexp.Regexp = regexp.MustCompile(`^\(([0-9a-f]+),([0-9a-f]+)\)$`)
+// g1String formats the uncompressed encoding 0x04 || x || y of a point the
+// way ECP.ToString does, without the leading zeros of the coordinates.
+func g1String(b []byte) string {
+	n := (len(b) - 1) / 2
+	x := new(big.Int).SetBytes(b[1 : 1+n])
+	y := new(big.Int).SetBytes(b[1+n:])
+
+	return "(" + x.Text(16) + "," + y.Text(16) + ")"
+}
 
 func (b *fp256bnG1) String() string {
-	rawstr := b.ECP.ToString()
-	m := g1StrRegexp.FindAllStringSubmatch(rawstr, -1)
-	return "(" + strings.TrimLeft(m[0][1], "0") + "," + strings.TrimLeft(m[0][2], "0") + ")"
+	if b.ECP.Is_infinity() {
+		return "infinity"
+	}
+
+	return g1String(b.Bytes())
 }
 
 func (e *fp256bnG1) Neg() {
@@ -249,6 +367,14 @@ func (e *fp256bnG2) Equals(a driver.G2) bool {
 	return e.ECP2.Equals(&a.(*fp256bnG2).ECP2)
 }
 
+func (e *fp256bnG2) IsGenerator() bool {
+	return e.Equals(fp256bnGenG2)
+}
+
+func (e *fp256bnG2) IsOnCurve() bool {
+	return e.ECP2.Is_infinity() || !FP256BN.NewECP2fp2s(e.ECP2.GetX(), e.ECP2.GetY()).Is_infinity()
+}
+
 func (e *fp256bnG2) Clone(a driver.G2) {
 	e.ECP2.Copy(&a.(*fp256bnG2).ECP2)
 }
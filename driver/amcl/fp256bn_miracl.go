@@ -7,8 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package amcl
 
 import (
+	"fmt"
 	"math/big"
-	"strings"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/common"
@@ -44,6 +44,11 @@ func (a *fp256bnMiraclGt) Inverse() {
 	a.FP12.Inverse()
 }
 
+// Conjugate falls back to Inverse, amcl does not export the conjugation.
+func (a *fp256bnMiraclGt) Conjugate() {
+	a.Inverse()
+}
+
 func (a *fp256bnMiraclGt) Mul(b driver.Gt) {
 	a.FP12.Mul(&b.(*fp256bnMiraclGt).FP12)
 }
@@ -61,18 +66,42 @@ func (b *fp256bnMiraclGt) Bytes() []byte {
 /*********************************************************************/
 
 func NewFp256Miraclbn() *Fp256Miraclbn {
-	return &Fp256Miraclbn{common.CurveBase{Modulus: modulusBig}}
+	c := &Fp256Miraclbn{CurveBase: common.CurveBase{Modulus: modulusBig}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*fp256bnMiraclGt).FP12
+	return c
 }
 
 type Fp256Miraclbn struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it: FP12 holds
+	// pointers, so these go through NewFP12copy
+	genGt FP256BN.FP12
 }
 
+func (c *Fp256Miraclbn) Info() *driver.CurveInfo {
+	return common.FP256BNInfo()
+}
+
+// Pairing returns the unity if a or b is the point at infinity, which Ate
+// only does for b.
 func (*Fp256Miraclbn) Pairing(a driver.G2, b driver.G1) driver.Gt {
+	if a.(*fp256bnMiraclG2).ECP2.Is_infinity() || b.(*fp256bnMiraclG1).ECP.Is_infinity() {
+		return &fp256bnMiraclGt{*FP256BN.NewFP12int(1)}
+	}
+
 	return &fp256bnMiraclGt{*FP256BN.Ate(a.(*fp256bnMiraclG2).ECP2, &b.(*fp256bnMiraclG1).ECP)}
 }
 
-func (*Fp256Miraclbn) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+// Pairing2 leaves out the pairs with a point at infinity, see Pairing.
+func (c *Fp256Miraclbn) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	switch {
+	case p2a.(*fp256bnMiraclG2).ECP2.Is_infinity() || p1a.(*fp256bnMiraclG1).ECP.Is_infinity():
+		return c.Pairing(p2b, p1b)
+	case p2b.(*fp256bnMiraclG2).ECP2.Is_infinity() || p1b.(*fp256bnMiraclG1).ECP.Is_infinity():
+		return c.Pairing(p2a, p1a)
+	}
+
 	return &fp256bnMiraclGt{*FP256BN.Ate2(p2a.(*fp256bnMiraclG2).ECP2, &p1a.(*fp256bnMiraclG1).ECP, p2b.(*fp256bnMiraclG2).ECP2, &p1b.(*fp256bnMiraclG1).ECP)}
 }
 
@@ -80,6 +109,13 @@ func (*Fp256Miraclbn) FExp(e driver.Gt) driver.Gt {
 	return &fp256bnMiraclGt{*FP256BN.Fexp(&e.(*fp256bnMiraclGt).FP12)}
 }
 
+// fp256bnMiraclGenG1 and fp256bnMiraclGenG2 are compared against by
+// IsGenerator
+var (
+	fp256bnMiraclGenG1 = (&Fp256Miraclbn{}).GenG1()
+	fp256bnMiraclGenG2 = (&Fp256Miraclbn{}).GenG2()
+)
+
 func (*Fp256Miraclbn) GenG1() driver.G1 {
 	return &fp256bnMiraclG1{*FP256BN.NewECPbigs(FP256BN.NewBIGints(FP256BN.CURVE_Gx), FP256BN.NewBIGints(FP256BN.CURVE_Gy))}
 }
@@ -91,7 +127,7 @@ func (*Fp256Miraclbn) GenG2() driver.G2 {
 }
 
 func (p *Fp256Miraclbn) GenGt() driver.Gt {
-	return &fp256bnMiraclGt{*FP256BN.Fexp(FP256BN.Ate(p.GenG2().(*fp256bnMiraclG2).ECP2, &p.GenG1().(*fp256bnMiraclG1).ECP))}
+	return &fp256bnMiraclGt{*FP256BN.NewFP12copy(&p.genGt)}
 }
 
 func (p *Fp256Miraclbn) CoordinateByteSize() int {
@@ -114,6 +150,10 @@ func (p *Fp256Miraclbn) CompressedG2ByteSize() int {
 	return 2*int(FP256BN.MODBYTES) + 1
 }
 
+func (p *Fp256Miraclbn) GtByteSize() int {
+	return 12 * int(FP256BN.MODBYTES)
+}
+
 func (p *Fp256Miraclbn) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -126,38 +166,77 @@ func (p *Fp256Miraclbn) NewG2() driver.G2 {
 	return &fp256bnMiraclG2{FP256BN.NewECP2()}
 }
 
-func bigToMiraclBIG(bi *big.Int) *FP256BN.BIG {
-	biCopy := bi
-
-	if bi.Sign() < 0 || bi.Cmp(&modulusBig) > 0 {
-		biCopy = new(big.Int).Set(bi)
-		biCopy = biCopy.Mod(biCopy, &modulusBig)
-		if biCopy.Sign() < 0 {
-			biCopy = biCopy.Add(biCopy, &modulusBig)
-		}
+func (p *Fp256Miraclbn) InfinityG1() driver.G1 {
+	return &fp256bnMiraclG1{*FP256BN.NewECP()}
+}
+
+func (p *Fp256Miraclbn) InfinityG2() driver.G2 {
+	return &fp256bnMiraclG2{FP256BN.NewECP2()}
+}
+
+func (p *Fp256Miraclbn) SumG2(points []driver.G2) driver.G2 {
+	acc := p.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
 	}
 
-	return FP256BN.FromBytes(common.BigToBytes(biCopy))
+	return acc
 }
 
-func (p *Fp256Miraclbn) NewG1FromBytes(b []byte) driver.G1 {
-	return &fp256bnMiraclG1{*FP256BN.ECP_fromBytes(b)}
+func bigToMiraclBIG(bi *big.Int) *FP256BN.BIG {
+	return FP256BN.FromBytes(common.BigToBytes(common.Normalize(bi, &modulusBig)))
 }
 
-func (p *Fp256Miraclbn) NewG2FromBytes(b []byte) driver.G2 {
-	return &fp256bnMiraclG2{FP256BN.ECP2_fromBytes(b)}
+func (p *Fp256Miraclbn) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) != p.G1ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x04); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnMiraclG1{*FP256BN.ECP_fromBytes(b)}, nil
 }
 
-func (p *Fp256Miraclbn) NewG1FromCompressed(b []byte) driver.G1 {
-	return &fp256bnMiraclG1{*FP256BN.ECP_fromBytes(b)}
+func (p *Fp256Miraclbn) NewG2FromBytes(b []byte) (driver.G2, error) {
+	if len(b) != p.G2ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x04); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnMiraclG2{FP256BN.ECP2_fromBytes(b)}, nil
 }
 
-func (p *Fp256Miraclbn) NewG2FromCompressed(b []byte) driver.G2 {
-	return &fp256bnMiraclG2{FP256BN.ECP2_fromBytes(b)}
+func (p *Fp256Miraclbn) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	if len(b) != p.CompressedG1ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x02, 0x03); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnMiraclG1{*FP256BN.ECP_fromBytes(b)}, nil
 }
 
-func (p *Fp256Miraclbn) NewGtFromBytes(b []byte) driver.Gt {
-	return &fp256bnMiraclGt{*FP256BN.FP12_fromBytes(b)}
+func (p *Fp256Miraclbn) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	if len(b) != p.CompressedG2ByteSize() {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+	if err := checkPrefix(b, 0x02, 0x03); err != nil {
+		return nil, err
+	}
+
+	return &fp256bnMiraclG2{FP256BN.ECP2_fromBytes(b)}, nil
+}
+
+func (p *Fp256Miraclbn) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	if len(b) != 12*int(FP256BN.MODBYTES) {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	return &fp256bnMiraclGt{*FP256BN.FP12_fromBytes(b)}, nil
 }
 
 func (p *Fp256Miraclbn) HashToG1(data []byte) driver.G1 {
@@ -176,6 +255,14 @@ func (p *Fp256Miraclbn) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	panic("HashToG2WithDomain is not available for this curve")
 }
 
+func (p *Fp256Miraclbn) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	panic("HashToG1WithU is not available for this curve")
+}
+
+func (p *Fp256Miraclbn) MapToG1(u []driver.Zr) driver.G1 {
+	panic("MapToG1 is not available for this curve")
+}
+
 /*********************************************************************/
 
 type fp256bnMiraclG1 struct {
@@ -212,6 +299,16 @@ func (e *fp256bnMiraclG1) IsInfinity() bool {
 	return e.ECP.Is_infinity()
 }
 
+func (e *fp256bnMiraclG1) IsGenerator() bool {
+	return e.Equals(fp256bnMiraclGenG1)
+}
+
+// IsOnCurve rebuilds the point from its affine coordinates, which yields the
+// point at infinity unless they satisfy the curve equation.
+func (e *fp256bnMiraclG1) IsOnCurve() bool {
+	return e.ECP.Is_infinity() || !FP256BN.NewECPbigs(e.ECP.GetX(), e.ECP.GetY()).Is_infinity()
+}
+
 func (e *fp256bnMiraclG1) Bytes() []byte {
 	b := make([]byte, 2*int(FP256BN.MODBYTES)+1)
 	e.ECP.ToBytes(b, false)
@@ -229,9 +326,11 @@ func (e *fp256bnMiraclG1) Sub(a driver.G1) {
 }
 
 func (b *fp256bnMiraclG1) String() string {
-	rawstr := b.ECP.ToString()
-	m := g1StrRegexp.FindAllStringSubmatch(rawstr, -1)
-	return "(" + strings.TrimLeft(m[0][1], "0") + "," + strings.TrimLeft(m[0][2], "0") + ")"
+	if b.ECP.Is_infinity() {
+		return "infinity"
+	}
+
+	return g1String(b.Bytes())
 }
 
 func (e *fp256bnMiraclG1) Neg() {
@@ -248,6 +347,14 @@ func (e *fp256bnMiraclG2) Equals(a driver.G2) bool {
 	return e.ECP2.Equals(a.(*fp256bnMiraclG2).ECP2)
 }
 
+func (e *fp256bnMiraclG2) IsGenerator() bool {
+	return e.Equals(fp256bnMiraclGenG2)
+}
+
+func (e *fp256bnMiraclG2) IsOnCurve() bool {
+	return e.ECP2.Is_infinity() || !FP256BN.NewECP2fp2s(e.ECP2.GetX(), e.ECP2.GetY()).Is_infinity()
+}
+
 func (e *fp256bnMiraclG2) Clone(a driver.G2) {
 	e.ECP2.Copy(a.(*fp256bnMiraclG2).ECP2)
 }
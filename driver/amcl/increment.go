@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package amcl
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+)
+
+const fpByteSize = 32
+
+var fpModulus = common.FP256BNInfo().BaseFieldModulus
+
+// hashAndIncrement maps data to a point of FP256BN by try-and-increment:
+// for ctr = 0, 1, ..., x = SHA-256(data || ctr) mod p until x^3 + 3 is a
+// square, then y is its even square root. G1 has cofactor 1, so the point
+// needs no clearing. It returns the uncompressed encoding of the point,
+// which both AMCL versions read in the same way.
+//
+// The number of attempts depends on data, so the map is not constant time:
+// only use it on public inputs.
+func hashAndIncrement(data []byte) []byte {
+	var x, y big.Int
+	three := big.NewInt(3)
+
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha256.New()
+		h.Write(data)
+		h.Write([]byte{byte(ctr)})
+		x.SetBytes(h.Sum(nil))
+		x.Mod(&x, fpModulus)
+
+		y.Exp(&x, three, fpModulus)
+		y.Add(&y, three)
+		if y.ModSqrt(&y, fpModulus) == nil {
+			continue
+		}
+		if y.Bit(0) == 1 {
+			y.Sub(fpModulus, &y)
+		}
+
+		b := make([]byte, 1+2*fpByteSize)
+		b[0] = 0x04
+		x.FillBytes(b[1 : 1+fpByteSize])
+		y.FillBytes(b[1+fpByteSize:])
+		return b
+	}
+
+	panic("hash and increment failed")
+}
+
+// MapToG1Increment maps data to G1 with the try-and-increment method of
+// hashAndIncrement; Fp256Miraclbn returns the same points.
+func (p *Fp256bn) MapToG1Increment(data []byte) driver.G1 {
+	g, err := p.NewG1FromBytes(hashAndIncrement(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
+
+// MapToG1Increment maps data to G1 with the try-and-increment method of
+// hashAndIncrement; Fp256bn returns the same points.
+func (p *Fp256Miraclbn) MapToG1Increment(data []byte) driver.G1 {
+	g, err := p.NewG1FromBytes(hashAndIncrement(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
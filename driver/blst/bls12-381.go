@@ -0,0 +1,495 @@
+//go:build mathlib_blst && cgo
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blst
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"unsafe"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/IBM/mathlib/driver/kilic"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+const fpByteSize = blst.BLST_FP_BYTES
+
+var fpModulus big.Int // p stored as big.Int
+var frModulus big.Int // r stored as big.Int
+func init() {
+	fpModulus.SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	frModulus.SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+}
+
+// scalarToLE returns the little-endian encoding expected by blst's
+// point multiplication
+func scalarToLE(z driver.Zr) []byte {
+	b := common.BigToBytes(new(big.Int).Mod(&z.(*common.BaseZr).Int, &frModulus))
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+/*********************************************************************/
+
+type bls12381G1 struct {
+	blst.P1
+}
+
+func (g *bls12381G1) Clone(a driver.G1) {
+	g.P1 = a.(*bls12381G1).P1
+}
+
+func (g *bls12381G1) Copy() driver.G1 {
+	return &bls12381G1{g.P1}
+}
+
+func (g *bls12381G1) Add(a driver.G1) {
+	g.P1.AddAssign(&a.(*bls12381G1).P1)
+}
+
+func (g *bls12381G1) Mul(a driver.Zr) driver.G1 {
+	return &bls12381G1{*g.P1.Mult(scalarToLE(a))}
+}
+
+func (g *bls12381G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *bls12381G1) Equals(a driver.G1) bool {
+	return g.P1.Equals(&a.(*bls12381G1).P1)
+}
+
+func (g *bls12381G1) Bytes() []byte {
+	return g.P1.Serialize()
+}
+
+func (g *bls12381G1) Compressed() []byte {
+	return g.P1.Compress()
+}
+
+func (g *bls12381G1) Sub(a driver.G1) {
+	g.P1.SubAssign(&a.(*bls12381G1).P1)
+}
+
+func (g *bls12381G1) IsInfinity() bool {
+	return g.P1.Equals(&blst.P1{})
+}
+
+func (g *bls12381G1) IsGenerator() bool {
+	return g.P1.Equals(blst.P1Generator())
+}
+
+// IsOnCurve deserializes the affine coordinates, which blst checks against
+// the curve equation but not against the subgroup.
+func (g *bls12381G1) IsOnCurve() bool {
+	return new(blst.P1Affine).Deserialize(g.P1.Serialize()) != nil
+}
+
+func (g *bls12381G1) String() string {
+	gb := g.Bytes()
+	x := new(big.Int).SetBytes(gb[:len(gb)/2])
+	y := new(big.Int).SetBytes(gb[len(gb)/2:])
+
+	return "(" + x.String() + "," + y.String() + ")"
+}
+
+func (g *bls12381G1) Neg() {
+	g.P1 = *(&blst.P1{}).Sub(&g.P1)
+}
+
+/*********************************************************************/
+
+type bls12381G2 struct {
+	blst.P2
+}
+
+func (g *bls12381G2) Clone(a driver.G2) {
+	g.P2 = a.(*bls12381G2).P2
+}
+
+func (g *bls12381G2) Copy() driver.G2 {
+	return &bls12381G2{g.P2}
+}
+
+func (g *bls12381G2) Mul(a driver.Zr) driver.G2 {
+	return &bls12381G2{*g.P2.Mult(scalarToLE(a))}
+}
+
+func (g *bls12381G2) Add(a driver.G2) {
+	g.P2.AddAssign(&a.(*bls12381G2).P2)
+}
+
+func (g *bls12381G2) Sub(a driver.G2) {
+	g.P2.SubAssign(&a.(*bls12381G2).P2)
+}
+
+func (g *bls12381G2) Affine() {
+	g.P2.FromAffine(g.P2.ToAffine())
+}
+
+func (g *bls12381G2) Bytes() []byte {
+	return g.P2.Serialize()
+}
+
+func (g *bls12381G2) Compressed() []byte {
+	return g.P2.Compress()
+}
+
+func (g *bls12381G2) String() string {
+	return fmt.Sprintf("%x", g.Bytes())
+}
+
+func (g *bls12381G2) Equals(a driver.G2) bool {
+	return g.P2.Equals(&a.(*bls12381G2).P2)
+}
+
+func (g *bls12381G2) IsGenerator() bool {
+	return g.P2.Equals(blst.P2Generator())
+}
+
+func (g *bls12381G2) IsOnCurve() bool {
+	return new(blst.P2Affine).Deserialize(g.P2.Serialize()) != nil
+}
+
+/*********************************************************************/
+
+type bls12381Gt struct {
+	blst.Fp12
+}
+
+// coefficients exposes the 12 base field coefficients of an Fp12 in blst's
+// memory layout, i.e. fp6[j].fp2[i].fp[k] at index 6j+2i+k.
+func coefficients(e *blst.Fp12) *[12]blst.Fp {
+	return (*[12]blst.Fp)(unsafe.Pointer(e))
+}
+
+func (g *bls12381Gt) Exp(x driver.Zr) driver.Gt {
+	e := new(big.Int).Mod(&x.(*common.BaseZr).Int, &frModulus)
+
+	res := blst.Fp12One()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		sq := res
+		res.MulAssign(&sq)
+		if e.Bit(i) == 1 {
+			res.MulAssign(&g.Fp12)
+		}
+	}
+
+	return &bls12381Gt{res}
+}
+
+func (g *bls12381Gt) Equals(a driver.Gt) bool {
+	return g.Fp12.Equals(&a.(*bls12381Gt).Fp12)
+}
+
+// Inverse conjugates g, which inverts it as long as g lies in the
+// cyclotomic subgroup; this holds for every output of Pairing, which
+// already applies the final exponentiation.
+func (g *bls12381Gt) Inverse() {
+	c := coefficients(&g.Fp12)
+	for i := 6; i < 12; i++ {
+		v := new(big.Int).SetBytes(c[i].ToBEndian())
+		if v.Sign() != 0 {
+			v.Sub(&fpModulus, v)
+		}
+		c[i].FromBEndian(v.FillBytes(make([]byte, fpByteSize)))
+	}
+}
+
+// Conjugate is Inverse, which already conjugates.
+func (g *bls12381Gt) Conjugate() {
+	g.Inverse()
+}
+
+func (g *bls12381Gt) Mul(a driver.Gt) {
+	g.Fp12.MulAssign(&a.(*bls12381Gt).Fp12)
+}
+
+func (g *bls12381Gt) IsUnity() bool {
+	one := blst.Fp12One()
+	return g.Fp12.Equals(&one)
+}
+
+func (g *bls12381Gt) ToString() string {
+	return fmt.Sprintf("%x", g.Bytes())
+}
+
+// Bytes follows the encoding of the kilic and gurvy drivers, which write
+// the coefficients from the most significant one down.
+func (g *bls12381Gt) Bytes() []byte {
+	c := coefficients(&g.Fp12)
+	out := make([]byte, 0, 12*fpByteSize)
+	for i := 11; i >= 0; i-- {
+		out = append(out, c[i].ToBEndian()...)
+	}
+	return out
+}
+
+/*********************************************************************/
+
+func NewBls12_381() *Bls12_381 {
+	c := &Bls12_381{CurveBase: common.CurveBase{Modulus: frModulus}}
+	c.genGt = c.Pairing(c.GenG2(), c.GenG1()).(*bls12381Gt).Fp12
+	return c
+}
+
+type Bls12_381 struct {
+	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt blst.Fp12
+}
+
+func (c *Bls12_381) Info() *driver.CurveInfo {
+	return common.BLS12_381Info()
+}
+
+func (c *Bls12_381) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	return pairing([]*bls12381G2{p2.(*bls12381G2)}, []*bls12381G1{p1.(*bls12381G1)})
+}
+
+func (c *Bls12_381) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	return pairing([]*bls12381G2{p2a.(*bls12381G2), p2b.(*bls12381G2)}, []*bls12381G1{p1a.(*bls12381G1), p1b.(*bls12381G1)})
+}
+
+// pairing returns the final-exponentiated product of the pairings of p2[i]
+// and p1[i]. It leaves out the pairs with a point at infinity, whose
+// pairing is the unity but which the Miller loop of blst does not expect.
+func pairing(p2 []*bls12381G2, p1 []*bls12381G1) driver.Gt {
+	qs := make([]blst.P2Affine, 0, len(p2))
+	ps := make([]blst.P1Affine, 0, len(p1))
+	for i := range p2 {
+		if p2[i].P2.Equals(&blst.P2{}) || p1[i].P1.Equals(&blst.P1{}) {
+			continue
+		}
+		qs = append(qs, *p2[i].P2.ToAffine())
+		ps = append(ps, *p1[i].P1.ToAffine())
+	}
+
+	if len(qs) == 0 {
+		return &bls12381Gt{blst.Fp12One()}
+	}
+
+	t := blst.Fp12MillerLoopN(qs, ps)
+	t.FinalExp()
+
+	return &bls12381Gt{*t}
+}
+
+func (c *Bls12_381) FExp(a driver.Gt) driver.Gt {
+	return a
+}
+
+func (c *Bls12_381) GenG1() driver.G1 {
+	return &bls12381G1{*blst.P1Generator()}
+}
+
+func (c *Bls12_381) GenG2() driver.G2 {
+	return &bls12381G2{*blst.P2Generator()}
+}
+
+func (c *Bls12_381) GenGt() driver.Gt {
+	return &bls12381Gt{c.genGt}
+}
+
+func (c *Bls12_381) CoordinateByteSize() int {
+	return fpByteSize
+}
+
+func (c *Bls12_381) G1ByteSize() int {
+	return blst.BLST_P1_SERIALIZE_BYTES
+}
+
+func (c *Bls12_381) CompressedG1ByteSize() int {
+	return blst.BLST_P1_COMPRESS_BYTES
+}
+
+func (c *Bls12_381) G2ByteSize() int {
+	return blst.BLST_P2_SERIALIZE_BYTES
+}
+
+func (c *Bls12_381) CompressedG2ByteSize() int {
+	return blst.BLST_P2_COMPRESS_BYTES
+}
+
+func (c *Bls12_381) GtByteSize() int {
+	return 12 * fpByteSize
+}
+
+func (c *Bls12_381) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Bls12_381) NewG1() driver.G1 {
+	return &bls12381G1{}
+}
+
+func (c *Bls12_381) NewG2() driver.G2 {
+	return &bls12381G2{}
+}
+
+func (c *Bls12_381) InfinityG1() driver.G1 {
+	return &bls12381G1{}
+}
+
+func (c *Bls12_381) InfinityG2() driver.G2 {
+	return &bls12381G2{}
+}
+
+func (c *Bls12_381) SumG2(points []driver.G2) driver.G2 {
+	acc := c.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
+	}
+
+	return acc
+}
+
+// setBytesFailed returns the reason why blst rejected an encoding, which
+// blst does not report: the encoding is the one of kilic, whose decoder
+// returns it as err.
+func setBytesFailed(_ interface{}, err error) error {
+	if err == nil {
+		return errors.New("set bytes failed")
+	}
+
+	return err
+}
+
+func (c *Bls12_381) NewG1FromBytes(b []byte) (driver.G1, error) {
+	p := new(blst.P1Affine).Deserialize(b)
+	if p == nil {
+		return nil, setBytesFailed(kilic.NewBls12_381().NewG1FromBytes(b))
+	}
+	if !p.InG1() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	res := &bls12381G1{}
+	res.P1.FromAffine(p)
+	return res, nil
+}
+
+func (c *Bls12_381) NewG2FromBytes(b []byte) (driver.G2, error) {
+	p := new(blst.P2Affine).Deserialize(b)
+	if p == nil {
+		return nil, setBytesFailed(kilic.NewBls12_381().NewG2FromBytes(b))
+	}
+	if !p.InG2() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	res := &bls12381G2{}
+	res.P2.FromAffine(p)
+	return res, nil
+}
+
+func (c *Bls12_381) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	p := new(blst.P1Affine).Uncompress(b)
+	if p == nil {
+		return nil, setBytesFailed(kilic.NewBls12_381().NewG1FromCompressed(b))
+	}
+	if !p.InG1() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	res := &bls12381G1{}
+	res.P1.FromAffine(p)
+	return res, nil
+}
+
+func (c *Bls12_381) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	p := new(blst.P2Affine).Uncompress(b)
+	if p == nil {
+		return nil, setBytesFailed(kilic.NewBls12_381().NewG2FromCompressed(b))
+	}
+	if !p.InG2() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	res := &bls12381G2{}
+	res.P2.FromAffine(p)
+	return res, nil
+}
+
+func (c *Bls12_381) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	if len(b) != 12*fpByteSize {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	res := &bls12381Gt{}
+	co := coefficients(&res.Fp12)
+	for i := 0; i < 12; i++ {
+		co[11-i].FromBEndian(b[i*fpByteSize : (i+1)*fpByteSize])
+	}
+
+	return res, nil
+}
+
+func (c *Bls12_381) HashToG1(data []byte) driver.G1 {
+	return &bls12381G1{*blst.HashToG1(data, nil)}
+}
+
+func (c *Bls12_381) HashToG2(data []byte) driver.G2 {
+	return &bls12381G2{*blst.HashToG2(data, nil)}
+}
+
+func (c *Bls12_381) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	return &bls12381G1{*blst.HashToG1(data, domain)}
+}
+
+// HashToG1WithU and MapToG1 are served by the kilic driver since blst does
+// not export its map-to-curve; the result is converted through its encoding.
+func (c *Bls12_381) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	p, u := kilic.NewBls12_381().HashToG1WithU(data, domain)
+	return c.g1FromKilic(p), u
+}
+
+func (c *Bls12_381) MapToG1(u []driver.Zr) driver.G1 {
+	return c.g1FromKilic(kilic.NewBls12_381().MapToG1(u))
+}
+
+func (c *Bls12_381) MapFieldToG1(b []byte) (driver.G1, error) {
+	p, err := kilic.NewBls12_381().MapFieldToG1(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG1FromBytes(p.Bytes())
+}
+
+func (c *Bls12_381) MapFieldToG2(b []byte) (driver.G2, error) {
+	p, err := kilic.NewBls12_381().MapFieldToG2(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG2FromBytes(p.Bytes())
+}
+
+func (c *Bls12_381) g1FromKilic(p driver.G1) driver.G1 {
+	g, err := c.NewG1FromBytes(p.Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
+
+func (c *Bls12_381) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	return &bls12381G2{*blst.HashToG2(data, domain)}
+}
@@ -0,0 +1,9 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blst implements BLS12-381 on top of the supranational/blst C
+// library. It is empty unless built with cgo and the mathlib_blst tag.
+package blst
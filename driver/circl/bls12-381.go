@@ -0,0 +1,468 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package circl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/IBM/mathlib/driver/kilic"
+	bls12381 "github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/cloudflare/circl/ecc/bls12381/ff"
+	kilicbls "github.com/kilic/bls12-381"
+)
+
+var frModulus big.Int // r stored as big.Int
+func init() {
+	frModulus.SetBytes(bls12381.Order())
+}
+
+func toScalar(z driver.Zr) *bls12381.Scalar {
+	s := &bls12381.Scalar{}
+	s.SetBytes(common.BigToBytes(new(big.Int).Mod(&z.(*common.BaseZr).Int, &frModulus)))
+	return s
+}
+
+// g1Gen and g2Gen are compared against by IsGenerator
+var (
+	g1Gen = bls12381.G1Generator()
+	g2Gen = bls12381.G2Generator()
+)
+
+/*********************************************************************/
+
+type bls12381G1 struct {
+	bls12381.G1
+}
+
+func newG1() *bls12381G1 {
+	g := &bls12381G1{}
+	g.G1.SetIdentity()
+	return g
+}
+
+func (g *bls12381G1) Clone(a driver.G1) {
+	g.G1 = a.(*bls12381G1).G1
+}
+
+func (g *bls12381G1) Copy() driver.G1 {
+	return &bls12381G1{g.G1}
+}
+
+func (g *bls12381G1) Add(a driver.G1) {
+	g.G1.Add(&g.G1, &a.(*bls12381G1).G1)
+}
+
+func (g *bls12381G1) Mul(a driver.Zr) driver.G1 {
+	res := &bls12381G1{}
+	res.G1.ScalarMult(toScalar(a), &g.G1)
+	return res
+}
+
+func (g *bls12381G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *bls12381G1) Equals(a driver.G1) bool {
+	return g.G1.IsEqual(&a.(*bls12381G1).G1)
+}
+
+func (g *bls12381G1) Bytes() []byte {
+	return g.G1.Bytes()
+}
+
+func (g *bls12381G1) Compressed() []byte {
+	return g.G1.BytesCompressed()
+}
+
+func (g *bls12381G1) Sub(a driver.G1) {
+	neg := a.(*bls12381G1).G1
+	neg.Neg()
+	g.G1.Add(&g.G1, &neg)
+}
+
+func (g *bls12381G1) IsInfinity() bool {
+	return g.G1.IsIdentity()
+}
+
+func (g *bls12381G1) IsGenerator() bool {
+	return g.G1.IsEqual(g1Gen)
+}
+
+// IsOnCurve checks the affine coordinates with the kilic library, as circl
+// only exposes the on-curve check together with the subgroup one.
+func (g *bls12381G1) IsOnCurve() bool {
+	if g.G1.IsIdentity() {
+		return true
+	}
+
+	_, err := kilicbls.NewG1().FromBytes(g.G1.Bytes())
+	return err == nil
+}
+
+func (g *bls12381G1) String() string {
+	gb := g.Bytes()
+	x := new(big.Int).SetBytes(gb[:len(gb)/2])
+	y := new(big.Int).SetBytes(gb[len(gb)/2:])
+
+	return "(" + x.String() + "," + y.String() + ")"
+}
+
+func (g *bls12381G1) Neg() {
+	g.G1.Neg()
+}
+
+/*********************************************************************/
+
+type bls12381G2 struct {
+	bls12381.G2
+}
+
+func newG2() *bls12381G2 {
+	g := &bls12381G2{}
+	g.G2.SetIdentity()
+	return g
+}
+
+func (g *bls12381G2) Clone(a driver.G2) {
+	g.G2 = a.(*bls12381G2).G2
+}
+
+func (g *bls12381G2) Copy() driver.G2 {
+	return &bls12381G2{g.G2}
+}
+
+func (g *bls12381G2) Mul(a driver.Zr) driver.G2 {
+	res := &bls12381G2{}
+	res.G2.ScalarMult(toScalar(a), &g.G2)
+	return res
+}
+
+func (g *bls12381G2) Add(a driver.G2) {
+	g.G2.Add(&g.G2, &a.(*bls12381G2).G2)
+}
+
+func (g *bls12381G2) Sub(a driver.G2) {
+	neg := a.(*bls12381G2).G2
+	neg.Neg()
+	g.G2.Add(&g.G2, &neg)
+}
+
+// Affine is a no-op: circl does not expose the normalization of a point,
+// which it performs anyway whenever the point is encoded or paired.
+func (g *bls12381G2) Affine() {
+}
+
+func (g *bls12381G2) Bytes() []byte {
+	return g.G2.Bytes()
+}
+
+func (g *bls12381G2) Compressed() []byte {
+	return g.G2.BytesCompressed()
+}
+
+func (g *bls12381G2) String() string {
+	return fmt.Sprintf("%x", g.Bytes())
+}
+
+func (g *bls12381G2) Equals(a driver.G2) bool {
+	return g.G2.IsEqual(&a.(*bls12381G2).G2)
+}
+
+func (g *bls12381G2) IsGenerator() bool {
+	return g.G2.IsEqual(g2Gen)
+}
+
+func (g *bls12381G2) IsOnCurve() bool {
+	if g.G2.IsIdentity() {
+		return true
+	}
+
+	_, err := kilicbls.NewG2().FromBytes(g.G2.Bytes())
+	return err == nil
+}
+
+/*********************************************************************/
+
+type bls12381Gt struct {
+	bls12381.Gt
+}
+
+func (g *bls12381Gt) Exp(x driver.Zr) driver.Gt {
+	res := &bls12381Gt{}
+	res.Gt.Exp(&g.Gt, toScalar(x))
+	return res
+}
+
+func (g *bls12381Gt) Equals(a driver.Gt) bool {
+	return g.Gt.IsEqual(&a.(*bls12381Gt).Gt)
+}
+
+func (g *bls12381Gt) Inverse() {
+	g.Gt.Inv(&g.Gt)
+}
+
+// Conjugate falls back to Inverse, circl does not export the conjugation.
+func (g *bls12381Gt) Conjugate() {
+	g.Inverse()
+}
+
+func (g *bls12381Gt) Mul(a driver.Gt) {
+	g.Gt.Mul(&g.Gt, &a.(*bls12381Gt).Gt)
+}
+
+func (g *bls12381Gt) IsUnity() bool {
+	return g.Gt.IsIdentity()
+}
+
+func (g *bls12381Gt) ToString() string {
+	return fmt.Sprintf("%x", g.Bytes())
+}
+
+func (g *bls12381Gt) Bytes() []byte {
+	b, err := g.Gt.MarshalBinary()
+	if err != nil {
+		panic(fmt.Sprintf("marshal failed [%s]", err.Error()))
+	}
+
+	return b
+}
+
+/*********************************************************************/
+
+func NewBls12_381() *Bls12_381 {
+	c := &Bls12_381{CurveBase: common.CurveBase{Modulus: frModulus}}
+	c.genGt = c.Pairing(c.GenG2(), c.GenG1()).(*bls12381Gt).Gt
+	return c
+}
+
+type Bls12_381 struct {
+	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bls12381.Gt
+}
+
+func (c *Bls12_381) Info() *driver.CurveInfo {
+	return common.BLS12_381Info()
+}
+
+// Pairing works on a copy of p1 since circl normalizes its
+// arguments in place.
+func (c *Bls12_381) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	p := p1.(*bls12381G1).G1
+
+	return &bls12381Gt{*bls12381.Pair(&p, &p2.(*bls12381G2).G2)}
+}
+
+// Pairing2 leaves out the pairs with the G1 point at infinity, which
+// ProdPairFrac does not expect: affinizing it breaks the whole product,
+// which then always ends up the unity.
+func (c *Bls12_381) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	switch {
+	case p1a.IsInfinity():
+		return c.Pairing(p2b, p1b)
+	case p1b.IsInfinity():
+		return c.Pairing(p2a, p1a)
+	}
+
+	pa := p1a.(*bls12381G1).G1
+	pb := p1b.(*bls12381G1).G1
+
+	return &bls12381Gt{*bls12381.ProdPairFrac(
+		[]*bls12381.G1{&pa, &pb},
+		[]*bls12381.G2{&p2a.(*bls12381G2).G2, &p2b.(*bls12381G2).G2},
+		[]int{1, 1})}
+}
+
+func (c *Bls12_381) FExp(a driver.Gt) driver.Gt {
+	return a
+}
+
+func (c *Bls12_381) GenG1() driver.G1 {
+	return &bls12381G1{*bls12381.G1Generator()}
+}
+
+func (c *Bls12_381) GenG2() driver.G2 {
+	return &bls12381G2{*bls12381.G2Generator()}
+}
+
+func (c *Bls12_381) GenGt() driver.Gt {
+	return &bls12381Gt{c.genGt}
+}
+
+func (c *Bls12_381) CoordinateByteSize() int {
+	return ff.FpSize
+}
+
+func (c *Bls12_381) G1ByteSize() int {
+	return bls12381.G1Size
+}
+
+func (c *Bls12_381) CompressedG1ByteSize() int {
+	return bls12381.G1SizeCompressed
+}
+
+func (c *Bls12_381) G2ByteSize() int {
+	return bls12381.G2Size
+}
+
+func (c *Bls12_381) CompressedG2ByteSize() int {
+	return bls12381.G2SizeCompressed
+}
+
+func (c *Bls12_381) GtByteSize() int {
+	return bls12381.GtSize
+}
+
+func (c *Bls12_381) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Bls12_381) NewG1() driver.G1 {
+	return newG1()
+}
+
+func (c *Bls12_381) NewG2() driver.G2 {
+	return newG2()
+}
+
+func (c *Bls12_381) InfinityG1() driver.G1 {
+	return newG1()
+}
+
+func (c *Bls12_381) InfinityG2() driver.G2 {
+	return newG2()
+}
+
+func (c *Bls12_381) SumG2(points []driver.G2) driver.G2 {
+	acc := c.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
+	}
+
+	return acc
+}
+
+func (c *Bls12_381) NewG1FromBytes(b []byte) (driver.G1, error) {
+	return c.newG1FromBytes(b, bls12381.G1Size)
+}
+
+func (c *Bls12_381) NewG2FromBytes(b []byte) (driver.G2, error) {
+	return c.newG2FromBytes(b, bls12381.G2Size)
+}
+
+func (c *Bls12_381) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	return c.newG1FromBytes(b, bls12381.G1SizeCompressed)
+}
+
+func (c *Bls12_381) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	return c.newG2FromBytes(b, bls12381.G2SizeCompressed)
+}
+
+// circl accepts both encodings in SetBytes, so the expected one is
+// enforced through its length.
+func (c *Bls12_381) newG1FromBytes(b []byte, size int) (driver.G1, error) {
+	if len(b) != size {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	g := &bls12381G1{}
+	if err := g.G1.SetBytes(b); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return g, nil
+}
+
+func (c *Bls12_381) newG2FromBytes(b []byte, size int) (driver.G2, error) {
+	if len(b) != size {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	g := &bls12381G2{}
+	if err := g.G2.SetBytes(b); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return g, nil
+}
+
+func (c *Bls12_381) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	g := &bls12381Gt{}
+	if err := g.Gt.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return g, nil
+}
+
+func (c *Bls12_381) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+func (c *Bls12_381) HashToG2(data []byte) driver.G2 {
+	return c.HashToG2WithDomain(data, []byte{})
+}
+
+func (c *Bls12_381) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g := &bls12381G1{}
+	g.G1.Hash(data, domain)
+	return g
+}
+
+func (c *Bls12_381) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	g := &bls12381G2{}
+	g.G2.Hash(data, domain)
+	return g
+}
+
+// circl keeps its SSWU map private, so the field elements and their mapping
+// come from the kilic driver, which implements the same suite.
+func (c *Bls12_381) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	p, u := kilic.NewBls12_381().HashToG1WithU(data, domain)
+	return c.g1FromKilic(p), u
+}
+
+func (c *Bls12_381) MapToG1(u []driver.Zr) driver.G1 {
+	return c.g1FromKilic(kilic.NewBls12_381().MapToG1(u))
+}
+
+func (c *Bls12_381) MapFieldToG1(b []byte) (driver.G1, error) {
+	p, err := kilic.NewBls12_381().MapFieldToG1(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG1FromBytes(p.Bytes())
+}
+
+func (c *Bls12_381) MapFieldToG2(b []byte) (driver.G2, error) {
+	p, err := kilic.NewBls12_381().MapFieldToG2(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG2FromBytes(p.Bytes())
+}
+
+// g1FromKilic converts a point computed by the kilic driver, which shares
+// the encoding of this one.
+func (c *Bls12_381) g1FromKilic(p driver.G1) driver.G1 {
+	g, err := c.NewG1FromBytes(p.Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
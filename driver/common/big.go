@@ -10,6 +10,7 @@ import (
 	"math/big"
 
 	"github.com/IBM/mathlib/driver"
+	"github.com/consensys/gnark-crypto/field/pool"
 )
 
 var onebytes = []byte{
@@ -22,19 +23,41 @@ var onebig = new(big.Int).SetBytes(onebytes)
 
 const ScalarByteSize = 32
 
+// BigToBytes returns bi on ScalarByteSize big-endian bytes, in two's
+// complement if it is negative. It panics if bi does not fit; values wider
+// than a scalar, e.g. base field elements, go through BigToBytesWide.
 func BigToBytes(bi *big.Int) []byte {
-	b := bi.Bytes()
-
 	if bi.Sign() >= 0 {
-		return append(make([]byte, ScalarByteSize-len(b)), b...)
+		return bi.FillBytes(make([]byte, ScalarByteSize))
 	}
 
 	twoscomp := new(big.Int).Set(onebig)
 	pos := new(big.Int).Neg(bi)
 	twoscomp = twoscomp.Sub(twoscomp, pos)
 	twoscomp = twoscomp.Add(twoscomp, big.NewInt(1))
-	b = twoscomp.Bytes()
-	return append(onebytes[:ScalarByteSize-len(b)], b...)
+	b := twoscomp.Bytes()
+	return append(onebytes[:ScalarByteSize-len(b):ScalarByteSize-len(b)], b...)
+}
+
+// BigToBytesWide returns the non-negative bi on size big-endian bytes,
+// which is at least ScalarByteSize, e.g. the byte size of a base field.
+func BigToBytesWide(bi *big.Int, size int) []byte {
+	if size < ScalarByteSize {
+		size = ScalarByteSize
+	}
+
+	return bi.FillBytes(make([]byte, size))
+}
+
+// Normalize returns the representative of bi in [0, m). Values that
+// already lie in that range are returned as they are; anything else,
+// including m itself, is reduced into a fresh big.Int.
+func Normalize(bi, m *big.Int) *big.Int {
+	if bi.Sign() >= 0 && bi.Cmp(m) < 0 {
+		return bi
+	}
+
+	return new(big.Int).Mod(bi, m)
 }
 
 type BaseZr struct {
@@ -61,9 +84,73 @@ func (b *BaseZr) Mul(a driver.Zr) driver.Zr {
 	return rv
 }
 
+// AddInPlace sets b to b + a modulo the modulus.
+func (b *BaseZr) AddInPlace(a driver.Zr) {
+	b.Int.Add(&b.Int, &a.(*BaseZr).Int)
+	b.reduce()
+}
+
+// SubInPlace sets b to b - a modulo the modulus.
+func (b *BaseZr) SubInPlace(a driver.Zr) {
+	b.Int.Sub(&b.Int, &a.(*BaseZr).Int)
+	b.reduce()
+}
+
+// MulInPlace sets b to b * a modulo the modulus, computing the product in a
+// big.Int taken from the pool.
+func (b *BaseZr) MulInPlace(a driver.Zr) {
+	t := pool.BigInt.Get()
+	t.Mul(&b.Int, &a.(*BaseZr).Int)
+	b.modInto(t)
+	pool.BigInt.Put(t)
+}
+
+// reduce brings b into [0, m). Sums and differences of reduced values are
+// at most one modulus away from it.
+func (b *BaseZr) reduce() {
+	switch {
+	case b.Int.Sign() < 0:
+		b.Int.Add(&b.Int, &b.Modulus)
+	case b.Int.Cmp(&b.Modulus) >= 0:
+		b.Int.Sub(&b.Int, &b.Modulus)
+	}
+
+	if b.Int.Sign() >= 0 && b.Int.Cmp(&b.Modulus) < 0 {
+		return
+	}
+
+	t := pool.BigInt.Get()
+	t.Set(&b.Int)
+	b.modInto(t)
+	pool.BigInt.Put(t)
+}
+
+// modInto sets b to x mod m, with the quotient taken from the pool.
+func (b *BaseZr) modInto(x *big.Int) {
+	q := pool.BigInt.Get()
+	q.QuoRem(x, &b.Modulus, &b.Int)
+	pool.BigInt.Put(q)
+
+	if b.Int.Sign() < 0 {
+		b.Int.Add(&b.Int, &b.Modulus)
+	}
+}
+
 func (b *BaseZr) PowMod(x driver.Zr) driver.Zr {
 	rv := &BaseZr{Modulus: b.Modulus}
-	rv.Exp(&b.Int, &x.(*BaseZr).Int, &b.Modulus)
+
+	e := &x.(*BaseZr).Int
+	if e.Sign() < 0 {
+		// b^(-e) is computed as (b^-1)^e; a non-invertible base yields zero
+		inv := new(big.Int).ModInverse(&b.Int, &b.Modulus)
+		if inv == nil {
+			return rv
+		}
+		rv.Exp(inv, new(big.Int).Neg(e), &b.Modulus)
+		return rv
+	}
+
+	rv.Exp(&b.Int, e, &b.Modulus)
 	return rv
 }
 
@@ -75,22 +162,14 @@ func (b *BaseZr) InvModP(p driver.Zr) {
 	b.Int.ModInverse(&b.Int, &p.(*BaseZr).Int)
 }
 
+// Bytes returns b reduced modulo its modulus, on ScalarByteSize bytes or on
+// the byte size of the modulus if it is wider, as for base field elements.
 func (b *BaseZr) Bytes() []byte {
-	target := b.Int
-
-	if b.Int.Sign() < 0 || b.Int.Cmp(&b.Modulus) > 0 {
-		target = *new(big.Int).Set(&b.Int)
-		target = *target.Mod(&target, &b.Modulus)
-		if target.Sign() < 0 {
-			target = *target.Add(&target, &b.Modulus)
-		}
-	}
-
-	return BigToBytes(&target)
+	return BigToBytesWide(Normalize(&b.Int, &b.Modulus), (b.Modulus.BitLen()+7)/8)
 }
 
 func (b *BaseZr) Equals(p driver.Zr) bool {
-	return b.Int.Cmp(&p.(*BaseZr).Int) == 0
+	return Normalize(&b.Int, &b.Modulus).Cmp(Normalize(&p.(*BaseZr).Int, &b.Modulus)) == 0
 }
 
 func (b *BaseZr) Copy() driver.Zr {
@@ -100,14 +179,39 @@ func (b *BaseZr) Copy() driver.Zr {
 }
 
 func (b *BaseZr) Clone(a driver.Zr) {
-	raw := a.(*BaseZr).Int.Bytes()
-	b.Int.SetBytes(raw)
+	b.Int.Set(&a.(*BaseZr).Int)
 }
 
 func (b *BaseZr) String() string {
-	return b.Int.Text(16)
+	return Normalize(&b.Int, &b.Modulus).Text(16)
 }
 
 func (b *BaseZr) Neg() {
 	b.Int.Neg(&b.Int)
 }
+
+func (b *BaseZr) Halve() driver.Zr {
+	rv := &BaseZr{Modulus: b.Modulus}
+	rv.Int.Mod(&b.Int, &b.Modulus)
+	if rv.Int.Bit(0) == 1 {
+		rv.Int.Add(&rv.Int, &b.Modulus)
+	}
+	rv.Int.Rsh(&rv.Int, 1)
+	return rv
+}
+
+func (b *BaseZr) IsNegative() bool {
+	return b.Int.Sign() < 0
+}
+
+func (b *BaseZr) Signed() *big.Int {
+	rv := new(big.Int).Mod(&b.Int, &b.Modulus)
+	if rv.Cmp(new(big.Int).Rsh(&b.Modulus, 1)) > 0 {
+		rv.Sub(rv, &b.Modulus)
+	}
+	return rv
+}
+
+func (b *BaseZr) CmpAbs(a driver.Zr) int {
+	return b.Signed().CmpAbs(a.(*BaseZr).Signed())
+}
@@ -35,6 +35,16 @@ func (c *CurveBase) ModMul(a1, b1, m driver.Zr) driver.Zr {
 	return res
 }
 
+// ModMul3 returns a1 * b1 * c1 modulo m, reducing only the full product.
+func (c *CurveBase) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	res := &BaseZr{Modulus: c.Modulus}
+	res.Int.Mul(&a1.(*BaseZr).Int, &b1.(*BaseZr).Int)
+	res.Int.Mul(&res.Int, &c1.(*BaseZr).Int)
+	res.Int.Mod(&res.Int, &m.(*BaseZr).Int)
+
+	return res
+}
+
 func (c *CurveBase) ModSub(a1, b1, m driver.Zr) driver.Zr {
 	res := &BaseZr{Modulus: c.Modulus}
 	res.Int.Sub(&a1.(*BaseZr).Int, &b1.(*BaseZr).Int)
@@ -51,6 +61,32 @@ func (c *CurveBase) ModAdd(a1, b1, m driver.Zr) driver.Zr {
 	return res
 }
 
+// ModAdd2 sets a1 to a1 + b1 + c1 modulo m
+func (c *CurveBase) ModAdd2(a1, b1, c1, m driver.Zr) {
+	a := &a1.(*BaseZr).Int
+	a.Add(a, &b1.(*BaseZr).Int)
+	a.Add(a, &c1.(*BaseZr).Int)
+	a.Mod(a, &m.(*BaseZr).Int)
+}
+
+// ModAddMul returns the sum of a1[i] * b1[i] modulo m
+func (c *CurveBase) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	res := &BaseZr{Modulus: c.Modulus}
+	prod := new(big.Int)
+	for i := range a1 {
+		prod.Mul(&a1[i].(*BaseZr).Int, &b1[i].(*BaseZr).Int)
+		res.Int.Add(&res.Int, prod)
+	}
+	res.Int.Mod(&res.Int, &m.(*BaseZr).Int)
+
+	return res
+}
+
+// ModAddMul2 returns a1 * c1 + b1 * c2 modulo m
+func (c *CurveBase) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
+}
+
 func (c *CurveBase) GroupOrder() driver.Zr {
 	return &BaseZr{Int: c.Modulus, Modulus: c.Modulus}
 }
@@ -88,3 +124,12 @@ func (c *CurveBase) HashToZr(data []byte) driver.Zr {
 func (p *CurveBase) Rand() (io.Reader, error) {
 	return rand.Reader, nil
 }
+
+func (p *CurveBase) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	res := make([]driver.G1, len(scalars))
+	for i, s := range scalars {
+		res[i] = base.Mul(s)
+	}
+
+	return res
+}
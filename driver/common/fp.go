@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// BaseFp is an element of a prime field based on big.Int, for the drivers
+// that do not implement driver.BaseFieldProvider.
+type BaseFp struct {
+	big.Int
+	Modulus *big.Int
+	// Size is the length of the encoding returned by Bytes.
+	Size int
+}
+
+// NewBaseFpFromBytes decodes the canonical big-endian encoding b, which
+// must be size bytes long and smaller than modulus.
+func NewBaseFpFromBytes(b []byte, modulus *big.Int, size int) (*BaseFp, error) {
+	if len(b) != size {
+		return nil, errors.New("invalid length")
+	}
+
+	e := &BaseFp{Modulus: modulus, Size: size}
+	e.SetBytes(b)
+	if e.Cmp(modulus) >= 0 {
+		return nil, errors.New("element not smaller than the modulus")
+	}
+
+	return e, nil
+}
+
+func (e *BaseFp) result() *BaseFp {
+	return &BaseFp{Modulus: e.Modulus, Size: e.Size}
+}
+
+func (e *BaseFp) Add(a driver.Fp) driver.Fp {
+	rv := e.result()
+	rv.Int.Add(&e.Int, &a.(*BaseFp).Int)
+	rv.Mod(&rv.Int, e.Modulus)
+	return rv
+}
+
+func (e *BaseFp) Sub(a driver.Fp) driver.Fp {
+	rv := e.result()
+	rv.Int.Sub(&e.Int, &a.(*BaseFp).Int)
+	rv.Mod(&rv.Int, e.Modulus)
+	return rv
+}
+
+func (e *BaseFp) Mul(a driver.Fp) driver.Fp {
+	rv := e.result()
+	rv.Int.Mul(&e.Int, &a.(*BaseFp).Int)
+	rv.Mod(&rv.Int, e.Modulus)
+	return rv
+}
+
+func (e *BaseFp) Neg() driver.Fp {
+	rv := e.result()
+	rv.Int.Neg(&e.Int)
+	rv.Mod(&rv.Int, e.Modulus)
+	return rv
+}
+
+func (e *BaseFp) Inverse() driver.Fp {
+	rv := e.result()
+	rv.ModInverse(&e.Int, e.Modulus)
+	return rv
+}
+
+func (e *BaseFp) Sqrt() (driver.Fp, bool) {
+	rv := e.result()
+	if rv.ModSqrt(&e.Int, e.Modulus) == nil {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func (e *BaseFp) Equals(a driver.Fp) bool {
+	return e.Cmp(&a.(*BaseFp).Int) == 0
+}
+
+func (e *BaseFp) IsZero() bool {
+	return e.Sign() == 0
+}
+
+func (e *BaseFp) Bytes() []byte {
+	return e.FillBytes(make([]byte, e.Size))
+}
+
+func (e *BaseFp) String() string {
+	return e.Text(16)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// HexInt parses a hexadecimal constant, panicking if it is malformed.
+func HexInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hexadecimal constant " + s)
+	}
+	return i
+}
+
+// BLS12_381Info returns the parameters of BLS12-381, shared by all of its
+// drivers.
+func BLS12_381Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		EmbeddingDegree:    12,
+		G1Cofactor:         HexInt("396c8c005555e1568c00aaab0000aaab"),
+		G2Cofactor:         HexInt("5d543a95414e7f1091d50792876a202cd91de4547085abaa68a205b2e5a7ddfa628f1cb4d9e82ef21537e293a6691ae1616ec6e786f0c70cf1c38e31c7238e5"),
+		BaseFieldModulus:   HexInt("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab"),
+		ScalarFieldModulus: HexInt("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"),
+	}
+}
+
+// FP256BNInfo returns the parameters of the FP256BN curve of MIRACL/AMCL.
+// Like other BN curves of its size, it falls short of 128 bits of security
+// after the recent improvements of the number field sieve.
+func FP256BNInfo() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  100,
+		EmbeddingDegree:    12,
+		G1Cofactor:         big.NewInt(1),
+		G2Cofactor:         HexInt("fffffffffffcf0cd46e5f25eee71a4a00cdc65fb129682eab025084a8c9b1019"),
+		BaseFieldModulus:   HexInt("fffffffffffcf0cd46e5f25eee71a49f0cdc65fb12980a82d3292ddbaed33013"),
+		ScalarFieldModulus: HexInt("fffffffffffcf0cd46e5f25eee71a49e0cdc65fb1299921af62d536cd10b500d"),
+	}
+}
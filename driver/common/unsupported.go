@@ -0,0 +1,38 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import "github.com/IBM/mathlib/driver"
+
+// UnsupportedG2 and UnsupportedGt stand in for the groups that a curve
+// without pairing lacks, so that a Curve can still be built around its
+// driver.
+type UnsupportedG2 struct{}
+
+func (UnsupportedG2) Clone(driver.G2)         { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Copy() driver.G2         { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Mul(driver.Zr) driver.G2 { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Add(driver.G2)           { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Sub(driver.G2)           { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Affine()                 { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Bytes() []byte           { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) Compressed() []byte      { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) String() string          { return "unsupported" }
+func (UnsupportedG2) Equals(driver.G2) bool   { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) IsGenerator() bool       { panic(driver.ErrUnsupported) }
+func (UnsupportedG2) IsOnCurve() bool         { panic(driver.ErrUnsupported) }
+
+type UnsupportedGt struct{}
+
+func (UnsupportedGt) Exp(driver.Zr) driver.Gt { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) Equals(driver.Gt) bool   { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) Inverse()                { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) Conjugate()              { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) Mul(driver.Gt)           { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) IsUnity() bool           { panic(driver.ErrUnsupported) }
+func (UnsupportedGt) ToString() string        { return "unsupported" }
+func (UnsupportedGt) Bytes() []byte           { panic(driver.ErrUnsupported) }
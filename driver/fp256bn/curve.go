@@ -0,0 +1,342 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fp256bn
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver/fp256bn/fp"
+)
+
+// Points are kept in homogeneous projective coordinates and combined with
+// the complete formulas of Renes, Costello and Batina for a = 0
+// (https://eprint.iacr.org/2015/1060), which hold for all inputs as both
+// groups have odd order. The point at infinity is (0 : 1 : 0).
+
+// the curve is y^2 = x^3 + b over Fp, and y^2 = x^3 + b * (1 + i) over Fp2
+const curveB = 3
+
+var (
+	b3G1 fp.Element // 3 * b
+	b3G2 e2         // 3 * b * (1 + i)
+)
+
+func init() {
+	b3G1.SetUint64(3 * curveB)
+	b3G2.a.SetUint64(3 * curveB)
+	b3G2.mulByNonResidue(&b3G2)
+}
+
+/*********************************************************************/
+
+type g1Point struct {
+	x, y, z fp.Element
+}
+
+func (p *g1Point) setInfinity() *g1Point {
+	p.x.SetZero()
+	p.y.SetOne()
+	p.z.SetZero()
+	return p
+}
+
+func (p *g1Point) isInfinity() bool {
+	return p.z.IsZero()
+}
+
+func (p *g1Point) equal(q *g1Point) bool {
+	var a, c fp.Element
+	a.Mul(&p.x, &q.z)
+	c.Mul(&q.x, &p.z)
+	if !a.Equal(&c) {
+		return false
+	}
+	a.Mul(&p.y, &q.z)
+	c.Mul(&q.y, &p.z)
+	return a.Equal(&c)
+}
+
+func (p *g1Point) neg(q *g1Point) *g1Point {
+	p.x = q.x
+	p.y.Neg(&q.y)
+	p.z = q.z
+	return p
+}
+
+func (p *g1Point) affine() *g1Point {
+	if p.isInfinity() {
+		return p.setInfinity()
+	}
+	var zInv fp.Element
+	zInv.Inverse(&p.z)
+	p.x.Mul(&p.x, &zInv)
+	p.y.Mul(&p.y, &zInv)
+	p.z.SetOne()
+	return p
+}
+
+// isOnCurve checks y^2 * z = x^3 + b * z^3
+func (p *g1Point) isOnCurve() bool {
+	var lhs, rhs, t fp.Element
+	lhs.Square(&p.y).Mul(&lhs, &p.z)
+	rhs.Square(&p.x).Mul(&rhs, &p.x)
+	t.Square(&p.z).Mul(&t, &p.z)
+	t.Mul(&t, new(fp.Element).SetUint64(curveB))
+	rhs.Add(&rhs, &t)
+	return lhs.Equal(&rhs)
+}
+
+func (p *g1Point) double(q *g1Point) *g1Point {
+	var t0, t1, t2, x3, y3, z3 fp.Element
+
+	t0.Square(&q.y)
+	t1.Mul(&q.y, &q.z)
+	t2.Square(&q.z)
+	z3.Double(&t0).Double(&z3).Double(&z3)
+	t2.Mul(&t2, &b3G1)
+	x3.Mul(&t2, &z3)
+	y3.Add(&t0, &t2)
+	z3.Mul(&z3, &t1)
+	t1.Double(&t2)
+	t2.Add(&t2, &t1)
+	t0.Sub(&t0, &t2)
+	y3.Mul(&y3, &t0).Add(&y3, &x3)
+	t1.Mul(&q.x, &q.y)
+	x3.Mul(&t0, &t1).Double(&x3)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *g1Point) add(q, r *g1Point) *g1Point {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fp.Element
+
+	t0.Mul(&q.x, &r.x)
+	t1.Mul(&q.y, &r.y)
+	t2.Mul(&q.z, &r.z)
+	t3.Add(&q.x, &q.y)
+	t4.Add(&r.x, &r.y)
+	t3.Mul(&t3, &t4)
+	t4.Add(&t0, &t1)
+	t3.Sub(&t3, &t4)
+	t4.Add(&q.y, &q.z)
+	x3.Add(&r.y, &r.z)
+	t4.Mul(&t4, &x3)
+	x3.Add(&t1, &t2)
+	t4.Sub(&t4, &x3)
+	x3.Add(&q.x, &q.z)
+	y3.Add(&r.x, &r.z)
+	x3.Mul(&x3, &y3)
+	y3.Add(&t0, &t2)
+	y3.Sub(&x3, &y3)
+	x3.Double(&t0)
+	t0.Add(&t0, &x3)
+	t2.Mul(&t2, &b3G1)
+	z3.Add(&t1, &t2)
+	t1.Sub(&t1, &t2)
+	y3.Mul(&y3, &b3G1)
+	x3.Mul(&y3, &t4)
+	t2.Mul(&t3, &t1)
+	x3.Sub(&t2, &x3)
+	y3.Mul(&y3, &t0)
+	t1.Mul(&t1, &z3)
+	y3.Add(&y3, &t1)
+	t0.Mul(&t0, &t3)
+	z3.Mul(&z3, &t4).Add(&z3, &t0)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *g1Point) selectIf(c uint, q, r *g1Point) *g1Point {
+	p.x.Select(int(c), &r.x, &q.x)
+	p.y.Select(int(c), &r.y, &q.y)
+	p.z.Select(int(c), &r.z, &q.z)
+	return p
+}
+
+// scalarMul sets p to [k]q for 0 <= k < 2^256, in time independent of k
+func (p *g1Point) scalarMul(q *g1Point, k *big.Int) *g1Point {
+	var res, t g1Point
+	res.setInfinity()
+	base := *q
+
+	for i := 255; i >= 0; i-- {
+		res.double(&res)
+		t.add(&res, &base)
+		res.selectIf(k.Bit(i), &t, &res)
+	}
+
+	*p = res
+	return p
+}
+
+/*********************************************************************/
+
+type g2Point struct {
+	x, y, z e2
+}
+
+func (p *g2Point) setInfinity() *g2Point {
+	p.x = e2{}
+	p.y.setOne()
+	p.z = e2{}
+	return p
+}
+
+func (p *g2Point) isInfinity() bool {
+	return p.z.isZero()
+}
+
+func (p *g2Point) equal(q *g2Point) bool {
+	var a, c e2
+	a.mul(&p.x, &q.z)
+	c.mul(&q.x, &p.z)
+	if !a.equal(&c) {
+		return false
+	}
+	a.mul(&p.y, &q.z)
+	c.mul(&q.y, &p.z)
+	return a.equal(&c)
+}
+
+func (p *g2Point) neg(q *g2Point) *g2Point {
+	p.x = q.x
+	p.y.neg(&q.y)
+	p.z = q.z
+	return p
+}
+
+func (p *g2Point) affine() *g2Point {
+	if p.isInfinity() {
+		return p.setInfinity()
+	}
+	var zInv e2
+	zInv.inverse(&p.z)
+	p.x.mul(&p.x, &zInv)
+	p.y.mul(&p.y, &zInv)
+	p.z.setOne()
+	return p
+}
+
+// isOnCurve checks y^2 * z = x^3 + b * (1 + i) * z^3
+func (p *g2Point) isOnCurve() bool {
+	var lhs, rhs, t e2
+	lhs.square(&p.y).mul(&lhs, &p.z)
+	rhs.square(&p.x).mul(&rhs, &p.x)
+	t.square(&p.z).mul(&t, &p.z)
+	t.mulByInt(&t, curveB).mulByNonResidue(&t)
+	rhs.add(&rhs, &t)
+	return lhs.equal(&rhs)
+}
+
+func (p *g2Point) isInSubgroup() bool {
+	var q g2Point
+	return q.scalarMul(p, order).isInfinity()
+}
+
+func (p *g2Point) double(q *g2Point) *g2Point {
+	var t0, t1, t2, x3, y3, z3 e2
+
+	t0.square(&q.y)
+	t1.mul(&q.y, &q.z)
+	t2.square(&q.z)
+	z3.add(&t0, &t0)
+	z3.add(&z3, &z3)
+	z3.add(&z3, &z3)
+	t2.mul(&t2, &b3G2)
+	x3.mul(&t2, &z3)
+	y3.add(&t0, &t2)
+	z3.mul(&z3, &t1)
+	t1.add(&t2, &t2)
+	t2.add(&t2, &t1)
+	t0.sub(&t0, &t2)
+	y3.mul(&y3, &t0).add(&y3, &x3)
+	t1.mul(&q.x, &q.y)
+	x3.mul(&t0, &t1)
+	x3.add(&x3, &x3)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *g2Point) add(q, r *g2Point) *g2Point {
+	var t0, t1, t2, t3, t4, x3, y3, z3 e2
+
+	t0.mul(&q.x, &r.x)
+	t1.mul(&q.y, &r.y)
+	t2.mul(&q.z, &r.z)
+	t3.add(&q.x, &q.y)
+	t4.add(&r.x, &r.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&q.y, &q.z)
+	x3.add(&r.y, &r.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&q.x, &q.z)
+	y3.add(&r.x, &r.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&t0, &x3)
+	t2.mul(&t2, &b3G2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(&y3, &b3G2)
+	x3.mul(&y3, &t4)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&y3, &t1)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4).add(&z3, &t0)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *g2Point) selectIf(c uint, q, r *g2Point) *g2Point {
+	for i, d := range []*e2{&p.x, &p.y, &p.z} {
+		s := [...]*e2{&q.x, &q.y, &q.z}[i]
+		t := [...]*e2{&r.x, &r.y, &r.z}[i]
+		d.a.Select(int(c), &t.a, &s.a)
+		d.b.Select(int(c), &t.b, &s.b)
+	}
+	return p
+}
+
+// scalarMul sets p to [k]q for 0 <= k < 2^256, in time independent of k
+func (p *g2Point) scalarMul(q *g2Point, k *big.Int) *g2Point {
+	var res, t g2Point
+	res.setInfinity()
+	base := *q
+
+	for i := 255; i >= 0; i-- {
+		res.double(&res)
+		t.add(&res, &base)
+		res.selectIf(k.Bit(i), &t, &res)
+	}
+
+	*p = res
+	return p
+}
+
+// frobenius applies the untwist-Frobenius-twist endomorphism, f being the
+// inverse of (1 + i)^((p - 1) / 6)
+func (p *g2Point) frobenius(q *g2Point, f *e2) *g2Point {
+	var f2 e2
+	f2.square(f)
+	p.x.conj(&q.x).mul(&p.x, &f2)
+	p.y.conj(&q.y).mul(&p.y, &f2).mul(&p.y, f)
+	p.z.conj(&q.z)
+	return p
+}
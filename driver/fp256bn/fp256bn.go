@@ -0,0 +1,582 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package fp256bn is a pure Go implementation of the FP256BN curve of
+// MIRACL/AMCL, with no dependency other than the field arithmetic generated
+// by gnark-crypto. Points, Gt elements and hashes are encoded exactly as in
+// MIRACL, so that it can replace the FP256BN_AMCL_MIRACL driver without
+// invalidating existing data.
+package fp256bn
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/IBM/mathlib/driver/fp256bn/fp"
+)
+
+// point encodings
+const (
+	uncompressed   = 0x04
+	compressedEven = 0x02
+	compressedOdd  = 0x03
+)
+
+// order is the order r of G1, G2 and Gt
+var order *big.Int
+
+var (
+	genG1 g1Point
+	genG2 g2Point
+)
+
+func init() {
+	order, _ = new(big.Int).SetString("fffffffffffcf0cd46e5f25eee71a49e0cdc65fb1299921af62d536cd10b500d", 16)
+
+	genG1.x.SetOne()
+	genG1.y.SetUint64(2)
+	genG1.z.SetOne()
+
+	genG2.x.a.SetString("0xfe0c3350b4c96c2028560f577c28913ace1c539a12bf843cd22616b689c09efb")
+	genG2.x.b.SetString("0x4ea66057738ac054db5ae1c637d813b924dd78e287d03589d269ed34a37e6a2b")
+	genG2.y.a.SetString("0x702046e7c542a3b376770d75124e3e51efcb24758d615848e909b481bedc27ff")
+	genG2.y.b.SetString("0x0554e3bcd388c29042eea649297eb29f8b4cbe80821a98b3e01281114aad049b")
+	genG2.z.setOne()
+}
+
+func scalar(z driver.Zr) *big.Int {
+	return common.Normalize(&z.(*common.BaseZr).Int, order)
+}
+
+/*********************************************************************/
+
+type fp256bnG1 struct {
+	g1Point
+}
+
+func (g *fp256bnG1) Clone(a driver.G1) {
+	g.g1Point = a.(*fp256bnG1).g1Point
+}
+
+func (g *fp256bnG1) Copy() driver.G1 {
+	return &fp256bnG1{g.g1Point}
+}
+
+func (g *fp256bnG1) Add(a driver.G1) {
+	g.add(&g.g1Point, &a.(*fp256bnG1).g1Point)
+}
+
+func (g *fp256bnG1) Mul(a driver.Zr) driver.G1 {
+	res := &fp256bnG1{}
+	res.scalarMul(&g.g1Point, scalar(a))
+	return res
+}
+
+func (g *fp256bnG1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *fp256bnG1) Equals(a driver.G1) bool {
+	return g.equal(&a.(*fp256bnG1).g1Point)
+}
+
+func (g *fp256bnG1) IsGenerator() bool {
+	return g.equal(&genG1)
+}
+
+func (g *fp256bnG1) IsOnCurve() bool {
+	return g.isInfinity() || g.isOnCurve()
+}
+
+// Bytes returns 0x04 || x || y; the point at infinity has affine
+// coordinates (0, 1), as in MIRACL.
+func (g *fp256bnG1) Bytes() []byte {
+	p := g.g1Point
+	p.affine()
+
+	x := p.x.Bytes()
+	y := p.y.Bytes()
+
+	b := make([]byte, 1+2*fp.Bytes)
+	b[0] = uncompressed
+	copy(b[1:], x[:])
+	copy(b[1+fp.Bytes:], y[:])
+	return b
+}
+
+// Compressed returns 0x02 || x if y is even, and 0x03 || x otherwise
+func (g *fp256bnG1) Compressed() []byte {
+	p := g.g1Point
+	p.affine()
+
+	x := p.x.Bytes()
+	y := p.y.Bytes()
+
+	b := make([]byte, 1+fp.Bytes)
+	b[0] = compressedEven + y[fp.Bytes-1]&1
+	copy(b[1:], x[:])
+	return b
+}
+
+func (g *fp256bnG1) Sub(a driver.G1) {
+	var neg g1Point
+	neg.neg(&a.(*fp256bnG1).g1Point)
+	g.add(&g.g1Point, &neg)
+}
+
+func (g *fp256bnG1) IsInfinity() bool {
+	return g.isInfinity()
+}
+
+func (g *fp256bnG1) String() string {
+	if g.isInfinity() {
+		return "infinity"
+	}
+
+	p := g.g1Point
+	p.affine()
+	return "(" + p.x.Text(16) + "," + p.y.Text(16) + ")"
+}
+
+func (g *fp256bnG1) Neg() {
+	g.neg(&g.g1Point)
+}
+
+/*********************************************************************/
+
+type fp256bnG2 struct {
+	g2Point
+}
+
+func (g *fp256bnG2) Clone(a driver.G2) {
+	g.g2Point = a.(*fp256bnG2).g2Point
+}
+
+func (g *fp256bnG2) Copy() driver.G2 {
+	return &fp256bnG2{g.g2Point}
+}
+
+func (g *fp256bnG2) Mul(a driver.Zr) driver.G2 {
+	res := &fp256bnG2{}
+	res.scalarMul(&g.g2Point, scalar(a))
+	return res
+}
+
+func (g *fp256bnG2) Add(a driver.G2) {
+	g.add(&g.g2Point, &a.(*fp256bnG2).g2Point)
+}
+
+func (g *fp256bnG2) Sub(a driver.G2) {
+	var neg g2Point
+	neg.neg(&a.(*fp256bnG2).g2Point)
+	g.add(&g.g2Point, &neg)
+}
+
+func (g *fp256bnG2) Affine() {
+	g.affine()
+}
+
+// Bytes returns 0x04 || x || y, each coordinate a + b*i being encoded as
+// b || a; the point at infinity has affine coordinates (0, 1).
+func (g *fp256bnG2) Bytes() []byte {
+	p := g.g2Point
+	p.affine()
+
+	b := make([]byte, 1+4*fp.Bytes)
+	b[0] = uncompressed
+	p.x.bytes(b[1:])
+	p.y.bytes(b[1+2*fp.Bytes:])
+	return b
+}
+
+// Compressed returns 0x02 || x or 0x03 || x, depending on the sign of y
+func (g *fp256bnG2) Compressed() []byte {
+	p := g.g2Point
+	p.affine()
+
+	b := make([]byte, 1+2*fp.Bytes)
+	b[0] = compressedEven + byte(p.y.sign())
+	p.x.bytes(b[1:])
+	return b
+}
+
+func (g *fp256bnG2) String() string {
+	if g.isInfinity() {
+		return "infinity"
+	}
+
+	p := g.g2Point
+	p.affine()
+	return "(" + p.x.String() + "," + p.y.String() + ")"
+}
+
+func (g *fp256bnG2) Equals(a driver.G2) bool {
+	return g.equal(&a.(*fp256bnG2).g2Point)
+}
+
+func (g *fp256bnG2) IsGenerator() bool {
+	return g.equal(&genG2)
+}
+
+func (g *fp256bnG2) IsOnCurve() bool {
+	return g.isInfinity() || g.isOnCurve()
+}
+
+/*********************************************************************/
+
+type fp256bnGt struct {
+	e12
+}
+
+func (g *fp256bnGt) Equals(a driver.Gt) bool {
+	return g.equal(&a.(*fp256bnGt).e12)
+}
+
+func (g *fp256bnGt) Inverse() {
+	g.inverse(&g.e12)
+}
+
+func (g *fp256bnGt) Conjugate() {
+	g.conj(&g.e12)
+}
+
+func (g *fp256bnGt) Mul(a driver.Gt) {
+	g.mul(&g.e12, &a.(*fp256bnGt).e12)
+}
+
+func (g *fp256bnGt) IsUnity() bool {
+	return g.isOne()
+}
+
+func (g *fp256bnGt) ToString() string {
+	return g.String()
+}
+
+func (g *fp256bnGt) Bytes() []byte {
+	return g.bytes()
+}
+
+func (g *fp256bnGt) Exp(a driver.Zr) driver.Gt {
+	res := &fp256bnGt{}
+	res.exp(&g.e12, scalar(a))
+	return res
+}
+
+/*********************************************************************/
+
+func NewFp256bn() *Fp256bn {
+	c := &Fp256bn{CurveBase: common.CurveBase{Modulus: *order}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*fp256bnGt).e12
+	return c
+}
+
+// Fp256bn is the BN curve with 256-bit base field of MIRACL/AMCL, with
+// G1 and G2 scalar multiplication and Gt exponentiation in constant time.
+type Fp256bn struct {
+	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt e12
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each projective coordinate with the constant-time Select of fp.
+func (c *Fp256bn) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*fp256bnG1).g1Point, &b.(*fp256bnG1).g1Point
+	rv := &fp256bnG1{}
+	rv.x.Select(cond, &q.x, &p.x)
+	rv.y.Select(cond, &q.y, &p.y)
+	rv.z.Select(cond, &q.z, &p.z)
+	return rv
+}
+
+// SelectG2 is SelectG1 for G2, whose coordinates are selected component
+// by component.
+func (c *Fp256bn) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	p, q := &a.(*fp256bnG2).g2Point, &b.(*fp256bnG2).g2Point
+	rv := &fp256bnG2{}
+	rv.x.a.Select(cond, &q.x.a, &p.x.a)
+	rv.x.b.Select(cond, &q.x.b, &p.x.b)
+	rv.y.a.Select(cond, &q.y.a, &p.y.a)
+	rv.y.b.Select(cond, &q.y.b, &p.y.b)
+	rv.z.a.Select(cond, &q.z.a, &p.z.a)
+	rv.z.b.Select(cond, &q.z.b, &p.z.b)
+	return rv
+}
+
+func (c *Fp256bn) Info() *driver.CurveInfo {
+	return common.FP256BNInfo()
+}
+
+func (c *Fp256bn) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	return &fp256bnGt{*millerLoop(
+		[]*g2Point{&p2.(*fp256bnG2).g2Point},
+		[]*g1Point{&p1.(*fp256bnG1).g1Point},
+	)}
+}
+
+func (c *Fp256bn) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	return &fp256bnGt{*millerLoop(
+		[]*g2Point{&p2a.(*fp256bnG2).g2Point, &p2b.(*fp256bnG2).g2Point},
+		[]*g1Point{&p1a.(*fp256bnG1).g1Point, &p1b.(*fp256bnG1).g1Point},
+	)}
+}
+
+func (c *Fp256bn) FExp(a driver.Gt) driver.Gt {
+	return &fp256bnGt{*finalExp(&a.(*fp256bnGt).e12)}
+}
+
+func (c *Fp256bn) GenG1() driver.G1 {
+	return &fp256bnG1{genG1}
+}
+
+func (c *Fp256bn) GenG2() driver.G2 {
+	return &fp256bnG2{genG2}
+}
+
+func (c *Fp256bn) GenGt() driver.Gt {
+	return &fp256bnGt{c.genGt}
+}
+
+func (c *Fp256bn) CoordinateByteSize() int {
+	return fp.Bytes
+}
+
+func (c *Fp256bn) G1ByteSize() int {
+	return 1 + 2*fp.Bytes
+}
+
+func (c *Fp256bn) CompressedG1ByteSize() int {
+	return 1 + fp.Bytes
+}
+
+func (c *Fp256bn) G2ByteSize() int {
+	return 1 + 4*fp.Bytes
+}
+
+func (c *Fp256bn) CompressedG2ByteSize() int {
+	return 1 + 2*fp.Bytes
+}
+
+func (c *Fp256bn) GtByteSize() int {
+	return 12 * fp.Bytes
+}
+
+func (c *Fp256bn) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Fp256bn) NewG1() driver.G1 {
+	return c.InfinityG1()
+}
+
+func (c *Fp256bn) NewG2() driver.G2 {
+	return c.InfinityG2()
+}
+
+func (c *Fp256bn) InfinityG1() driver.G1 {
+	g := &fp256bnG1{}
+	g.setInfinity()
+	return g
+}
+
+func (c *Fp256bn) InfinityG2() driver.G2 {
+	g := &fp256bnG2{}
+	g.setInfinity()
+	return g
+}
+
+func (c *Fp256bn) SumG2(points []driver.G2) driver.G2 {
+	acc := c.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
+	}
+
+	return acc
+}
+
+func (c *Fp256bn) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) != c.G1ByteSize() || b[0] != uncompressed {
+		return nil, errors.New("set bytes failed [invalid uncompressed encoding]")
+	}
+
+	g := &fp256bnG1{}
+	if err := g.x.SetBytesCanonical(b[1 : 1+fp.Bytes]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	if err := g.y.SetBytesCanonical(b[1+fp.Bytes:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	if g.x.IsZero() && g.y.IsOne() {
+		g.setInfinity()
+		return g, nil
+	}
+	g.z.SetOne()
+
+	if !g.isOnCurve() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+
+	return g, nil
+}
+
+func (c *Fp256bn) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	if len(b) != c.CompressedG1ByteSize() || (b[0] != compressedEven && b[0] != compressedOdd) {
+		return nil, errors.New("set bytes failed [invalid compressed encoding]")
+	}
+
+	g := &fp256bnG1{}
+	if err := g.x.SetBytesCanonical(b[1:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	if g.x.IsZero() && b[0] == compressedOdd {
+		g.setInfinity()
+		return g, nil
+	}
+
+	// y^2 = x^3 + b
+	var rhs fp.Element
+	rhs.Square(&g.x).Mul(&rhs, &g.x).Add(&rhs, new(fp.Element).SetUint64(curveB))
+	if g.y.Sqrt(&rhs) == nil {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+	if y := g.y.Bytes(); y[fp.Bytes-1]&1 != b[0]-compressedEven {
+		g.y.Neg(&g.y)
+	}
+	g.z.SetOne()
+
+	return g, nil
+}
+
+func (c *Fp256bn) NewG2FromBytes(b []byte) (driver.G2, error) {
+	if len(b) != c.G2ByteSize() || b[0] != uncompressed {
+		return nil, errors.New("set bytes failed [invalid uncompressed encoding]")
+	}
+
+	g := &fp256bnG2{}
+	if err := g.x.setBytes(b[1:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	if err := g.y.setBytes(b[1+2*fp.Bytes:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	var one e2
+	if g.x.isZero() && g.y.equal(one.setOne()) {
+		g.setInfinity()
+		return g, nil
+	}
+	g.z.setOne()
+
+	if !g.isOnCurve() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+	if !g.isInSubgroup() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	return g, nil
+}
+
+func (c *Fp256bn) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	if len(b) != c.CompressedG2ByteSize() || (b[0] != compressedEven && b[0] != compressedOdd) {
+		return nil, errors.New("set bytes failed [invalid compressed encoding]")
+	}
+
+	g := &fp256bnG2{}
+	if err := g.x.setBytes(b[1:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+	if g.x.isZero() && b[0] == compressedOdd {
+		g.setInfinity()
+		return g, nil
+	}
+
+	// y^2 = x^3 + b * (1 + i)
+	var rhs, t e2
+	rhs.square(&g.x).mul(&rhs, &g.x)
+	t.a.SetUint64(curveB)
+	t.mulByNonResidue(&t)
+	rhs.add(&rhs, &t)
+	if g.y.sqrt(&rhs) == nil {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+	if g.y.sign() != uint(b[0]-compressedEven) {
+		g.y.neg(&g.y)
+	}
+	g.z.setOne()
+
+	if !g.isInSubgroup() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	return g, nil
+}
+
+func (c *Fp256bn) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	if len(b) != 12*fp.Bytes {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	g := &fp256bnGt{}
+	if err := g.setBytes(b); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return g, nil
+}
+
+func (c *Fp256bn) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+func (c *Fp256bn) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g, _ := c.HashToG1WithU(data, domain)
+	return g
+}
+
+// HashToG1WithU hashes data to two field elements as in RFC 9380, with
+// expand_message_xmd over SHA-256, and maps each of them to G1 with the
+// Shallue-van de Woestijne method as MIRACL does.
+func (c *Fp256bn) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	els, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	u := make([]driver.Zr, len(els))
+	for i := range els {
+		u[i] = &common.BaseZr{Int: *els[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return c.MapToG1(u), u
+}
+
+func (c *Fp256bn) MapToG1(u []driver.Zr) driver.G1 {
+	res := c.InfinityG1().(*fp256bnG1)
+	for _, e := range u {
+		var t fp.Element
+		t.SetBigInt(&e.(*common.BaseZr).Int)
+		p := svdw(&t)
+		res.add(&res.g1Point, &p)
+	}
+
+	return res
+}
+
+func (c *Fp256bn) HashToG2(data []byte) driver.G2 {
+	panic("HashToG2 is not available for this curve")
+}
+
+func (c *Fp256bn) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	panic("HashToG2WithDomain is not available for this curve")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fp256bn
+
+import (
+	"github.com/IBM/mathlib/driver/fp256bn/fp"
+)
+
+// parameters of the Shallue-van de Woestijne map of MIRACL
+var (
+	svdwZ      fp.Element // Z = 1
+	svdwSqrtM3 fp.Element // sqrt(-3)
+)
+
+func init() {
+	svdwZ.SetOne()
+	svdwSqrtM3.SetString("0xfffffffffffcf0cad3d42fddca5173d3d540b6bf2f71b0451cf11992678fc004")
+}
+
+func sign(x *fp.Element) uint {
+	b := x.Bytes()
+	return uint(b[fp.Bytes-1] & 1)
+}
+
+// rhs returns x^3 + b
+func rhs(x *fp.Element) *fp.Element {
+	var r fp.Element
+	r.Square(x).Mul(&r, x).Add(&r, new(fp.Element).SetUint64(curveB))
+	return &r
+}
+
+// svdw is the Shallue-van de Woestijne map of RFC 9380, Section 6.6.1,
+// computed as in MIRACL: the constant c3 is normalized to sign 0 and the
+// sign of y follows the one of u. G1 has cofactor 1.
+func svdw(u *fp.Element) g1Point {
+	var one, a, c, t, y, d, w, x1, x2, x3 fp.Element
+	one.SetOne()
+
+	a.Set(rhs(&svdwZ))
+	c.Mul(&svdwSqrtM3, &svdwZ)
+
+	// d = (1 + g(Z) * u^2) * (1 - g(Z) * u^2) * sqrt(-3) * Z
+	t.Square(u)
+	y.Mul(&a, &t)
+	t.Add(&one, &y)
+	y.Sub(&one, &y)
+	d.Mul(&t, &y).Mul(&d, &c)
+	d.Inverse(&d)
+
+	// w = c3 * sqrt(-3) * Z * u * (1 - g(Z) * u^2) / d
+	w.Sqrt(&a)
+	w.Mul(&w, &c)
+	if sign(&w) == 1 {
+		w.Neg(&w)
+	}
+	w.Mul(&w, &c).Mul(&w, u).Mul(&w, &y).Mul(&w, &d)
+
+	// x1 = -Z / 2 - w, x2 = -Z / 2 + w
+	x1.Neg(&svdwZ)
+	x1.Halve()
+	x2.Set(&x1)
+	x1.Sub(&x1, &w)
+	x2.Add(&x2, &w)
+
+	// x3 = Z + 4 * g(Z) * ((1 + g(Z) * u^2)^2 / d)^2
+	a.Double(&a).Double(&a)
+	t.Square(&t).Mul(&t, &d).Square(&t)
+	a.Mul(&a, &t)
+	x3.Add(&svdwZ, &a)
+
+	if rhs(&x2).Legendre() == 1 {
+		x3 = x2
+	}
+	if rhs(&x1).Legendre() == 1 {
+		x3 = x1
+	}
+	y.Sqrt(rhs(&x3))
+	if sign(&y) != sign(u) {
+		y.Neg(&y)
+	}
+
+	var p g1Point
+	p.x = x3
+	p.y = y
+	p.z.SetOne()
+	return p
+}
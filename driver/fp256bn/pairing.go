@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fp256bn
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver/fp256bn/fp"
+)
+
+// The optimal ate pairing follows MIRACL/AMCL step by step, since the
+// output of the Miller loop, before the final exponentiation, is exposed
+// through driver.Curve.Pairing and has to match it byte for byte.
+
+var (
+	// |u|, where u = -0x6882f5c030b0a801 is the BN parameter of the curve
+	bnx = new(big.Int).SetUint64(0x6882f5c030b0a801)
+
+	// n = 6|u| - 2 and 3n, whose difference drives the Miller loop
+	ateN, ateN3 *big.Int
+
+	// frob = (1 + i)^((p - 1) / 6) and its inverse
+	frob, frobInv e2
+)
+
+func init() {
+	ateN = new(big.Int).Mul(bnx, big.NewInt(6))
+	ateN.Sub(ateN, big.NewInt(2))
+	ateN3 = new(big.Int).Mul(ateN, big.NewInt(3))
+
+	frob.a.SetString("0x3d617662ca786f352d1a6e8ddb0867cf39a171511e3ab28f74760328af943106")
+	frob.b.SetString("0xc29e899d3584819819cb83d113693ccfd33af4a9f45d57f35eb32ab2ff3eff0d")
+	frobInv.inverse(&frob)
+}
+
+// lineDouble doubles a and returns the coefficients of the tangent line
+func lineDouble(a *g2Point) (aa, bb, cc e2) {
+	aa.mul(&a.y, &a.z)
+	cc.square(&a.x)
+	yy := new(e2).square(&a.y)
+	bb.square(&a.z)
+
+	aa.add(&aa, &aa).neg(&aa).mulByNonResidue(&aa)
+	bb.mul(&bb, &b3G2)
+	cc.mulByInt(&cc, 3)
+	bb.sub(&bb, yy)
+
+	a.double(a)
+	return
+}
+
+// lineAdd adds q to a and returns the coefficients of the line through them
+func lineAdd(a, q *g2Point) (aa, bb, cc e2) {
+	var t1 e2
+	t1.mul(&a.z, &q.y)
+	bb.mul(&a.z, &q.x)
+
+	aa.sub(&a.x, &bb)
+	cc.sub(&a.y, &t1)
+
+	t1.mul(&aa, &q.y)
+	aa.mulByNonResidue(&aa)
+
+	bb.mul(&cc, &q.x).sub(&bb, &t1)
+	cc.neg(&cc)
+
+	a.add(a, q)
+	return
+}
+
+// line evaluates at (px, py) the line through a and q, or the tangent at a
+// if q is nil, and moves a to a + q or 2a
+func line(a, q *g2Point, px, py *fp.Element) *e12 {
+	var aa, bb, cc e2
+	if q == nil {
+		aa, bb, cc = lineDouble(a)
+	} else {
+		aa, bb, cc = lineAdd(a, q)
+	}
+
+	cc.mulByFp(&cc, px)
+	aa.mulByFp(&aa, py)
+
+	l := &e12{}
+	l.a.a = aa
+	l.a.b = bb
+	l.c.b = cc
+	return l
+}
+
+// millerLoop returns the product of the Miller loops of the pairs (q[i], p[i])
+func millerLoop(q []*g2Point, p []*g1Point) *e12 {
+	type pair struct {
+		a, q, nq g2Point
+		px, py   fp.Element
+	}
+
+	var pairs []*pair
+	for i := range q {
+		if q[i].isInfinity() || p[i].isInfinity() {
+			continue
+		}
+
+		pp := &pair{q: *q[i], px: p[i].x, py: p[i].y}
+		pp.q.affine()
+		if !p[i].z.IsOne() {
+			var zInv fp.Element
+			zInv.Inverse(&p[i].z)
+			pp.px.Mul(&pp.px, &zInv)
+			pp.py.Mul(&pp.py, &zInv)
+		}
+		pp.a = pp.q
+		pp.nq.neg(&pp.q)
+		pairs = append(pairs, pp)
+	}
+
+	r := new(e12).setOne()
+	if len(pairs) == 0 {
+		return r
+	}
+
+	for i := ateN3.BitLen() - 2; i >= 1; i-- {
+		r.square(r)
+		for _, pp := range pairs {
+			lv := line(&pp.a, nil, &pp.px, &pp.py)
+			switch int(ateN3.Bit(i)) - int(ateN.Bit(i)) {
+			case 1:
+				lv.mul(lv, line(&pp.a, &pp.q, &pp.px, &pp.py))
+			case -1:
+				lv.mul(lv, line(&pp.a, &pp.nq, &pp.px, &pp.py))
+			}
+			r.mul(r, lv)
+		}
+	}
+
+	// u is negative
+	r.conj(r)
+
+	for _, pp := range pairs {
+		var k g2Point
+		pp.a.neg(&pp.a)
+		k.frobenius(&pp.q, &frobInv)
+		lv := line(&pp.a, &k, &pp.px, &pp.py)
+		k.frobenius(&k, &frobInv).neg(&k)
+		lv.mul(lv, line(&pp.a, &k, &pp.px, &pp.py))
+		r.mul(r, lv)
+	}
+
+	return r
+}
+
+// expByBnx sets z to x^|u|
+func (z *e12) expByBnx(x *e12) *e12 {
+	var res e12
+	res.setOne()
+	for i := bnx.BitLen() - 1; i >= 0; i-- {
+		res.square(&res)
+		if bnx.Bit(i) == 1 {
+			res.mul(&res, x)
+		}
+	}
+	*z = res
+	return z
+}
+
+// finalExp raises m to the power (p^12 - 1) / r
+func finalExp(m *e12) *e12 {
+	var r, lv, x0, x1, x2, x3, x4, x5 e12
+
+	// easy part
+	lv.inverse(m)
+	r.conj(m)
+	r.mul(&r, &lv)
+	lv = r
+	r.frobenius(&r, &frob).frobenius(&r, &frob)
+	r.mul(&r, &lv)
+
+	// hard part
+	lv.frobenius(&r, &frob)
+	x0.frobenius(&lv, &frob)
+	lv.mul(&lv, &r)
+	x0.mul(&x0, &lv)
+	x0.frobenius(&x0, &frob)
+	x1.conj(&r)
+	x4.expByBnx(&r)
+	x3.frobenius(&x4, &frob)
+	x2.expByBnx(&x4)
+	x5.conj(&x2)
+	lv.expByBnx(&x2)
+
+	x2.frobenius(&x2, &frob)
+	r.conj(&x2)
+	x4.mul(&x4, &r)
+	x2.frobenius(&x2, &frob)
+
+	r.frobenius(&lv, &frob)
+	lv.mul(&lv, &r)
+
+	lv.square(&lv)
+	lv.mul(&lv, &x4)
+	lv.mul(&lv, &x5)
+	r.mul(&x3, &x5)
+	r.mul(&r, &lv)
+	lv.mul(&lv, &x2)
+	r.square(&r)
+	r.mul(&r, &lv)
+	r.square(&r)
+	lv.mul(&r, &x1)
+	r.mul(&r, &x0)
+	lv.square(&lv)
+	r.mul(&r, &lv)
+
+	return &r
+}
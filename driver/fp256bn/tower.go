@@ -0,0 +1,478 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fp256bn
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver/fp256bn/fp"
+)
+
+// The extension tower is the one of MIRACL/AMCL, so that the serialization
+// of its elements is the same: Fp2 = Fp[i]/(i^2 + 1), Fp4 = Fp2[v]/(v^2 -
+// (1 + i)) and Fp12 = Fp4[w]/(w^3 - v). Each element is serialized with its
+// coefficients in decreasing degree order.
+
+/*********************************************************************/
+
+// e2 is a + b*i
+type e2 struct {
+	a, b fp.Element
+}
+
+func (z *e2) setOne() *e2 {
+	z.a.SetOne()
+	z.b.SetZero()
+	return z
+}
+
+func (z *e2) isZero() bool {
+	return z.a.IsZero() && z.b.IsZero()
+}
+
+func (z *e2) equal(x *e2) bool {
+	return z.a.Equal(&x.a) && z.b.Equal(&x.b)
+}
+
+func (z *e2) add(x, y *e2) *e2 {
+	z.a.Add(&x.a, &y.a)
+	z.b.Add(&x.b, &y.b)
+	return z
+}
+
+func (z *e2) sub(x, y *e2) *e2 {
+	z.a.Sub(&x.a, &y.a)
+	z.b.Sub(&x.b, &y.b)
+	return z
+}
+
+func (z *e2) neg(x *e2) *e2 {
+	z.a.Neg(&x.a)
+	z.b.Neg(&x.b)
+	return z
+}
+
+func (z *e2) conj(x *e2) *e2 {
+	z.a.Set(&x.a)
+	z.b.Neg(&x.b)
+	return z
+}
+
+func (z *e2) mul(x, y *e2) *e2 {
+	var t0, t1, t2, t3 fp.Element
+	t0.Mul(&x.a, &y.a)
+	t1.Mul(&x.b, &y.b)
+	t2.Add(&x.a, &x.b)
+	t3.Add(&y.a, &y.b)
+	t2.Mul(&t2, &t3).Sub(&t2, &t0).Sub(&t2, &t1)
+	z.a.Sub(&t0, &t1)
+	z.b.Set(&t2)
+	return z
+}
+
+func (z *e2) square(x *e2) *e2 {
+	return z.mul(x, x)
+}
+
+func (z *e2) mulByFp(x *e2, s *fp.Element) *e2 {
+	z.a.Mul(&x.a, s)
+	z.b.Mul(&x.b, s)
+	return z
+}
+
+func (z *e2) mulByInt(x *e2, c uint64) *e2 {
+	var s fp.Element
+	s.SetUint64(c)
+	return z.mulByFp(x, &s)
+}
+
+// mulByNonResidue multiplies x by 1 + i
+func (z *e2) mulByNonResidue(x *e2) *e2 {
+	var a fp.Element
+	a.Sub(&x.a, &x.b)
+	z.b.Add(&x.a, &x.b)
+	z.a.Set(&a)
+	return z
+}
+
+func (z *e2) norm() fp.Element {
+	var n, t fp.Element
+	n.Square(&z.a)
+	t.Square(&z.b)
+	return *n.Add(&n, &t)
+}
+
+func (z *e2) inverse(x *e2) *e2 {
+	n := x.norm()
+	n.Inverse(&n)
+	z.a.Mul(&x.a, &n)
+	z.b.Mul(&x.b, &n)
+	z.b.Neg(&z.b)
+	return z
+}
+
+// sign is the sgn0 function of RFC 9380
+func (z *e2) sign() uint {
+	a := z.a.Bytes()
+	if z.a.IsZero() {
+		b := z.b.Bytes()
+		return uint(b[fp.Bytes-1] & 1)
+	}
+	return uint(a[fp.Bytes-1] & 1)
+}
+
+// sqrt sets z to a square root of x and returns it, or returns nil if x is
+// not a square. p = 3 mod 4, so that x = a + b*i with b != 0 has the square
+// root sqrt(d) + b / (2 * sqrt(d)) * i with d = (a +/- sqrt(a^2 + b^2)) / 2.
+func (z *e2) sqrt(x *e2) *e2 {
+	var s e2
+
+	if x.b.IsZero() {
+		// either a or -a is a square in Fp
+		if s.a.Sqrt(&x.a) == nil {
+			s.b.Neg(&x.a)
+			s.b.Sqrt(&s.b)
+		}
+		*z = s
+		return z
+	}
+
+	n := x.norm()
+	if n.Sqrt(&n) == nil {
+		return nil
+	}
+
+	var d fp.Element
+	d.Add(&x.a, &n)
+	d.Halve()
+	if d.Legendre() != 1 {
+		d.Sub(&x.a, &n)
+		d.Halve()
+	}
+	if s.a.Sqrt(&d) == nil {
+		return nil
+	}
+	s.b.Double(&s.a)
+	s.b.Inverse(&s.b)
+	s.b.Mul(&s.b, &x.b)
+
+	var c e2
+	if !c.square(&s).equal(x) {
+		return nil
+	}
+
+	*z = s
+	return z
+}
+
+func (z *e2) bytes(b []byte) {
+	bb := z.b.Bytes()
+	ab := z.a.Bytes()
+	copy(b, bb[:])
+	copy(b[fp.Bytes:], ab[:])
+}
+
+func (z *e2) setBytes(b []byte) error {
+	if err := z.b.SetBytesCanonical(b[:fp.Bytes]); err != nil {
+		return err
+	}
+	return z.a.SetBytesCanonical(b[fp.Bytes : 2*fp.Bytes])
+}
+
+func (z *e2) String() string {
+	return "[" + hexString(&z.a) + "," + hexString(&z.b) + "]"
+}
+
+// hexString mimics the string representation of MIRACL field elements
+func hexString(x *fp.Element) string {
+	s := x.Text(16)
+	for len(s) < 2*fp.Bytes {
+		s = "0" + s
+	}
+	return s
+}
+
+/*********************************************************************/
+
+// e4 is a + b*v
+type e4 struct {
+	a, b e2
+}
+
+func (z *e4) isZero() bool {
+	return z.a.isZero() && z.b.isZero()
+}
+
+func (z *e4) equal(x *e4) bool {
+	return z.a.equal(&x.a) && z.b.equal(&x.b)
+}
+
+func (z *e4) add(x, y *e4) *e4 {
+	z.a.add(&x.a, &y.a)
+	z.b.add(&x.b, &y.b)
+	return z
+}
+
+func (z *e4) sub(x, y *e4) *e4 {
+	z.a.sub(&x.a, &y.a)
+	z.b.sub(&x.b, &y.b)
+	return z
+}
+
+func (z *e4) neg(x *e4) *e4 {
+	z.a.neg(&x.a)
+	z.b.neg(&x.b)
+	return z
+}
+
+// conj maps a + b*v to a - b*v
+func (z *e4) conj(x *e4) *e4 {
+	z.a = x.a
+	z.b.neg(&x.b)
+	return z
+}
+
+// nconj maps a + b*v to -a + b*v
+func (z *e4) nconj(x *e4) *e4 {
+	z.a.neg(&x.a)
+	z.b = x.b
+	return z
+}
+
+func (z *e4) mul(x, y *e4) *e4 {
+	var t0, t1, t2, t3 e2
+	t0.mul(&x.a, &y.a)
+	t1.mul(&x.b, &y.b)
+	t2.add(&x.a, &x.b)
+	t3.add(&y.a, &y.b)
+	t2.mul(&t2, &t3).sub(&t2, &t0).sub(&t2, &t1)
+	z.a.mulByNonResidue(&t1).add(&z.a, &t0)
+	z.b = t2
+	return z
+}
+
+func (z *e4) square(x *e4) *e4 {
+	return z.mul(x, x)
+}
+
+func (z *e4) mulByE2(x *e4, s *e2) *e4 {
+	z.a.mul(&x.a, s)
+	z.b.mul(&x.b, s)
+	return z
+}
+
+// mulByNonResidue multiplies x by v
+func (z *e4) mulByNonResidue(x *e4) *e4 {
+	var t e2
+	t.mulByNonResidue(&x.b)
+	z.b = x.a
+	z.a = t
+	return z
+}
+
+func (z *e4) inverse(x *e4) *e4 {
+	var t0, t1 e2
+	t0.square(&x.a)
+	t1.square(&x.b)
+	t1.mulByNonResidue(&t1)
+	t0.sub(&t0, &t1)
+	t0.inverse(&t0)
+	z.a.mul(&x.a, &t0)
+	t0.neg(&t0)
+	z.b.mul(&x.b, &t0)
+	return z
+}
+
+func (z *e4) frobenius(x *e4, f *e2) *e4 {
+	z.a.conj(&x.a)
+	z.b.conj(&x.b)
+	z.b.mul(&z.b, f)
+	return z
+}
+
+func (z *e4) bytes(b []byte) {
+	z.b.bytes(b)
+	z.a.bytes(b[2*fp.Bytes:])
+}
+
+func (z *e4) setBytes(b []byte) error {
+	if err := z.b.setBytes(b[:2*fp.Bytes]); err != nil {
+		return err
+	}
+	return z.a.setBytes(b[2*fp.Bytes : 4*fp.Bytes])
+}
+
+func (z *e4) String() string {
+	return "[" + z.a.String() + "," + z.b.String() + "]"
+}
+
+/*********************************************************************/
+
+// e12 is a + b*w + c*w^2
+type e12 struct {
+	a, b, c e4
+}
+
+func (z *e12) setOne() *e12 {
+	*z = e12{}
+	z.a.a.setOne()
+	return z
+}
+
+func (z *e12) isOne() bool {
+	var one e12
+	one.setOne()
+	return z.equal(&one)
+}
+
+func (z *e12) equal(x *e12) bool {
+	return z.a.equal(&x.a) && z.b.equal(&x.b) && z.c.equal(&x.c)
+}
+
+func (z *e12) mul(x, y *e12) *e12 {
+	var z0, z1, z2, z3, t0, t1 e4
+
+	z0.mul(&x.a, &y.a)
+	z2.mul(&x.b, &y.b)
+	z3.mul(&x.c, &y.c)
+
+	// coefficient of w
+	t0.add(&x.a, &x.b)
+	t1.add(&y.a, &y.b)
+	z1.mul(&t0, &t1).sub(&z1, &z0).sub(&z1, &z2)
+	t0.mulByNonResidue(&z3)
+	z1.add(&z1, &t0)
+
+	// coefficient of w^2
+	t0.add(&x.a, &x.c)
+	t1.add(&y.a, &y.c)
+	t0.mul(&t0, &t1).sub(&t0, &z0).sub(&t0, &z3)
+	t0.add(&t0, &z2)
+
+	// constant coefficient
+	t1.add(&x.b, &x.c)
+	var t2 e4
+	t2.add(&y.b, &y.c)
+	t1.mul(&t1, &t2).sub(&t1, &z2).sub(&t1, &z3)
+	t1.mulByNonResidue(&t1)
+	z.a.add(&z0, &t1)
+
+	z.b = z1
+	z.c = t0
+	return z
+}
+
+func (z *e12) square(x *e12) *e12 {
+	return z.mul(x, x)
+}
+
+// conj is the p^6-th power Frobenius map, i.e. the inverse of elements of
+// the cyclotomic subgroup
+func (z *e12) conj(x *e12) *e12 {
+	z.a.conj(&x.a)
+	z.b.nconj(&x.b)
+	z.c.conj(&x.c)
+	return z
+}
+
+func (z *e12) inverse(x *e12) *e12 {
+	var f0, f1, f2, f3 e4
+
+	f0.square(&x.a)
+	f1.mul(&x.b, &x.c).mulByNonResidue(&f1)
+	f0.sub(&f0, &f1)
+
+	f1.square(&x.c).mulByNonResidue(&f1)
+	f2.mul(&x.a, &x.b)
+	f1.sub(&f1, &f2)
+
+	f2.square(&x.b)
+	f3.mul(&x.a, &x.c)
+	f2.sub(&f2, &f3)
+
+	var t e4
+	f3.mul(&x.b, &f2).mulByNonResidue(&f3)
+	t.mul(&x.a, &f0)
+	f3.add(&f3, &t)
+	t.mul(&x.c, &f1).mulByNonResidue(&t)
+	f3.add(&f3, &t)
+	f3.inverse(&f3)
+
+	z.a.mul(&f0, &f3)
+	z.b.mul(&f1, &f3)
+	z.c.mul(&f2, &f3)
+	return z
+}
+
+// frobenius computes the p-th power of x, f being (1 + i)^((p - 1) / 6)
+func (z *e12) frobenius(x *e12, f *e2) *e12 {
+	var f2, f3 e2
+	f2.square(f)
+	f3.mul(f, &f2)
+
+	z.a.frobenius(&x.a, &f3)
+	z.b.frobenius(&x.b, &f3).mulByE2(&z.b, f)
+	z.c.frobenius(&x.c, &f3).mulByE2(&z.c, &f2)
+	return z
+}
+
+// exp sets z to x^k for 0 <= k < 2^256, in time independent of k
+func (z *e12) exp(x *e12, k *big.Int) *e12 {
+	var res, t e12
+	res.setOne()
+	base := *x
+
+	for i := 255; i >= 0; i-- {
+		res.square(&res)
+		t.mul(&res, &base)
+		res.selectIf(k.Bit(i), &t, &res)
+	}
+
+	*z = res
+	return z
+}
+
+// selectIf sets z to x if c is 1 and to y if c is 0
+func (z *e12) selectIf(c uint, x, y *e12) *e12 {
+	zs := z.coefficients()
+	xs := x.coefficients()
+	ys := y.coefficients()
+	for i := range zs {
+		zs[i].Select(int(c), ys[i], xs[i])
+	}
+	return z
+}
+
+func (z *e12) coefficients() []*fp.Element {
+	return []*fp.Element{
+		&z.a.a.a, &z.a.a.b, &z.a.b.a, &z.a.b.b,
+		&z.b.a.a, &z.b.a.b, &z.b.b.a, &z.b.b.b,
+		&z.c.a.a, &z.c.a.b, &z.c.b.a, &z.c.b.b,
+	}
+}
+
+func (z *e12) bytes() []byte {
+	b := make([]byte, 12*fp.Bytes)
+	z.c.bytes(b)
+	z.b.bytes(b[4*fp.Bytes:])
+	z.a.bytes(b[8*fp.Bytes:])
+	return b
+}
+
+func (z *e12) setBytes(b []byte) error {
+	if err := z.c.setBytes(b[:4*fp.Bytes]); err != nil {
+		return err
+	}
+	if err := z.b.setBytes(b[4*fp.Bytes : 8*fp.Bytes]); err != nil {
+		return err
+	}
+	return z.a.setBytes(b[8*fp.Bytes : 12*fp.Bytes])
+}
+
+func (z *e12) String() string {
+	return "[" + z.a.String() + "," + z.b.String() + "," + z.c.String() + "]"
+}
@@ -7,12 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package gurvy
 
 import (
+	"bytes"
 	"fmt"
-	"strings"
+	"math/big"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/common"
 	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 )
 
@@ -67,11 +69,23 @@ func (g *bls12377G1) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12377G1) PutBytes(dst []byte) int {
+	raw := g.G1Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12377G1) Compressed() []byte {
 	raw := g.G1Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls12377G1) PutCompressed(dst []byte) int {
+	raw := g.G1Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12377G1) Sub(a driver.G1) {
 	j, k := bls12377.G1Jac{}, bls12377.G1Jac{}
 	j.FromAffine(&g.G1Affine)
@@ -84,10 +98,12 @@ func (g *bls12377G1) IsInfinity() bool {
 	return g.G1Affine.IsInfinity()
 }
 
+func (g *bls12377G1) IsGenerator() bool {
+	return g.G1Affine.Bytes() == g1Bytes12_377
+}
+
 func (g *bls12377G1) String() string {
-	rawstr := g.G1Affine.String()
-	m := g1StrRegexp.FindAllStringSubmatch(rawstr, -1)
-	return "(" + strings.TrimLeft(m[0][1], "0") + "," + strings.TrimLeft(m[0][2], "0") + ")"
+	return "(" + g.X.String() + "," + g.Y.String() + ")"
 }
 
 func (g *bls12377G1) Neg() {
@@ -146,11 +162,23 @@ func (g *bls12377G2) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12377G2) PutBytes(dst []byte) int {
+	raw := g.G2Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12377G2) Compressed() []byte {
 	raw := g.G2Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls12377G2) PutCompressed(dst []byte) int {
+	raw := g.G2Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12377G2) String() string {
 	return g.G2Affine.String()
 }
@@ -159,6 +187,10 @@ func (g *bls12377G2) Equals(a driver.G2) bool {
 	return g.G2Affine.Equal(&a.(*bls12377G2).G2Affine)
 }
 
+func (g *bls12377G2) IsGenerator() bool {
+	return g.G2Affine.Bytes() == g2Bytes12_377
+}
+
 /*********************************************************************/
 
 type bls12377Gt struct {
@@ -178,6 +210,10 @@ func (g *bls12377Gt) Inverse() {
 	g.GT.Inverse(&g.GT)
 }
 
+func (g *bls12377Gt) Conjugate() {
+	g.GT.Conjugate(&g.GT)
+}
+
 func (g *bls12377Gt) Mul(a driver.Gt) {
 	g.GT.Mul(&g.GT, &a.(*bls12377Gt).GT)
 }
@@ -198,14 +234,131 @@ func (g *bls12377Gt) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12377Gt) PutBytes(dst []byte) int {
+	raw := g.GT.Bytes()
+	return copy(dst, raw[:])
+}
+
+/*********************************************************************/
+
+type bls12377Fp struct {
+	fp.Element
+}
+
+func (e *bls12377Fp) Add(a driver.Fp) driver.Fp {
+	rv := &bls12377Fp{}
+	rv.Element.Add(&e.Element, &a.(*bls12377Fp).Element)
+	return rv
+}
+
+func (e *bls12377Fp) Sub(a driver.Fp) driver.Fp {
+	rv := &bls12377Fp{}
+	rv.Element.Sub(&e.Element, &a.(*bls12377Fp).Element)
+	return rv
+}
+
+func (e *bls12377Fp) Mul(a driver.Fp) driver.Fp {
+	rv := &bls12377Fp{}
+	rv.Element.Mul(&e.Element, &a.(*bls12377Fp).Element)
+	return rv
+}
+
+func (e *bls12377Fp) Neg() driver.Fp {
+	rv := &bls12377Fp{}
+	rv.Element.Neg(&e.Element)
+	return rv
+}
+
+func (e *bls12377Fp) Inverse() driver.Fp {
+	rv := &bls12377Fp{}
+	rv.Element.Inverse(&e.Element)
+	return rv
+}
+
+func (e *bls12377Fp) Sqrt() (driver.Fp, bool) {
+	rv := &bls12377Fp{}
+	if rv.Element.Sqrt(&e.Element) == nil {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func (e *bls12377Fp) Equals(a driver.Fp) bool {
+	return e.Element.Equal(&a.(*bls12377Fp).Element)
+}
+
+func (e *bls12377Fp) Bytes() []byte {
+	raw := e.Element.Bytes()
+	return raw[:]
+}
+
+func (e *bls12377Fp) String() string {
+	return e.Element.Text(16)
+}
+
 /*********************************************************************/
 
 func NewBls12_377() *Bls12_377 {
-	return &Bls12_377{common.CurveBase{Modulus: *fr.Modulus()}}
+	c := &Bls12_377{CurveBase: common.CurveBase{Modulus: *fr.Modulus()}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*bls12377Gt).GT
+	return c
 }
 
 type Bls12_377 struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bls12377.GT
+}
+
+func (c *Bls12_377) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		EmbeddingDegree:    12,
+		G1Cofactor:         common.HexInt("170b5d44300000000000000000000000"),
+		G2Cofactor:         common.HexInt("26ba558ae9562addd88d99a6f6a829fbb36b00e1dcc40c8c505634fae2e189d693e8c36676bd09a0f3622fba094800452217cc900000000000000000000001"),
+		BaseFieldModulus:   fp.Modulus(),
+		ScalarFieldModulus: fr.Modulus(),
+	}
+}
+
+func (c *Bls12_377) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModAddMul(a1, b1, m)
+	}
+
+	var sum, a, b fr.Element
+	for i := range a1 {
+		a.SetBigInt(&a1[i].(*common.BaseZr).Int)
+		b.SetBigInt(&b1[i].(*common.BaseZr).Int)
+		sum.Add(&sum, a.Mul(&a, &b))
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	sum.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bls12_377) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModMul3(a1, b1, c1, m)
+	}
+
+	var a, b, e fr.Element
+	a.SetBigInt(&a1.(*common.BaseZr).Int)
+	b.SetBigInt(&b1.(*common.BaseZr).Int)
+	e.SetBigInt(&c1.(*common.BaseZr).Int)
+	a.Mul(&a, &b).Mul(&a, &e)
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	a.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bls12_377) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
 }
 
 func (c *Bls12_377) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
@@ -230,6 +383,26 @@ func (c *Bls12_377) FExp(a driver.Gt) driver.Gt {
 	return &bls12377Gt{bls12377.FinalExponentiation(&a.(*bls12377Gt).GT)}
 }
 
+func (c *Bls12_377) MultiPairing(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	return c.FExp(c.MillerLoop(p2, p1))
+}
+
+func (c *Bls12_377) MillerLoop(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	ps := make([]bls12377.G1Affine, len(p1))
+	qs := make([]bls12377.G2Affine, len(p2))
+	for i := range p1 {
+		ps[i] = p1[i].(*bls12377G1).G1Affine
+		qs[i] = p2[i].(*bls12377G2).G2Affine
+	}
+
+	t, err := bls12377.MillerLoop(ps, qs)
+	if err != nil {
+		panic(fmt.Sprintf("miller loop failed [%s]", err.Error()))
+	}
+
+	return &bls12377Gt{t}
+}
+
 var g1Bytes12_377 [48]byte
 var g2Bytes12_377 [96]byte
 
@@ -260,11 +433,7 @@ func (c *Bls12_377) GenG2() driver.G2 {
 }
 
 func (c *Bls12_377) GenGt() driver.Gt {
-	g1 := c.GenG1()
-	g2 := c.GenG2()
-	gengt := c.Pairing(g2, g1)
-	gengt = c.FExp(gengt)
-	return gengt
+	return &bls12377Gt{c.genGt}
 }
 
 func (c *Bls12_377) CoordinateByteSize() int {
@@ -287,6 +456,10 @@ func (c *Bls12_377) CompressedG2ByteSize() int {
 	return bls12377.SizeOfG2AffineCompressed
 }
 
+func (c *Bls12_377) GtByteSize() int {
+	return bls12377.SizeOfGT
+}
+
 func (c *Bls12_377) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -299,54 +472,237 @@ func (c *Bls12_377) NewG2() driver.G2 {
 	return &bls12377G2{}
 }
 
-func (c *Bls12_377) NewG1FromBytes(b []byte) driver.G1 {
+func (c *Bls12_377) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	frs := make([]fr.Element, len(scalars))
+	for i, s := range scalars {
+		frs[i].SetBigInt(&s.(*common.BaseZr).Int)
+	}
+
+	points := bls12377.BatchScalarMultiplicationG1(&base.(*bls12377G1).G1Affine, frs)
+
+	res := make([]driver.G1, len(points))
+	for i := range points {
+		res[i] = &bls12377G1{points[i]}
+	}
+
+	return res
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]points[i], computed with
+// gnark's multi-exponentiation.
+func (c *Bls12_377) MultiScalarMul(points []driver.G1, scalars []driver.Zr, tasks int) driver.G1 {
+	ps := make([]bls12377.G1Affine, len(points))
+	frs := make([]fr.Element, len(scalars))
+	for i := range points {
+		ps[i] = points[i].(*bls12377G1).G1Affine
+		frs[i].SetBigInt(&scalars[i].(*common.BaseZr).Int)
+	}
+
+	res := &bls12377G1{}
+	if _, err := res.G1Affine.MultiExp(ps, frs, multiExpConfig(tasks)); err != nil {
+		panic(fmt.Sprintf("MultiScalarMul failed [%s]", err.Error()))
+	}
+
+	return res
+}
+
+func (c *Bls12_377) ZrMontgomeryBytes(a driver.Zr) []byte {
+	var e fr.Element
+	e.SetBigInt(&a.(*common.BaseZr).Int)
+	return montgomeryBytes(e[:])
+}
+
+func (c *Bls12_377) NewZrFromMontgomery(b []byte) (driver.Zr, error) {
+	var e fr.Element
+	if err := setMontgomery(e[:], b, &c.Modulus); err != nil {
+		return nil, err
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	e.BigInt(&res.Int)
+	return res, nil
+}
+
+func (c *Bls12_377) SumG1(points []driver.G1) driver.G1 {
+	var acc bls12377.G1Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls12377G1).G1Affine)
+	}
+
+	res := &bls12377G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls12_377) MulAddG1(acc driver.G1, s driver.Zr, P driver.G1) {
+	a := acc.(*bls12377G1)
+
+	var j bls12377.G1Jac
+	j.ScalarMultiplicationAffine(&P.(*bls12377G1).G1Affine, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G1Affine)
+	a.G1Affine.FromJacobian(&j)
+}
+
+func (c *Bls12_377) MulAddG2(acc driver.G2, s driver.Zr, P driver.G2) {
+	a := acc.(*bls12377G2)
+
+	var j bls12377.G2Jac
+	j.FromAffine(&P.(*bls12377G2).G2Affine)
+	j.ScalarMultiplication(&j, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G2Affine)
+	a.G2Affine.FromJacobian(&j)
+}
+
+func (c *Bls12_377) InfinityG1() driver.G1 {
+	return &bls12377G1{}
+}
+
+func (c *Bls12_377) InfinityG2() driver.G2 {
+	return &bls12377G2{}
+}
+
+func (c *Bls12_377) SumG2(points []driver.G2) driver.G2 {
+	var acc bls12377.G2Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls12377G2).G2Affine)
+	}
+
+	res := &bls12377G2{}
+	res.G2Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls12_377) NewG1FromBytes(b []byte) (driver.G1, error) {
 	v := &bls12377G1{}
 	_, err := v.G1Affine.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_377) NewG2FromBytes(b []byte) driver.G2 {
+func (c *Bls12_377) NewG2FromBytes(b []byte) (driver.G2, error) {
 	v := &bls12377G2{}
 	_, err := v.G2Affine.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+// NewG1FromBytesUnchecked decodes b with neither the on-curve nor the
+// subgroup check, which the caller must perform if b is not trusted.
+func (c *Bls12_377) NewG1FromBytesUnchecked(b []byte) (driver.G1, error) {
+	v := &bls12377G1{}
+	if err := bls12377.NewDecoder(bytes.NewReader(b), bls12377.NoSubgroupChecks()).Decode(&v.G1Affine); err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls12_377) NewG2FromBytesUnchecked(b []byte) (driver.G2, error) {
+	v := &bls12377G2{}
+	if err := bls12377.NewDecoder(bytes.NewReader(b), bls12377.NoSubgroupChecks()).Decode(&v.G2Affine); err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_377) NewG1FromCompressed(b []byte) driver.G1 {
+func (c *Bls12_377) NewG1FromCompressed(b []byte) (driver.G1, error) {
 	v := &bls12377G1{}
 	_, err := v.G1Affine.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_377) NewG2FromCompressed(b []byte) driver.G2 {
+func (c *Bls12_377) NewG2FromCompressed(b []byte) (driver.G2, error) {
 	v := &bls12377G2{}
 	_, err := v.G2Affine.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_377) NewGtFromBytes(b []byte) driver.Gt {
+func (c *Bls12_377) NewGtFromBytes(b []byte) (driver.Gt, error) {
 	v := &bls12377Gt{}
 	err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return v, nil
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fp; the point at
+// infinity is (0, 0) like any other pair of coordinates.
+func (c *Bls12_377) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*bls12377G1).G1Affine, &b.(*bls12377G1).G1Affine
+	rv := &bls12377G1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+// SelectG2 is SelectG1 for G2, whose coordinates are selected component
+// by component.
+func (c *Bls12_377) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	p, q := &a.(*bls12377G2).G2Affine, &b.(*bls12377G2).G2Affine
+	rv := &bls12377G2{}
+	rv.X.A0.Select(cond, &q.X.A0, &p.X.A0)
+	rv.X.A1.Select(cond, &q.X.A1, &p.X.A1)
+	rv.Y.A0.Select(cond, &q.Y.A0, &p.Y.A0)
+	rv.Y.A1.Select(cond, &q.Y.A1, &p.Y.A1)
+	return rv
+}
+
+// CompressedGt returns the torus-based compression of a, half the size of
+// Bytes, with the identity encoded as zeros. It fails unless a is in the
+// cyclotomic subgroup, as the outputs of FExp are.
+func (c *Bls12_377) CompressedGt(a driver.Gt) ([]byte, error) {
+	gt := &a.(*bls12377Gt).GT
+
+	var y bls12377.GT
+	if !gt.IsOne() {
+		var norm bls12377.GT
+		if !norm.Conjugate(gt).Mul(&norm, gt).IsOne() {
+			return nil, errNotCyclotomic
+		}
+
+		var err error
+		if y.C0, err = gt.CompressTorus(); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := y.Bytes()
+	return raw[bls12377.SizeOfGT/2:], nil
+}
+
+func (c *Bls12_377) NewGtFromCompressed(b []byte) (driver.Gt, error) {
+	if len(b) != bls12377.SizeOfGT/2 {
+		return nil, fmt.Errorf("invalid compressed Gt length %d", len(b))
 	}
 
-	return v
+	var y bls12377.GT
+	if err := y.SetBytes(append(make([]byte, bls12377.SizeOfGT/2), b...)); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	if y.C0.IsZero() {
+		return &bls12377Gt{*y.SetOne()}, nil
+	}
+
+	return &bls12377Gt{y.C0.DecompressTorus()}, nil
 }
 
 func (c *Bls12_377) HashToG1(data []byte) driver.G1 {
@@ -376,6 +732,72 @@ func (p *Bls12_377) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	return &bls12377G1{g1}
 }
 
+func (p *Bls12_377) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	u, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = &common.BaseZr{Int: *u[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return p.MapToG1(els), els
+}
+
+func (p *Bls12_377) MapToG1(u []driver.Zr) driver.G1 {
+	var acc bls12377.G1Jac
+	for _, e := range u {
+		var el fp.Element
+		el.SetBigInt(&e.(*common.BaseZr).Int)
+		q := bls12377.MapToG1(el)
+		acc.AddMixed(&q)
+	}
+
+	res := &bls12377G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+// MapFieldToG1 maps the base field element b to G1 with MapToG1, the
+// map_to_curve of RFC 9380 followed by clear_cofactor.
+func (p *Bls12_377) MapFieldToG1(b []byte) (driver.G1, error) {
+	var u fp.Element
+	if err := u.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return &bls12377G1{bls12377.MapToG1(u)}, nil
+}
+
+// MapFieldToG2 is MapFieldToG1 for G2, whose field elements are made of 2
+// components of the base field.
+func (p *Bls12_377) MapFieldToG2(b []byte) (driver.G2, error) {
+	if len(b) != 2*fp.Bytes {
+		return nil, fmt.Errorf("invalid field element length [%d]", len(b))
+	}
+
+	// x = A0 + A1*u, laid out from A1 to A0
+	var q bls12377.G2Affine
+	for i, a := range []*fp.Element{&q.X.A1, &q.X.A0} {
+		if err := a.SetBytesCanonical(b[i*fp.Bytes : (i+1)*fp.Bytes]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bls12377G2{bls12377.MapToG2(q.X)}, nil
+}
+
+func (p *Bls12_377) NewFpFromBytes(b []byte) (driver.Fp, error) {
+	e := &bls12377Fp{}
+	if err := e.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 func (p *Bls12_377) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2, err := bls12377.HashToG2(data, domain)
 	if err != nil {
@@ -0,0 +1,421 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gurvy
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var frModulus12_381 = fr.Modulus()
+
+// bls12381Zr is a scalar of BLS12-381. Values in [0, r) are held as an
+// fr.Element, in Montgomery form, so that the arithmetic modulo r goes
+// without big.Int. The Zr interface also carries values outside that
+// range: the results of Plus, Minus and Neg before any reduction, the group
+// order itself and residues modulo other moduli. Those are held in wide
+// and handled with big.Int, as common.BaseZr does.
+type bls12381Zr struct {
+	fr.Element
+	wide *big.Int
+}
+
+func newBls12381Zr(v *big.Int) *bls12381Zr {
+	z := &bls12381Zr{}
+	z.set(v)
+	return z
+}
+
+// set sets z to v, keeping it in wide if it is outside [0, r).
+func (z *bls12381Zr) set(v *big.Int) {
+	if v.Sign() >= 0 && v.Cmp(frModulus12_381) < 0 {
+		z.Element.SetBigInt(v)
+		z.wide = nil
+		return
+	}
+
+	z.wide = new(big.Int).Set(v)
+}
+
+// value sets dst to the value of z and returns it.
+func (z *bls12381Zr) value(dst *big.Int) *big.Int {
+	if z.wide != nil {
+		return dst.Set(z.wide)
+	}
+
+	return z.Element.BigInt(dst)
+}
+
+// reduced returns z modulo r, in dst unless z is held as an fr.Element.
+func (z *bls12381Zr) reduced(dst *fr.Element) *fr.Element {
+	if z.wide == nil {
+		return &z.Element
+	}
+
+	return dst.SetBigInt(z.wide)
+}
+
+// isOrder reports whether z is the group order, i.e. the modulus of the
+// Mod* operations that they can run on fr elements.
+func (z *bls12381Zr) isOrder() bool {
+	return z.wide != nil && z.wide.Cmp(frModulus12_381) == 0
+}
+
+func (z *bls12381Zr) Plus(a driver.Zr) driver.Zr {
+	b := a.(*bls12381Zr)
+	if z.wide == nil && b.wide == nil {
+		rv := &bls12381Zr{}
+		rv.Element.Add(&z.Element, &b.Element)
+		if rv.Element.Cmp(&z.Element) >= 0 {
+			return rv
+		}
+	}
+
+	// the sum is not reduced
+	v := z.value(new(big.Int))
+	return newBls12381Zr(v.Add(v, b.value(new(big.Int))))
+}
+
+func (z *bls12381Zr) Minus(a driver.Zr) driver.Zr {
+	b := a.(*bls12381Zr)
+	if z.wide == nil && b.wide == nil && z.Element.Cmp(&b.Element) >= 0 {
+		rv := &bls12381Zr{}
+		rv.Element.Sub(&z.Element, &b.Element)
+		return rv
+	}
+
+	// the difference is not reduced
+	v := z.value(new(big.Int))
+	return newBls12381Zr(v.Sub(v, b.value(new(big.Int))))
+}
+
+func (z *bls12381Zr) Mul(a driver.Zr) driver.Zr {
+	var x, y fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Mul(z.reduced(&x), a.(*bls12381Zr).reduced(&y))
+	return rv
+}
+
+// AddInPlace sets z to z + a modulo r.
+func (z *bls12381Zr) AddInPlace(a driver.Zr) {
+	var x, y fr.Element
+	z.Element.Add(z.reduced(&x), a.(*bls12381Zr).reduced(&y))
+	z.wide = nil
+}
+
+// SubInPlace sets z to z - a modulo r.
+func (z *bls12381Zr) SubInPlace(a driver.Zr) {
+	var x, y fr.Element
+	z.Element.Sub(z.reduced(&x), a.(*bls12381Zr).reduced(&y))
+	z.wide = nil
+}
+
+// MulInPlace sets z to z * a modulo r.
+func (z *bls12381Zr) MulInPlace(a driver.Zr) {
+	var x, y fr.Element
+	z.Element.Mul(z.reduced(&x), a.(*bls12381Zr).reduced(&y))
+	z.wide = nil
+}
+
+func (z *bls12381Zr) Mod(a driver.Zr) {
+	m := a.(*bls12381Zr)
+	if m.isOrder() {
+		var x fr.Element
+		z.Element.Set(z.reduced(&x))
+		z.wide = nil
+		return
+	}
+
+	v := z.value(new(big.Int))
+	z.set(v.Mod(v, m.value(new(big.Int))))
+}
+
+// PowMod returns z^x modulo r; a negative x raises the inverse of z, and a
+// z with no inverse yields zero.
+func (z *bls12381Zr) PowMod(x driver.Zr) driver.Zr {
+	var b fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Exp(*z.reduced(&b), x.(*bls12381Zr).value(new(big.Int)))
+	return rv
+}
+
+func (z *bls12381Zr) InvModP(p driver.Zr) {
+	m := p.(*bls12381Zr)
+	if z.wide == nil && m.isOrder() {
+		// like big.Int.ModInverse, fr leaves zero as it is
+		z.Element.Inverse(&z.Element)
+		return
+	}
+
+	v := z.value(new(big.Int))
+	if v.ModInverse(v, m.value(new(big.Int))) != nil {
+		z.set(v)
+	}
+}
+
+func (z *bls12381Zr) Bytes() []byte {
+	if z.wide != nil {
+		return common.BigToBytes(common.Normalize(z.wide, frModulus12_381))
+	}
+
+	raw := z.Element.Bytes()
+	return raw[:]
+}
+
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (z *bls12381Zr) PutBytes(dst []byte) int {
+	if z.wide != nil {
+		return copy(dst, z.Bytes())
+	}
+
+	raw := z.Element.Bytes()
+	return copy(dst, raw[:])
+}
+
+func (z *bls12381Zr) Equals(a driver.Zr) bool {
+	b := a.(*bls12381Zr)
+	if z.wide == nil && b.wide == nil {
+		return z.Element.Equal(&b.Element)
+	}
+
+	return common.Normalize(z.value(new(big.Int)), frModulus12_381).Cmp(common.Normalize(b.value(new(big.Int)), frModulus12_381)) == 0
+}
+
+func (z *bls12381Zr) Copy() driver.Zr {
+	rv := &bls12381Zr{}
+	rv.Clone(z)
+	return rv
+}
+
+func (z *bls12381Zr) Clone(a driver.Zr) {
+	b := a.(*bls12381Zr)
+	z.Element.Set(&b.Element)
+	z.wide = nil
+	if b.wide != nil {
+		z.wide = new(big.Int).Set(b.wide)
+	}
+}
+
+func (z *bls12381Zr) String() string {
+	if z.wide != nil {
+		return common.Normalize(z.wide, frModulus12_381).Text(16)
+	}
+
+	return z.Element.Text(16)
+}
+
+// Neg negates z without reducing it, as common.BaseZr does.
+func (z *bls12381Zr) Neg() {
+	if z.wide == nil && z.Element.IsZero() {
+		return
+	}
+
+	v := z.value(new(big.Int))
+	z.set(v.Neg(v))
+}
+
+func (z *bls12381Zr) Halve() driver.Zr {
+	var x fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Set(z.reduced(&x))
+	rv.Element.Halve()
+	return rv
+}
+
+func (z *bls12381Zr) IsNegative() bool {
+	return z.wide != nil && z.wide.Sign() < 0
+}
+
+func (z *bls12381Zr) Signed() *big.Int {
+	var x fr.Element
+	rv := z.reduced(&x).BigInt(new(big.Int))
+	if rv.Cmp(new(big.Int).Rsh(frModulus12_381, 1)) > 0 {
+		rv.Sub(rv, frModulus12_381)
+	}
+	return rv
+}
+
+func (z *bls12381Zr) CmpAbs(a driver.Zr) int {
+	return z.Signed().CmpAbs(a.(*bls12381Zr).Signed())
+}
+
+/*********************************************************************/
+
+func (c *Bls12_381) GroupOrder() driver.Zr {
+	return newBls12381Zr(&c.Modulus)
+}
+
+func (c *Bls12_381) NewZrFromBytes(b []byte) driver.Zr {
+	rv := &bls12381Zr{}
+	if len(b) == fr.Bytes {
+		if v, err := fr.BigEndian.Element((*[fr.Bytes]byte)(b)); err == nil {
+			rv.Element = v
+			return rv
+		}
+	}
+
+	rv.set(new(big.Int).SetBytes(b))
+	return rv
+}
+
+func (c *Bls12_381) NewZrFromInt64(i int64) driver.Zr {
+	if i < 0 {
+		return newBls12381Zr(big.NewInt(i))
+	}
+
+	rv := &bls12381Zr{}
+	rv.Element.SetUint64(uint64(i))
+	return rv
+}
+
+func (c *Bls12_381) NewZrFromUint64(i uint64) driver.Zr {
+	rv := &bls12381Zr{}
+	rv.Element.SetUint64(i)
+	return rv
+}
+
+func (c *Bls12_381) NewRandomZr(rng io.Reader) driver.Zr {
+	bi, err := rand.Int(rng, &c.Modulus)
+	if err != nil {
+		panic(err)
+	}
+
+	rv := &bls12381Zr{}
+	rv.Element.SetBigInt(bi)
+	return rv
+}
+
+func (c *Bls12_381) HashToZr(data []byte) driver.Zr {
+	digest := sha256.Sum256(data)
+
+	rv := &bls12381Zr{}
+	rv.Element.SetBytes(digest[:])
+	return rv
+}
+
+// The modular operations run on fr elements when m is the group order and
+// on big.Int otherwise.
+
+// modBig returns v modulo m.
+func modBig12_381(v *big.Int, m driver.Zr) driver.Zr {
+	return newBls12381Zr(v.Mod(v, m.(*bls12381Zr).value(new(big.Int))))
+}
+
+func (c *Bls12_381) ModNeg(a1, m driver.Zr) driver.Zr {
+	a := a1.(*bls12381Zr)
+	if !m.(*bls12381Zr).isOrder() {
+		v := m.(*bls12381Zr).value(new(big.Int))
+		return modBig12_381(v.Sub(v, a.value(new(big.Int))), m)
+	}
+
+	var x fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Neg(a.reduced(&x))
+	return rv
+}
+
+func (c *Bls12_381) ModAdd(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*bls12381Zr), b1.(*bls12381Zr)
+	if !m.(*bls12381Zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig12_381(v.Add(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Add(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+func (c *Bls12_381) ModSub(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*bls12381Zr), b1.(*bls12381Zr)
+	if !m.(*bls12381Zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig12_381(v.Sub(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Sub(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+// ModAdd2 sets a1 to a1 + b1 + c1 modulo m
+func (c *Bls12_381) ModAdd2(a1, b1, c1, m driver.Zr) {
+	a := a1.(*bls12381Zr)
+	if !m.(*bls12381Zr).isOrder() {
+		v := a.value(new(big.Int))
+		v.Add(v, b1.(*bls12381Zr).value(new(big.Int)))
+		v.Add(v, c1.(*bls12381Zr).value(new(big.Int)))
+		a.Clone(modBig12_381(v, m))
+		return
+	}
+
+	var x, y, z fr.Element
+	a.Element.Add(a.reduced(&x), b1.(*bls12381Zr).reduced(&y))
+	a.Element.Add(&a.Element, c1.(*bls12381Zr).reduced(&z))
+	a.wide = nil
+}
+
+func (c *Bls12_381) ModMul(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*bls12381Zr), b1.(*bls12381Zr)
+	if !m.(*bls12381Zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig12_381(v.Mul(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Mul(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+// ModMul3 returns a1 * b1 * c1 modulo m.
+func (c *Bls12_381) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if !m.(*bls12381Zr).isOrder() {
+		v := a1.(*bls12381Zr).value(new(big.Int))
+		v.Mul(v, b1.(*bls12381Zr).value(new(big.Int)))
+		return modBig12_381(v.Mul(v, c1.(*bls12381Zr).value(new(big.Int))), m)
+	}
+
+	var x, y, z fr.Element
+	rv := &bls12381Zr{}
+	rv.Element.Mul(a1.(*bls12381Zr).reduced(&x), b1.(*bls12381Zr).reduced(&y))
+	rv.Element.Mul(&rv.Element, c1.(*bls12381Zr).reduced(&z))
+	return rv
+}
+
+// ModAddMul returns the sum of a1[i] * b1[i] modulo m
+func (c *Bls12_381) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if !m.(*bls12381Zr).isOrder() {
+		sum, v := new(big.Int), new(big.Int)
+		for i := range a1 {
+			a1[i].(*bls12381Zr).value(v)
+			sum.Add(sum, v.Mul(v, b1[i].(*bls12381Zr).value(new(big.Int))))
+		}
+		return modBig12_381(sum, m)
+	}
+
+	var x, y, prod fr.Element
+	rv := &bls12381Zr{}
+	for i := range a1 {
+		prod.Mul(a1[i].(*bls12381Zr).reduced(&x), b1[i].(*bls12381Zr).reduced(&y))
+		rv.Element.Add(&rv.Element, &prod)
+	}
+	return rv
+}
+
+// ModAddMul2 returns a1 * c1 + b1 * c2 modulo m
+func (c *Bls12_381) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
+}
@@ -7,13 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package gurvy
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"hash"
-	"strings"
+	"math/big"
+	"math/bits"
+	"sync"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/common"
+	"github.com/consensys/gnark-crypto/ecc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	"golang.org/x/crypto/blake2b"
 )
@@ -47,17 +53,67 @@ func (g *bls12381G1) Add(a driver.G1) {
 
 func (g *bls12381G1) Mul(a driver.Zr) driver.G1 {
 	gc := &bls12381G1{}
-	gc.G1Affine.ScalarMultiplication(&g.G1Affine, &a.(*common.BaseZr).Int)
+	gc.G1Affine.ScalarMultiplication(&g.G1Affine, a.(*bls12381Zr).value(new(big.Int)))
 
 	return gc
 }
 
+// Mul2 computes [e]g + [f]Q with Shamir's trick on top of the GLV
+// decomposition that gnark uses for Mul: e and f are split into halves
+// acting on g, phi(g), Q and phi(Q), so that the four multiplications share
+// about 128 doublings and add one of 15 precomputed sums per bit. It stays
+// in Jacobian coordinates up to the result; Q may equal g.
 func (g *bls12381G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
-	a := g.Mul(e)
-	b := Q.Mul(f)
-	a.Add(b)
+	var x, y fr.Element
+	k1 := ecc.SplitScalar(e.(*bls12381Zr).reduced(&x).BigInt(new(big.Int)), &glvBasis12_381)
+	k2 := ecc.SplitScalar(f.(*bls12381Zr).reduced(&y).BigInt(new(big.Int)), &glvBasis12_381)
+	ks := [4]*big.Int{&k1[0], &k1[1], &k2[0], &k2[1]}
+
+	var bases [4]bls12381.G1Jac
+	bases[0].FromAffine(&g.G1Affine)
+	bases[2].FromAffine(&Q.(*bls12381G1).G1Affine)
+	for i := 0; i < 4; i += 2 {
+		bases[i+1].Set(&bases[i])
+		bases[i+1].X.Mul(&bases[i+1].X, &thirdRootOneG1_12_381)
+	}
+
+	n := 0
+	for i, k := range ks {
+		if k.Sign() < 0 {
+			k.Neg(k)
+			bases[i].Neg(&bases[i])
+		}
+		if k.BitLen() > n {
+			n = k.BitLen()
+		}
+	}
 
-	return a
+	// table[m-1] is the sum of the bases selected by the bits of m
+	var table [15]bls12381.G1Jac
+	for m := 1; m < 16; m++ {
+		low := m & -m
+		if m == low {
+			table[m-1].Set(&bases[bits.TrailingZeros(uint(m))])
+			continue
+		}
+		table[m-1].Set(&table[m-low-1])
+		table[m-1].AddAssign(&table[low-1])
+	}
+	affine := bls12381.BatchJacobianToAffineG1(table[:])
+
+	var acc bls12381.G1Jac
+	for i := n - 1; i >= 0; i-- {
+		acc.DoubleAssign()
+
+		m := ks[0].Bit(i) | ks[1].Bit(i)<<1 | ks[2].Bit(i)<<2 | ks[3].Bit(i)<<3
+		if m != 0 {
+			acc.AddMixed(&affine[m-1])
+		}
+	}
+
+	res := &bls12381G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
 }
 
 func (g *bls12381G1) Equals(a driver.G1) bool {
@@ -69,11 +125,23 @@ func (g *bls12381G1) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12381G1) PutBytes(dst []byte) int {
+	raw := g.G1Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12381G1) Compressed() []byte {
 	raw := g.G1Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls12381G1) PutCompressed(dst []byte) int {
+	raw := g.G1Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12381G1) Sub(a driver.G1) {
 	j, k := bls12381.G1Jac{}, bls12381.G1Jac{}
 	j.FromAffine(&g.G1Affine)
@@ -86,10 +154,12 @@ func (g *bls12381G1) IsInfinity() bool {
 	return g.G1Affine.IsInfinity()
 }
 
+func (g *bls12381G1) IsGenerator() bool {
+	return g.G1Affine.Bytes() == g1Bytes12_381
+}
+
 func (g *bls12381G1) String() string {
-	rawstr := g.G1Affine.String()
-	m := g1StrRegexp.FindAllStringSubmatch(rawstr, -1)
-	return "(" + strings.TrimLeft(m[0][1], "0") + "," + strings.TrimLeft(m[0][2], "0") + ")"
+	return "(" + g.X.String() + "," + g.Y.String() + ")"
 }
 
 func (g *bls12381G1) Neg() {
@@ -118,7 +188,7 @@ func (e *bls12381G2) Copy() driver.G2 {
 
 func (g *bls12381G2) Mul(a driver.Zr) driver.G2 {
 	gc := &bls12381G2{}
-	gc.G2Affine.ScalarMultiplication(&g.G2Affine, &a.(*common.BaseZr).Int)
+	gc.G2Affine.ScalarMultiplication(&g.G2Affine, a.(*bls12381Zr).value(new(big.Int)))
 
 	return gc
 }
@@ -148,11 +218,23 @@ func (g *bls12381G2) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12381G2) PutBytes(dst []byte) int {
+	raw := g.G2Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12381G2) Compressed() []byte {
 	raw := g.G2Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls12381G2) PutCompressed(dst []byte) int {
+	raw := g.G2Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bls12381G2) String() string {
 	return g.G2Affine.String()
 }
@@ -161,15 +243,34 @@ func (g *bls12381G2) Equals(a driver.G2) bool {
 	return g.G2Affine.Equal(&a.(*bls12381G2).G2Affine)
 }
 
+func (g *bls12381G2) IsGenerator() bool {
+	return g.G2Affine.Bytes() == g2Bytes12_381
+}
+
 /*********************************************************************/
 
 type bls12381Gt struct {
 	bls12381.GT
 }
 
+// Exp computes the power in the result, the only GT it allocates, and
+// takes the exponent from expPool: what is left is the scratch of gnark's
+// E12.Exp.
 func (g *bls12381Gt) Exp(x driver.Zr) driver.Gt {
-	copy := bls12381.GT{}
-	return &bls12381Gt{*copy.Exp(g.GT, &x.(*common.BaseZr).Int)}
+	e := expPool.Get().(*big.Int)
+	defer expPool.Put(e)
+
+	r := &bls12381Gt{}
+	r.GT.Exp(g.GT, x.(*bls12381Zr).value(e))
+	return r
+}
+
+// expPool recycles the big.Int exponents of Gt.Exp, which do not outlive
+// the call.
+var expPool = sync.Pool{
+	New: func() interface{} {
+		return new(big.Int)
+	},
 }
 
 func (g *bls12381Gt) Equals(a driver.Gt) bool {
@@ -180,10 +281,15 @@ func (g *bls12381Gt) Inverse() {
 	g.GT.Inverse(&g.GT)
 }
 
+func (g *bls12381Gt) Conjugate() {
+	g.GT.Conjugate(&g.GT)
+}
+
 func (g *bls12381Gt) Mul(a driver.Gt) {
 	g.GT.Mul(&g.GT, &a.(*bls12381Gt).GT)
 }
 
+// IsUnity compares against a GT on the stack and does not allocate.
 func (g *bls12381Gt) IsUnity() bool {
 	unity := bls12381.GT{}
 	unity.SetOne()
@@ -200,10 +306,107 @@ func (g *bls12381Gt) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls12381Gt) PutBytes(dst []byte) int {
+	raw := g.GT.Bytes()
+	return copy(dst, raw[:])
+}
+
+/*********************************************************************/
+
+type bls12381Fp struct {
+	fp.Element
+}
+
+func (e *bls12381Fp) Add(a driver.Fp) driver.Fp {
+	rv := &bls12381Fp{}
+	rv.Element.Add(&e.Element, &a.(*bls12381Fp).Element)
+	return rv
+}
+
+func (e *bls12381Fp) Sub(a driver.Fp) driver.Fp {
+	rv := &bls12381Fp{}
+	rv.Element.Sub(&e.Element, &a.(*bls12381Fp).Element)
+	return rv
+}
+
+func (e *bls12381Fp) Mul(a driver.Fp) driver.Fp {
+	rv := &bls12381Fp{}
+	rv.Element.Mul(&e.Element, &a.(*bls12381Fp).Element)
+	return rv
+}
+
+func (e *bls12381Fp) Neg() driver.Fp {
+	rv := &bls12381Fp{}
+	rv.Element.Neg(&e.Element)
+	return rv
+}
+
+func (e *bls12381Fp) Inverse() driver.Fp {
+	rv := &bls12381Fp{}
+	rv.Element.Inverse(&e.Element)
+	return rv
+}
+
+func (e *bls12381Fp) Sqrt() (driver.Fp, bool) {
+	rv := &bls12381Fp{}
+	if rv.Element.Sqrt(&e.Element) == nil {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func (e *bls12381Fp) Equals(a driver.Fp) bool {
+	return e.Element.Equal(&a.(*bls12381Fp).Element)
+}
+
+func (e *bls12381Fp) Bytes() []byte {
+	raw := e.Element.Bytes()
+	return raw[:]
+}
+
+func (e *bls12381Fp) String() string {
+	return e.Element.Text(16)
+}
+
 /*********************************************************************/
 
+// gnarkSubgroupCheckFailed and gnarkNoSquareRoot are the messages of the
+// errors of gnark's decoders on points that are not in the subgroup or not
+// on the curve, which it does not tell apart otherwise.
+const (
+	gnarkSubgroupCheckFailed = "invalid point: subgroup check failed"
+	gnarkNoSquareRoot        = "invalid compressed coordinate: square root doesn't exist"
+)
+
+// setBytesFailed returns the error of a decoder for which gnark returned
+// err, wrapping driver.ErrNotOnCurve or driver.ErrNotInSubgroup if that is
+// the cause. gnark runs no separate curve check, so points that are not on
+// the curve fail its subgroup check; onCurve tells the two apart, since the
+// coordinates are set before that check.
+func setBytesFailed(err error, onCurve bool) error {
+	switch err.Error() {
+	case gnarkSubgroupCheckFailed:
+		if onCurve {
+			return fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+		}
+		return fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	case gnarkNoSquareRoot:
+		return fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+
+	return fmt.Errorf("set bytes failed [%s]", err.Error())
+}
+
+// errNotCyclotomic is the error of CompressedGt on elements that torus
+// compression would not preserve, e.g. pairings that did not go through FExp
+var errNotCyclotomic = errors.New("element is not in the cyclotomic subgroup")
+
 func NewBls12_381() *Bls12_381 {
-	return &Bls12_381{common.CurveBase{Modulus: *fr.Modulus()}}
+	c := &Bls12_381{CurveBase: common.CurveBase{Modulus: *fr.Modulus()}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*bls12381Gt).GT
+	return c
 }
 
 func NewBls12_381BBS() *Bls12_381BBS {
@@ -212,12 +415,34 @@ func NewBls12_381BBS() *Bls12_381BBS {
 
 type Bls12_381 struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bls12381.GT
+}
+
+func (c *Bls12_381) Info() *driver.CurveInfo {
+	return common.BLS12_381Info()
 }
 
 type Bls12_381BBS struct {
 	Bls12_381
 }
 
+// blake2bPool recycles the blake2b-512 hashers used by the BBS hash
+// functions; ExpandMsgXmd resets them before use.
+var blake2bPool = sync.Pool{
+	New: func() interface{} {
+		// We pass a null key so error is impossible here.
+		h, _ := blake2b.New512(nil) //nolint:errcheck
+		return h
+	},
+}
+
+// Pairing, Pairing2 and FExp keep their GT intermediates on the stack: the
+// only GT they allocate is the result, which the caller owns and which
+// therefore cannot come from a pool. What else they allocate is scratch
+// internal to gnark, i.e. the copies of the inputs in MillerLoop and the
+// batch inversions of the final exponentiation.
 func (c *Bls12_381) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
 	t, err := bls12381.MillerLoop([]bls12381.G1Affine{p1.(*bls12381G1).G1Affine}, []bls12381.G2Affine{p2.(*bls12381G2).G2Affine})
 	if err != nil {
@@ -240,13 +465,45 @@ func (c *Bls12_381) FExp(a driver.Gt) driver.Gt {
 	return &bls12381Gt{bls12381.FinalExponentiation(&a.(*bls12381Gt).GT)}
 }
 
+func (c *Bls12_381) MultiPairing(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	return c.FExp(c.MillerLoop(p2, p1))
+}
+
+func (c *Bls12_381) MillerLoop(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	ps := make([]bls12381.G1Affine, len(p1))
+	qs := make([]bls12381.G2Affine, len(p2))
+	for i := range p1 {
+		ps[i] = p1[i].(*bls12381G1).G1Affine
+		qs[i] = p2[i].(*bls12381G2).G2Affine
+	}
+
+	t, err := bls12381.MillerLoop(ps, qs)
+	if err != nil {
+		panic(fmt.Sprintf("miller loop failed [%s]", err.Error()))
+	}
+
+	return &bls12381Gt{t}
+}
+
 var g1Bytes12_381 [48]byte
 var g2Bytes12_381 [96]byte
 
+// phi(x, y) = (thirdRootOneG1_12_381*x, y) acts on G1 as the scalar
+// glvLambda12_381; the constants are gnark's, which keeps them private.
+var (
+	thirdRootOneG1_12_381 fp.Element
+	glvLambda12_381       big.Int
+	glvBasis12_381        ecc.Lattice
+)
+
 func init() {
 	_, _, g1, g2 := bls12381.Generators()
 	g1Bytes12_381 = g1.Bytes()
 	g2Bytes12_381 = g2.Bytes()
+
+	thirdRootOneG1_12_381.SetString("4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939436")
+	glvLambda12_381.SetString("228988810152649578064853576960394133503", 10)
+	ecc.PrecomputeLattice(fr.Modulus(), &glvLambda12_381, &glvBasis12_381)
 }
 
 func (c *Bls12_381) GenG1() driver.G1 {
@@ -270,11 +527,7 @@ func (c *Bls12_381) GenG2() driver.G2 {
 }
 
 func (c *Bls12_381) GenGt() driver.Gt {
-	g1 := c.GenG1()
-	g2 := c.GenG2()
-	gengt := c.Pairing(g2, g1)
-	gengt = c.FExp(gengt)
-	return gengt
+	return &bls12381Gt{c.genGt}
 }
 
 func (c *Bls12_381) CoordinateByteSize() int {
@@ -297,6 +550,10 @@ func (c *Bls12_381) CompressedG2ByteSize() int {
 	return bls12381.SizeOfG2AffineCompressed
 }
 
+func (c *Bls12_381) GtByteSize() int {
+	return bls12381.SizeOfGT
+}
+
 func (c *Bls12_381) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -309,54 +566,234 @@ func (c *Bls12_381) NewG2() driver.G2 {
 	return &bls12381G2{}
 }
 
-func (c *Bls12_381) NewG1FromBytes(b []byte) driver.G1 {
+func (c *Bls12_381) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	frs := make([]fr.Element, len(scalars))
+	for i, s := range scalars {
+		frs[i].Set(s.(*bls12381Zr).reduced(&frs[i]))
+	}
+
+	points := bls12381.BatchScalarMultiplicationG1(&base.(*bls12381G1).G1Affine, frs)
+
+	res := make([]driver.G1, len(points))
+	for i := range points {
+		res[i] = &bls12381G1{points[i]}
+	}
+
+	return res
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]points[i], computed with
+// gnark's multi-exponentiation.
+func (c *Bls12_381) MultiScalarMul(points []driver.G1, scalars []driver.Zr, tasks int) driver.G1 {
+	ps := make([]bls12381.G1Affine, len(points))
+	frs := make([]fr.Element, len(scalars))
+	for i := range points {
+		ps[i] = points[i].(*bls12381G1).G1Affine
+		frs[i].Set(scalars[i].(*bls12381Zr).reduced(&frs[i]))
+	}
+
+	res := &bls12381G1{}
+	if _, err := res.G1Affine.MultiExp(ps, frs, multiExpConfig(tasks)); err != nil {
+		panic(fmt.Sprintf("MultiScalarMul failed [%s]", err.Error()))
+	}
+
+	return res
+}
+
+func (c *Bls12_381) ZrMontgomeryBytes(a driver.Zr) []byte {
+	var e fr.Element
+	return montgomeryBytes(a.(*bls12381Zr).reduced(&e)[:])
+}
+
+func (c *Bls12_381) NewZrFromMontgomery(b []byte) (driver.Zr, error) {
+	var e fr.Element
+	if err := setMontgomery(e[:], b, &c.Modulus); err != nil {
+		return nil, err
+	}
+
+	return &bls12381Zr{Element: e}, nil
+}
+
+func (c *Bls12_381) SumG1(points []driver.G1) driver.G1 {
+	var acc bls12381.G1Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls12381G1).G1Affine)
+	}
+
+	res := &bls12381G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls12_381) MulAddG1(acc driver.G1, s driver.Zr, P driver.G1) {
+	a := acc.(*bls12381G1)
+
+	var j bls12381.G1Jac
+	j.ScalarMultiplicationAffine(&P.(*bls12381G1).G1Affine, s.(*bls12381Zr).value(new(big.Int)))
+	j.AddMixed(&a.G1Affine)
+	a.G1Affine.FromJacobian(&j)
+}
+
+func (c *Bls12_381) MulAddG2(acc driver.G2, s driver.Zr, P driver.G2) {
+	a := acc.(*bls12381G2)
+
+	var j bls12381.G2Jac
+	j.FromAffine(&P.(*bls12381G2).G2Affine)
+	j.ScalarMultiplication(&j, s.(*bls12381Zr).value(new(big.Int)))
+	j.AddMixed(&a.G2Affine)
+	a.G2Affine.FromJacobian(&j)
+}
+
+func (c *Bls12_381) InfinityG1() driver.G1 {
+	return &bls12381G1{}
+}
+
+func (c *Bls12_381) InfinityG2() driver.G2 {
+	return &bls12381G2{}
+}
+
+func (c *Bls12_381) SumG2(points []driver.G2) driver.G2 {
+	var acc bls12381.G2Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls12381G2).G2Affine)
+	}
+
+	res := &bls12381G2{}
+	res.G2Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls12_381) NewG1FromBytes(b []byte) (driver.G1, error) {
 	v := &bls12381G1{}
 	_, err := v.G1Affine.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_381) NewG2FromBytes(b []byte) driver.G2 {
+func (c *Bls12_381) NewG2FromBytes(b []byte) (driver.G2, error) {
 	v := &bls12381G2{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+// NewG1FromBytesUnchecked decodes b with neither the on-curve nor the
+// subgroup check, which the caller must perform if b is not trusted.
+func (c *Bls12_381) NewG1FromBytesUnchecked(b []byte) (driver.G1, error) {
+	v := &bls12381G1{}
+	if err := bls12381.NewDecoder(bytes.NewReader(b), bls12381.NoSubgroupChecks()).Decode(&v.G1Affine); err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls12_381) NewG2FromBytesUnchecked(b []byte) (driver.G2, error) {
+	v := &bls12381G2{}
+	if err := bls12381.NewDecoder(bytes.NewReader(b), bls12381.NoSubgroupChecks()).Decode(&v.G2Affine); err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_381) NewG1FromCompressed(b []byte) driver.G1 {
+func (c *Bls12_381) NewG1FromCompressed(b []byte) (driver.G1, error) {
 	v := &bls12381G1{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_381) NewG2FromCompressed(b []byte) driver.G2 {
+func (c *Bls12_381) NewG2FromCompressed(b []byte) (driver.G2, error) {
 	v := &bls12381G2{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bls12_381) NewGtFromBytes(b []byte) driver.Gt {
+func (c *Bls12_381) NewGtFromBytes(b []byte) (driver.Gt, error) {
 	v := &bls12381Gt{}
 	err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
 	}
 
-	return v
+	return v, nil
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fp; the point at
+// infinity is (0, 0) like any other pair of coordinates.
+func (c *Bls12_381) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*bls12381G1).G1Affine, &b.(*bls12381G1).G1Affine
+	rv := &bls12381G1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+// SelectG2 is SelectG1 for G2, whose coordinates are selected component
+// by component.
+func (c *Bls12_381) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	p, q := &a.(*bls12381G2).G2Affine, &b.(*bls12381G2).G2Affine
+	rv := &bls12381G2{}
+	rv.X.A0.Select(cond, &q.X.A0, &p.X.A0)
+	rv.X.A1.Select(cond, &q.X.A1, &p.X.A1)
+	rv.Y.A0.Select(cond, &q.Y.A0, &p.Y.A0)
+	rv.Y.A1.Select(cond, &q.Y.A1, &p.Y.A1)
+	return rv
+}
+
+// CompressedGt returns the torus-based compression of a, half the size of
+// Bytes, with the identity encoded as zeros. It fails unless a is in the
+// cyclotomic subgroup, as the outputs of FExp are.
+func (c *Bls12_381) CompressedGt(a driver.Gt) ([]byte, error) {
+	gt := &a.(*bls12381Gt).GT
+
+	var y bls12381.GT
+	if !gt.IsOne() {
+		var norm bls12381.GT
+		if !norm.Conjugate(gt).Mul(&norm, gt).IsOne() {
+			return nil, errNotCyclotomic
+		}
+
+		var err error
+		if y.C0, err = gt.CompressTorus(); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := y.Bytes()
+	return raw[bls12381.SizeOfGT/2:], nil
+}
+
+func (c *Bls12_381) NewGtFromCompressed(b []byte) (driver.Gt, error) {
+	if len(b) != bls12381.SizeOfGT/2 {
+		return nil, fmt.Errorf("invalid compressed Gt length %d", len(b))
+	}
+
+	var y bls12381.GT
+	if err := y.SetBytes(append(make([]byte, bls12381.SizeOfGT/2), b...)); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	if y.C0.IsZero() {
+		return &bls12381Gt{*y.SetOne()}, nil
+	}
+
+	return &bls12381Gt{y.C0.DecompressTorus()}, nil
 }
 
 func (c *Bls12_381) HashToG1(data []byte) driver.G1 {
@@ -386,6 +823,72 @@ func (p *Bls12_381) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	return &bls12381G1{g1}
 }
 
+func (p *Bls12_381) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	u, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = &common.BaseZr{Int: *u[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return p.MapToG1(els), els
+}
+
+func (p *Bls12_381) MapToG1(u []driver.Zr) driver.G1 {
+	var acc bls12381.G1Jac
+	for _, e := range u {
+		var el fp.Element
+		el.SetBigInt(&e.(*common.BaseZr).Int)
+		q := bls12381.MapToG1(el)
+		acc.AddMixed(&q)
+	}
+
+	res := &bls12381G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+// MapFieldToG1 maps the base field element b to G1 with MapToG1, the
+// map_to_curve of RFC 9380 followed by clear_cofactor.
+func (p *Bls12_381) MapFieldToG1(b []byte) (driver.G1, error) {
+	var u fp.Element
+	if err := u.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return &bls12381G1{bls12381.MapToG1(u)}, nil
+}
+
+// MapFieldToG2 is MapFieldToG1 for G2, whose field elements are made of 2
+// components of the base field.
+func (p *Bls12_381) MapFieldToG2(b []byte) (driver.G2, error) {
+	if len(b) != 2*fp.Bytes {
+		return nil, fmt.Errorf("invalid field element length [%d]", len(b))
+	}
+
+	// x = A0 + A1*u, laid out from A1 to A0
+	var q bls12381.G2Affine
+	for i, a := range []*fp.Element{&q.X.A1, &q.X.A0} {
+		if err := a.SetBytesCanonical(b[i*fp.Bytes : (i+1)*fp.Bytes]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bls12381G2{bls12381.MapToG2(q.X)}, nil
+}
+
+func (p *Bls12_381) NewFpFromBytes(b []byte) (driver.Fp, error) {
+	e := &bls12381Fp{}
+	if err := e.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 func (p *Bls12_381) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2, err := bls12381.HashToG2(data, domain)
 	if err != nil {
@@ -396,11 +899,9 @@ func (p *Bls12_381) HashToG2WithDomain(data, domain []byte) driver.G2 {
 }
 
 func (c *Bls12_381BBS) HashToG1(data []byte) driver.G1 {
-	hashFunc := func() hash.Hash {
-		// We pass a null key so error is impossible here.
-		h, _ := blake2b.New512(nil) //nolint:errcheck
-		return h
-	}
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
 
 	g1, err := HashToG1GenericBESwu(data, []byte{}, hashFunc)
 	if err != nil {
@@ -420,11 +921,9 @@ func (c *Bls12_381BBS) HashToG2(data []byte) driver.G2 {
 }
 
 func (p *Bls12_381BBS) HashToG1WithDomain(data, domain []byte) driver.G1 {
-	hashFunc := func() hash.Hash {
-		// We pass a null key so error is impossible here.
-		h, _ := blake2b.New512(nil) //nolint:errcheck
-		return h
-	}
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
 
 	g1, err := HashToG1GenericBESwu(data, domain, hashFunc)
 	if err != nil {
@@ -434,6 +933,45 @@ func (p *Bls12_381BBS) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	return &bls12381G1{g1}
 }
 
+func (p *Bls12_381BBS) HashToZrWithDomain(data, domain []byte) driver.Zr {
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
+
+	v, err := HashToZrGenericBE(data, domain, &p.Modulus, hashFunc)
+	if err != nil {
+		panic(fmt.Sprintf("HashToZr failed [%s]", err.Error()))
+	}
+
+	return newBls12381Zr(v)
+}
+
+func (p *Bls12_381BBS) HashToZrBatch(data, domain []byte, count int) []driver.Zr {
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
+
+	vs, err := HashToZrsGenericBE(data, domain, &p.Modulus, count, hashFunc)
+	if err != nil {
+		panic(fmt.Sprintf("HashToZr failed [%s]", err.Error()))
+	}
+
+	res := make([]driver.Zr, count)
+	for i, v := range vs {
+		res[i] = newBls12381Zr(v)
+	}
+
+	return res
+}
+
+func (p *Bls12_381BBS) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	panic("HashToG1WithU is not available for this curve")
+}
+
+func (p *Bls12_381BBS) MapToG1(u []driver.Zr) driver.G1 {
+	panic("MapToG1 is not available for this curve")
+}
+
 func (p *Bls12_381BBS) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2, err := bls12381.HashToG2(data, domain)
 	if err != nil {
@@ -0,0 +1,812 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gurvy
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+/*********************************************************************/
+
+type bls24315G1 struct {
+	bls24315.G1Affine
+}
+
+func (g *bls24315G1) Clone(a driver.G1) {
+	raw := a.(*bls24315G1).G1Affine.Bytes()
+	_, err := g.SetBytes(raw[:])
+	if err != nil {
+		panic("could not copy point")
+	}
+}
+
+func (e *bls24315G1) Copy() driver.G1 {
+	c := &bls24315G1{}
+	c.Set(&e.G1Affine)
+	return c
+}
+
+func (g *bls24315G1) Add(a driver.G1) {
+	j := bls24315.G1Jac{}
+	j.FromAffine(&g.G1Affine)
+	j.AddMixed((*bls24315.G1Affine)(&a.(*bls24315G1).G1Affine))
+	g.G1Affine.FromJacobian(&j)
+}
+
+func (g *bls24315G1) Mul(a driver.Zr) driver.G1 {
+	ret := &bls24315G1{}
+	ret.G1Affine.ScalarMultiplication(&g.G1Affine, &a.(*common.BaseZr).Int)
+
+	return ret
+}
+
+func (g *bls24315G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *bls24315G1) Equals(a driver.G1) bool {
+	return g.G1Affine.Equal(&a.(*bls24315G1).G1Affine)
+}
+
+func (g *bls24315G1) Bytes() []byte {
+	raw := g.G1Affine.RawBytes()
+	return raw[:]
+}
+
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls24315G1) PutBytes(dst []byte) int {
+	raw := g.G1Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
+func (g *bls24315G1) Compressed() []byte {
+	raw := g.G1Affine.Bytes()
+	return raw[:]
+}
+
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls24315G1) PutCompressed(dst []byte) int {
+	raw := g.G1Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
+func (g *bls24315G1) Sub(a driver.G1) {
+	j, k := bls24315.G1Jac{}, bls24315.G1Jac{}
+	j.FromAffine(&g.G1Affine)
+	k.FromAffine(&a.(*bls24315G1).G1Affine)
+	j.SubAssign(&k)
+	g.G1Affine.FromJacobian(&j)
+}
+
+func (g *bls24315G1) IsInfinity() bool {
+	return g.G1Affine.IsInfinity()
+}
+
+func (g *bls24315G1) IsGenerator() bool {
+	return g.G1Affine.Bytes() == g1Bytes24_315
+}
+
+func (g *bls24315G1) String() string {
+	return "(" + g.X.String() + "," + g.Y.String() + ")"
+}
+
+func (g *bls24315G1) Neg() {
+	g.G1Affine.Neg(&g.G1Affine)
+}
+
+/*********************************************************************/
+
+type bls24315G2 struct {
+	bls24315.G2Affine
+}
+
+func (g *bls24315G2) Clone(a driver.G2) {
+	raw := a.(*bls24315G2).G2Affine.Bytes()
+	_, err := g.SetBytes(raw[:])
+	if err != nil {
+		panic("could not copy point")
+	}
+}
+
+func (e *bls24315G2) Copy() driver.G2 {
+	c := &bls24315G2{}
+	c.Set(&e.G2Affine)
+	return c
+}
+
+func (g *bls24315G2) Mul(a driver.Zr) driver.G2 {
+	gc := &bls24315G2{}
+	gc.G2Affine.ScalarMultiplication(&g.G2Affine, &a.(*common.BaseZr).Int)
+
+	return gc
+}
+
+func (g *bls24315G2) Add(a driver.G2) {
+	j := bls24315.G2Jac{}
+	j.FromAffine(&g.G2Affine)
+	j.AddMixed((*bls24315.G2Affine)(&a.(*bls24315G2).G2Affine))
+	g.G2Affine.FromJacobian(&j)
+}
+
+func (g *bls24315G2) Sub(a driver.G2) {
+	j := bls24315.G2Jac{}
+	j.FromAffine(&g.G2Affine)
+	aJac := bls24315.G2Jac{}
+	aJac.FromAffine((*bls24315.G2Affine)(&a.(*bls24315G2).G2Affine))
+	j.SubAssign(&aJac)
+	g.G2Affine.FromJacobian(&j)
+}
+
+func (g *bls24315G2) Affine() {
+	// we're always affine
+}
+
+func (g *bls24315G2) Bytes() []byte {
+	raw := g.G2Affine.RawBytes()
+	return raw[:]
+}
+
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls24315G2) PutBytes(dst []byte) int {
+	raw := g.G2Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
+func (g *bls24315G2) Compressed() []byte {
+	raw := g.G2Affine.Bytes()
+	return raw[:]
+}
+
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bls24315G2) PutCompressed(dst []byte) int {
+	raw := g.G2Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
+func (g *bls24315G2) String() string {
+	return g.G2Affine.String()
+}
+
+func (g *bls24315G2) Equals(a driver.G2) bool {
+	return g.G2Affine.Equal(&a.(*bls24315G2).G2Affine)
+}
+
+func (g *bls24315G2) IsGenerator() bool {
+	return g.G2Affine.Bytes() == g2Bytes24_315
+}
+
+/*********************************************************************/
+
+type bls24315Gt struct {
+	bls24315.GT
+}
+
+func (g *bls24315Gt) Exp(x driver.Zr) driver.Gt {
+	copy := bls24315.GT{}
+	return &bls24315Gt{*copy.Exp(g.GT, &x.(*common.BaseZr).Int)}
+}
+
+func (g *bls24315Gt) Equals(a driver.Gt) bool {
+	return g.GT.Equal(&a.(*bls24315Gt).GT)
+}
+
+func (g *bls24315Gt) Inverse() {
+	g.GT.Inverse(&g.GT)
+}
+
+func (g *bls24315Gt) Conjugate() {
+	g.GT.Conjugate(&g.GT)
+}
+
+func (g *bls24315Gt) Mul(a driver.Gt) {
+	g.GT.Mul(&g.GT, &a.(*bls24315Gt).GT)
+}
+
+func (g *bls24315Gt) IsUnity() bool {
+	unity := bls24315.GT{}
+	unity.SetOne()
+
+	return unity.Equal(&g.GT)
+}
+
+func (g *bls24315Gt) ToString() string {
+	return g.GT.String()
+}
+
+func (g *bls24315Gt) Bytes() []byte {
+	raw := g.GT.Bytes()
+	return raw[:]
+}
+
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bls24315Gt) PutBytes(dst []byte) int {
+	raw := g.GT.Bytes()
+	return copy(dst, raw[:])
+}
+
+/*********************************************************************/
+
+type bls24315Fp struct {
+	fp.Element
+}
+
+func (e *bls24315Fp) Add(a driver.Fp) driver.Fp {
+	rv := &bls24315Fp{}
+	rv.Element.Add(&e.Element, &a.(*bls24315Fp).Element)
+	return rv
+}
+
+func (e *bls24315Fp) Sub(a driver.Fp) driver.Fp {
+	rv := &bls24315Fp{}
+	rv.Element.Sub(&e.Element, &a.(*bls24315Fp).Element)
+	return rv
+}
+
+func (e *bls24315Fp) Mul(a driver.Fp) driver.Fp {
+	rv := &bls24315Fp{}
+	rv.Element.Mul(&e.Element, &a.(*bls24315Fp).Element)
+	return rv
+}
+
+func (e *bls24315Fp) Neg() driver.Fp {
+	rv := &bls24315Fp{}
+	rv.Element.Neg(&e.Element)
+	return rv
+}
+
+func (e *bls24315Fp) Inverse() driver.Fp {
+	rv := &bls24315Fp{}
+	rv.Element.Inverse(&e.Element)
+	return rv
+}
+
+func (e *bls24315Fp) Sqrt() (driver.Fp, bool) {
+	rv := &bls24315Fp{}
+	if rv.Element.Sqrt(&e.Element) == nil {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func (e *bls24315Fp) Equals(a driver.Fp) bool {
+	return e.Element.Equal(&a.(*bls24315Fp).Element)
+}
+
+func (e *bls24315Fp) Bytes() []byte {
+	raw := e.Element.Bytes()
+	return raw[:]
+}
+
+func (e *bls24315Fp) String() string {
+	return e.Element.Text(16)
+}
+
+/*********************************************************************/
+
+func NewBls24_315() *Bls24_315 {
+	c := &Bls24_315{CurveBase: common.CurveBase{Modulus: *fr.Modulus()}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*bls24315Gt).GT
+	return c
+}
+
+type Bls24_315 struct {
+	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bls24315.GT
+}
+
+func (c *Bls24_315) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		EmbeddingDegree:    24,
+		G1Cofactor:         common.HexInt("2fe8030000000000"),
+		G2Cofactor:         common.HexInt("142a76791a4ecf9c5e2d1e9744e1a3d20ecd4e893c629f9a3e8f21811c01446602b3ec97c88db0069213228615137a0dded7e599b628469c774cb87cd287bf73a8d2cc439ffffe00fe2b41efdc3698dd4b373acdeee183eb09e6f58e9055cd34eace3e7e701215b52c02797e31a2c6fe9ac0018b940adf101e0000000001"),
+		BaseFieldModulus:   fp.Modulus(),
+		ScalarFieldModulus: fr.Modulus(),
+	}
+}
+
+func (c *Bls24_315) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModAddMul(a1, b1, m)
+	}
+
+	var sum, a, b fr.Element
+	for i := range a1 {
+		a.SetBigInt(&a1[i].(*common.BaseZr).Int)
+		b.SetBigInt(&b1[i].(*common.BaseZr).Int)
+		sum.Add(&sum, a.Mul(&a, &b))
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	sum.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bls24_315) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModMul3(a1, b1, c1, m)
+	}
+
+	var a, b, e fr.Element
+	a.SetBigInt(&a1.(*common.BaseZr).Int)
+	b.SetBigInt(&b1.(*common.BaseZr).Int)
+	e.SetBigInt(&c1.(*common.BaseZr).Int)
+	a.Mul(&a, &b).Mul(&a, &e)
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	a.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bls24_315) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
+}
+
+func (c *Bls24_315) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	t, err := bls24315.MillerLoop([]bls24315.G1Affine{p1.(*bls24315G1).G1Affine}, []bls24315.G2Affine{p2.(*bls24315G2).G2Affine})
+	if err != nil {
+		panic(fmt.Sprintf("pairing failed [%s]", err.Error()))
+	}
+
+	return &bls24315Gt{t}
+}
+
+func (c *Bls24_315) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	t, err := bls24315.MillerLoop([]bls24315.G1Affine{p1a.(*bls24315G1).G1Affine, p1b.(*bls24315G1).G1Affine}, []bls24315.G2Affine{p2a.(*bls24315G2).G2Affine, p2b.(*bls24315G2).G2Affine})
+	if err != nil {
+		panic(fmt.Sprintf("pairing 2 failed [%s]", err.Error()))
+	}
+
+	return &bls24315Gt{t}
+}
+
+func (c *Bls24_315) FExp(a driver.Gt) driver.Gt {
+	return &bls24315Gt{bls24315.FinalExponentiation(&a.(*bls24315Gt).GT)}
+}
+
+func (c *Bls24_315) MultiPairing(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	return c.FExp(c.MillerLoop(p2, p1))
+}
+
+func (c *Bls24_315) MillerLoop(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	ps := make([]bls24315.G1Affine, len(p1))
+	qs := make([]bls24315.G2Affine, len(p2))
+	for i := range p1 {
+		ps[i] = p1[i].(*bls24315G1).G1Affine
+		qs[i] = p2[i].(*bls24315G2).G2Affine
+	}
+
+	t, err := bls24315.MillerLoop(ps, qs)
+	if err != nil {
+		panic(fmt.Sprintf("miller loop failed [%s]", err.Error()))
+	}
+
+	return &bls24315Gt{t}
+}
+
+var g1Bytes24_315 [bls24315.SizeOfG1AffineCompressed]byte
+var g2Bytes24_315 [bls24315.SizeOfG2AffineCompressed]byte
+
+func init() {
+	_, _, g1, g2 := bls24315.Generators()
+	g1Bytes24_315 = g1.Bytes()
+	g2Bytes24_315 = g2.Bytes()
+}
+
+func (c *Bls24_315) GenG1() driver.G1 {
+	r := &bls24315G1{}
+	_, err := r.SetBytes(g1Bytes24_315[:])
+	if err != nil {
+		panic("could not generate point")
+	}
+
+	return r
+}
+
+func (c *Bls24_315) GenG2() driver.G2 {
+	r := &bls24315G2{}
+	_, err := r.SetBytes(g2Bytes24_315[:])
+	if err != nil {
+		panic("could not generate point")
+	}
+
+	return r
+}
+
+func (c *Bls24_315) GenGt() driver.Gt {
+	return &bls24315Gt{c.genGt}
+}
+
+func (c *Bls24_315) CoordinateByteSize() int {
+	return bls24315.SizeOfG1AffineCompressed
+}
+
+func (c *Bls24_315) G1ByteSize() int {
+	return bls24315.SizeOfG1AffineUncompressed
+}
+
+func (c *Bls24_315) CompressedG1ByteSize() int {
+	return bls24315.SizeOfG1AffineCompressed
+}
+
+func (c *Bls24_315) G2ByteSize() int {
+	return bls24315.SizeOfG2AffineUncompressed
+}
+
+func (c *Bls24_315) CompressedG2ByteSize() int {
+	return bls24315.SizeOfG2AffineCompressed
+}
+
+func (c *Bls24_315) GtByteSize() int {
+	return bls24315.SizeOfGT
+}
+
+func (c *Bls24_315) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Bls24_315) NewG1() driver.G1 {
+	return &bls24315G1{}
+}
+
+func (c *Bls24_315) NewG2() driver.G2 {
+	return &bls24315G2{}
+}
+
+func (c *Bls24_315) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	frs := make([]fr.Element, len(scalars))
+	for i, s := range scalars {
+		frs[i].SetBigInt(&s.(*common.BaseZr).Int)
+	}
+
+	points := bls24315.BatchScalarMultiplicationG1(&base.(*bls24315G1).G1Affine, frs)
+
+	res := make([]driver.G1, len(points))
+	for i := range points {
+		res[i] = &bls24315G1{points[i]}
+	}
+
+	return res
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]points[i], computed with
+// gnark's multi-exponentiation.
+func (c *Bls24_315) MultiScalarMul(points []driver.G1, scalars []driver.Zr, tasks int) driver.G1 {
+	ps := make([]bls24315.G1Affine, len(points))
+	frs := make([]fr.Element, len(scalars))
+	for i := range points {
+		ps[i] = points[i].(*bls24315G1).G1Affine
+		frs[i].SetBigInt(&scalars[i].(*common.BaseZr).Int)
+	}
+
+	res := &bls24315G1{}
+	if _, err := res.G1Affine.MultiExp(ps, frs, multiExpConfig(tasks)); err != nil {
+		panic(fmt.Sprintf("MultiScalarMul failed [%s]", err.Error()))
+	}
+
+	return res
+}
+
+func (c *Bls24_315) ZrMontgomeryBytes(a driver.Zr) []byte {
+	var e fr.Element
+	e.SetBigInt(&a.(*common.BaseZr).Int)
+	return montgomeryBytes(e[:])
+}
+
+func (c *Bls24_315) NewZrFromMontgomery(b []byte) (driver.Zr, error) {
+	var e fr.Element
+	if err := setMontgomery(e[:], b, &c.Modulus); err != nil {
+		return nil, err
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	e.BigInt(&res.Int)
+	return res, nil
+}
+
+func (c *Bls24_315) SumG1(points []driver.G1) driver.G1 {
+	var acc bls24315.G1Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls24315G1).G1Affine)
+	}
+
+	res := &bls24315G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls24_315) MulAddG1(acc driver.G1, s driver.Zr, P driver.G1) {
+	a := acc.(*bls24315G1)
+
+	var j bls24315.G1Jac
+	j.ScalarMultiplicationAffine(&P.(*bls24315G1).G1Affine, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G1Affine)
+	a.G1Affine.FromJacobian(&j)
+}
+
+func (c *Bls24_315) MulAddG2(acc driver.G2, s driver.Zr, P driver.G2) {
+	a := acc.(*bls24315G2)
+
+	var j bls24315.G2Jac
+	j.FromAffine(&P.(*bls24315G2).G2Affine)
+	j.ScalarMultiplication(&j, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G2Affine)
+	a.G2Affine.FromJacobian(&j)
+}
+
+func (c *Bls24_315) InfinityG1() driver.G1 {
+	return &bls24315G1{}
+}
+
+func (c *Bls24_315) InfinityG2() driver.G2 {
+	return &bls24315G2{}
+}
+
+func (c *Bls24_315) SumG2(points []driver.G2) driver.G2 {
+	var acc bls24315.G2Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bls24315G2).G2Affine)
+	}
+
+	res := &bls24315G2{}
+	res.G2Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bls24_315) NewG1FromBytes(b []byte) (driver.G1, error) {
+	v := &bls24315G1{}
+	_, err := v.G1Affine.SetBytes(b)
+	if err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls24_315) NewG2FromBytes(b []byte) (driver.G2, error) {
+	v := &bls24315G2{}
+	_, err := v.G2Affine.SetBytes(b)
+	if err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+// NewG1FromBytesUnchecked decodes b with neither the on-curve nor the
+// subgroup check, which the caller must perform if b is not trusted.
+func (c *Bls24_315) NewG1FromBytesUnchecked(b []byte) (driver.G1, error) {
+	v := &bls24315G1{}
+	if err := bls24315.NewDecoder(bytes.NewReader(b), bls24315.NoSubgroupChecks()).Decode(&v.G1Affine); err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls24_315) NewG2FromBytesUnchecked(b []byte) (driver.G2, error) {
+	v := &bls24315G2{}
+	if err := bls24315.NewDecoder(bytes.NewReader(b), bls24315.NoSubgroupChecks()).Decode(&v.G2Affine); err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls24_315) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	v := &bls24315G1{}
+	_, err := v.G1Affine.SetBytes(b)
+	if err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls24_315) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	v := &bls24315G2{}
+	_, err := v.G2Affine.SetBytes(b)
+	if err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bls24_315) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	v := &bls24315Gt{}
+	err := v.SetBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return v, nil
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fp; the point at
+// infinity is (0, 0) like any other pair of coordinates.
+func (c *Bls24_315) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*bls24315G1).G1Affine, &b.(*bls24315G1).G1Affine
+	rv := &bls24315G1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+// SelectG2 is SelectG1 for G2, whose coordinates are selected component
+// by component.
+func (c *Bls24_315) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	p, q := &a.(*bls24315G2).G2Affine, &b.(*bls24315G2).G2Affine
+	rv := &bls24315G2{}
+	rv.X.B0.A0.Select(cond, &q.X.B0.A0, &p.X.B0.A0)
+	rv.X.B0.A1.Select(cond, &q.X.B0.A1, &p.X.B0.A1)
+	rv.X.B1.A0.Select(cond, &q.X.B1.A0, &p.X.B1.A0)
+	rv.X.B1.A1.Select(cond, &q.X.B1.A1, &p.X.B1.A1)
+	rv.Y.B0.A0.Select(cond, &q.Y.B0.A0, &p.Y.B0.A0)
+	rv.Y.B0.A1.Select(cond, &q.Y.B0.A1, &p.Y.B0.A1)
+	rv.Y.B1.A0.Select(cond, &q.Y.B1.A0, &p.Y.B1.A0)
+	rv.Y.B1.A1.Select(cond, &q.Y.B1.A1, &p.Y.B1.A1)
+	return rv
+}
+
+// CompressedGt returns the torus-based compression of a, half the size of
+// Bytes, with the identity encoded as zeros. It fails unless a is in the
+// cyclotomic subgroup, as the outputs of FExp are.
+func (c *Bls24_315) CompressedGt(a driver.Gt) ([]byte, error) {
+	gt := &a.(*bls24315Gt).GT
+
+	var y bls24315.GT
+	if !gt.IsOne() {
+		var norm bls24315.GT
+		if !norm.Conjugate(gt).Mul(&norm, gt).IsOne() {
+			return nil, errNotCyclotomic
+		}
+
+		var err error
+		if y.D0, err = gt.CompressTorus(); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := y.Bytes()
+	return raw[:bls24315.SizeOfGT/2], nil
+}
+
+func (c *Bls24_315) NewGtFromCompressed(b []byte) (driver.Gt, error) {
+	if len(b) != bls24315.SizeOfGT/2 {
+		return nil, fmt.Errorf("invalid compressed Gt length %d", len(b))
+	}
+
+	var y bls24315.GT
+	if err := y.SetBytes(append(b[:len(b):len(b)], make([]byte, bls24315.SizeOfGT/2)...)); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	if y.D0.IsZero() {
+		return &bls24315Gt{*y.SetOne()}, nil
+	}
+
+	return &bls24315Gt{y.D0.DecompressTorus()}, nil
+}
+
+func (c *Bls24_315) HashToG1(data []byte) driver.G1 {
+	g1, err := bls24315.HashToG1(data, []byte{})
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	return &bls24315G1{g1}
+}
+
+func (c *Bls24_315) HashToG2(data []byte) driver.G2 {
+	g2, err := bls24315.HashToG2(data, []byte{})
+	if err != nil {
+		panic(fmt.Sprintf("HashToG2 failed [%s]", err.Error()))
+	}
+
+	return &bls24315G2{g2}
+}
+
+func (p *Bls24_315) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g1, err := bls24315.HashToG1(data, domain)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	return &bls24315G1{g1}
+}
+
+func (p *Bls24_315) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	u, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = &common.BaseZr{Int: *u[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return p.MapToG1(els), els
+}
+
+func (p *Bls24_315) MapToG1(u []driver.Zr) driver.G1 {
+	var acc bls24315.G1Jac
+	for _, e := range u {
+		var el fp.Element
+		el.SetBigInt(&e.(*common.BaseZr).Int)
+		q := bls24315.MapToG1(el)
+		acc.AddMixed(&q)
+	}
+
+	res := &bls24315G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+// MapFieldToG1 maps the base field element b to G1 with MapToG1, the
+// map_to_curve of RFC 9380 followed by clear_cofactor.
+func (p *Bls24_315) MapFieldToG1(b []byte) (driver.G1, error) {
+	var u fp.Element
+	if err := u.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return &bls24315G1{bls24315.MapToG1(u)}, nil
+}
+
+// MapFieldToG2 is MapFieldToG1 for G2, whose field elements are made of 4
+// components of the base field.
+func (p *Bls24_315) MapFieldToG2(b []byte) (driver.G2, error) {
+	if len(b) != 4*fp.Bytes {
+		return nil, fmt.Errorf("invalid field element length [%d]", len(b))
+	}
+
+	// x = B0 + B1*v with Bi = Ai0 + Ai1*u, laid out from B1.A1 to B0.A0
+	var q bls24315.G2Affine
+	for i, a := range []*fp.Element{&q.X.B1.A1, &q.X.B1.A0, &q.X.B0.A1, &q.X.B0.A0} {
+		if err := a.SetBytesCanonical(b[i*fp.Bytes : (i+1)*fp.Bytes]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bls24315G2{bls24315.MapToG2(q.X)}, nil
+}
+
+func (p *Bls24_315) NewFpFromBytes(b []byte) (driver.Fp, error) {
+	e := &bls24315Fp{}
+	if err := e.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (p *Bls24_315) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	g2, err := bls24315.HashToG2(data, domain)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG2 failed [%s]", err.Error()))
+	}
+
+	return &bls24315G2{g2}
+}
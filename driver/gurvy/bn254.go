@@ -7,13 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package gurvy
 
 import (
+	"bytes"
 	"fmt"
-	"regexp"
-	"strings"
+	"math/big"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/common"
 	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
@@ -68,11 +69,23 @@ func (g *bn254G1) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bn254G1) PutBytes(dst []byte) int {
+	raw := g.G1Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bn254G1) Compressed() []byte {
 	raw := g.G1Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bn254G1) PutCompressed(dst []byte) int {
+	raw := g.G1Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bn254G1) Sub(a driver.G1) {
 	j, k := bn254.G1Jac{}, bn254.G1Jac{}
 	j.FromAffine(&g.G1Affine)
@@ -85,12 +98,12 @@ func (g *bn254G1) IsInfinity() bool {
 	return g.G1Affine.IsInfinity()
 }
 
-var g1StrRegexp *regexp.Regexp = regexp.MustCompile(`^E\([[]([0-9]+),([0-9]+)[]]\)$`)
+func (g *bn254G1) IsGenerator() bool {
+	return g.G1Affine.Bytes() == g1Bytes254
+}
 
 func (g *bn254G1) String() string {
-	rawstr := g.G1Affine.String()
-	m := g1StrRegexp.FindAllStringSubmatch(rawstr, -1)
-	return "(" + strings.TrimLeft(m[0][1], "0") + "," + strings.TrimLeft(m[0][2], "0") + ")"
+	return "(" + g.X.String() + "," + g.Y.String() + ")"
 }
 
 func (g *bn254G1) Neg() {
@@ -149,11 +162,23 @@ func (g *bn254G2) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bn254G2) PutBytes(dst []byte) int {
+	raw := g.G2Affine.RawBytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bn254G2) Compressed() []byte {
 	raw := g.G2Affine.Bytes()
 	return raw[:]
 }
 
+// PutCompressed writes Compressed into dst, see driver.CompressedPutter.
+func (g *bn254G2) PutCompressed(dst []byte) int {
+	raw := g.G2Affine.Bytes()
+	return copy(dst, raw[:])
+}
+
 func (g *bn254G2) String() string {
 	return g.G2Affine.String()
 }
@@ -162,6 +187,10 @@ func (g *bn254G2) Equals(a driver.G2) bool {
 	return g.G2Affine.Equal(&a.(*bn254G2).G2Affine)
 }
 
+func (g *bn254G2) IsGenerator() bool {
+	return g.G2Affine.Bytes() == g2Bytes254
+}
+
 /*********************************************************************/
 
 type bn254Gt struct {
@@ -181,6 +210,10 @@ func (g *bn254Gt) Inverse() {
 	g.GT.Inverse(&g.GT)
 }
 
+func (g *bn254Gt) Conjugate() {
+	g.GT.Conjugate(&g.GT)
+}
+
 func (g *bn254Gt) Mul(a driver.Gt) {
 	g.GT.Mul(&g.GT, &a.(*bn254Gt).GT)
 }
@@ -201,14 +234,137 @@ func (g *bn254Gt) Bytes() []byte {
 	return raw[:]
 }
 
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (g *bn254Gt) PutBytes(dst []byte) int {
+	raw := g.GT.Bytes()
+	return copy(dst, raw[:])
+}
+
+/*********************************************************************/
+
+type bn254Fp struct {
+	fp.Element
+}
+
+func (e *bn254Fp) Add(a driver.Fp) driver.Fp {
+	rv := &bn254Fp{}
+	rv.Element.Add(&e.Element, &a.(*bn254Fp).Element)
+	return rv
+}
+
+func (e *bn254Fp) Sub(a driver.Fp) driver.Fp {
+	rv := &bn254Fp{}
+	rv.Element.Sub(&e.Element, &a.(*bn254Fp).Element)
+	return rv
+}
+
+func (e *bn254Fp) Mul(a driver.Fp) driver.Fp {
+	rv := &bn254Fp{}
+	rv.Element.Mul(&e.Element, &a.(*bn254Fp).Element)
+	return rv
+}
+
+func (e *bn254Fp) Neg() driver.Fp {
+	rv := &bn254Fp{}
+	rv.Element.Neg(&e.Element)
+	return rv
+}
+
+func (e *bn254Fp) Inverse() driver.Fp {
+	rv := &bn254Fp{}
+	rv.Element.Inverse(&e.Element)
+	return rv
+}
+
+func (e *bn254Fp) Sqrt() (driver.Fp, bool) {
+	rv := &bn254Fp{}
+	if rv.Element.Sqrt(&e.Element) == nil {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func (e *bn254Fp) Equals(a driver.Fp) bool {
+	return e.Element.Equal(&a.(*bn254Fp).Element)
+}
+
+func (e *bn254Fp) Bytes() []byte {
+	raw := e.Element.Bytes()
+	return raw[:]
+}
+
+func (e *bn254Fp) String() string {
+	return e.Element.Text(16)
+}
+
 /*********************************************************************/
 
 func NewBn254() *Bn254 {
-	return &Bn254{common.CurveBase{Modulus: *fr.Modulus()}}
+	c := &Bn254{CurveBase: common.CurveBase{Modulus: *fr.Modulus()}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*bn254Gt).GT
+	return c
 }
 
+// Bn254 is the BN curve of Ethereum, also known as alt_bn128 or bn256. It
+// is not the FP256BN curve of the amcl and fp256bn drivers: both are BN
+// curves over 256-bit fields, but with different parameters, so neither
+// their points nor their encodings can be mixed.
 type Bn254 struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bn254.GT
+}
+
+// Info describes BN254, whose security is estimated at about 100 bits since
+// the improvements of the number field sieve.
+func (c *Bn254) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  100,
+		EmbeddingDegree:    12,
+		G1Cofactor:         big.NewInt(1),
+		G2Cofactor:         common.HexInt("30644e72e131a029b85045b68181585e06ceecda572a2489345f2299c0f9fa8d"),
+		BaseFieldModulus:   fp.Modulus(),
+		ScalarFieldModulus: fr.Modulus(),
+	}
+}
+
+func (c *Bn254) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModAddMul(a1, b1, m)
+	}
+
+	var sum, a, b fr.Element
+	for i := range a1 {
+		a.SetBigInt(&a1[i].(*common.BaseZr).Int)
+		b.SetBigInt(&b1[i].(*common.BaseZr).Int)
+		sum.Add(&sum, a.Mul(&a, &b))
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	sum.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bn254) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModMul3(a1, b1, c1, m)
+	}
+
+	var a, b, e fr.Element
+	a.SetBigInt(&a1.(*common.BaseZr).Int)
+	b.SetBigInt(&b1.(*common.BaseZr).Int)
+	e.SetBigInt(&c1.(*common.BaseZr).Int)
+	a.Mul(&a, &b).Mul(&a, &e)
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	a.BigInt(&res.Int)
+	return res
+}
+
+func (c *Bn254) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
 }
 
 func (c *Bn254) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
@@ -233,6 +389,26 @@ func (c *Bn254) FExp(a driver.Gt) driver.Gt {
 	return &bn254Gt{bn254.FinalExponentiation(&a.(*bn254Gt).GT)}
 }
 
+func (c *Bn254) MultiPairing(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	return c.FExp(c.MillerLoop(p2, p1))
+}
+
+func (c *Bn254) MillerLoop(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	ps := make([]bn254.G1Affine, len(p1))
+	qs := make([]bn254.G2Affine, len(p2))
+	for i := range p1 {
+		ps[i] = p1[i].(*bn254G1).G1Affine
+		qs[i] = p2[i].(*bn254G2).G2Affine
+	}
+
+	t, err := bn254.MillerLoop(ps, qs)
+	if err != nil {
+		panic(fmt.Sprintf("miller loop failed [%s]", err.Error()))
+	}
+
+	return &bn254Gt{t}
+}
+
 var g1Bytes254 [32]byte
 var g2Bytes254 [64]byte
 
@@ -263,11 +439,7 @@ func (c *Bn254) GenG2() driver.G2 {
 }
 
 func (c *Bn254) GenGt() driver.Gt {
-	g1 := c.GenG1()
-	g2 := c.GenG2()
-	gengt := c.Pairing(g2, g1)
-	gengt = c.FExp(gengt)
-	return gengt
+	return &bn254Gt{c.genGt}
 }
 
 func (c *Bn254) CoordinateByteSize() int {
@@ -290,6 +462,10 @@ func (c *Bn254) CompressedG2ByteSize() int {
 	return bn254.SizeOfG2AffineCompressed
 }
 
+func (c *Bn254) GtByteSize() int {
+	return bn254.SizeOfGT
+}
+
 func (c *Bn254) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -302,54 +478,237 @@ func (c *Bn254) NewG2() driver.G2 {
 	return &bn254G2{}
 }
 
-func (c *Bn254) NewG1FromBytes(b []byte) driver.G1 {
+func (c *Bn254) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	frs := make([]fr.Element, len(scalars))
+	for i, s := range scalars {
+		frs[i].SetBigInt(&s.(*common.BaseZr).Int)
+	}
+
+	points := bn254.BatchScalarMultiplicationG1(&base.(*bn254G1).G1Affine, frs)
+
+	res := make([]driver.G1, len(points))
+	for i := range points {
+		res[i] = &bn254G1{points[i]}
+	}
+
+	return res
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]points[i], computed with
+// gnark's multi-exponentiation.
+func (c *Bn254) MultiScalarMul(points []driver.G1, scalars []driver.Zr, tasks int) driver.G1 {
+	ps := make([]bn254.G1Affine, len(points))
+	frs := make([]fr.Element, len(scalars))
+	for i := range points {
+		ps[i] = points[i].(*bn254G1).G1Affine
+		frs[i].SetBigInt(&scalars[i].(*common.BaseZr).Int)
+	}
+
+	res := &bn254G1{}
+	if _, err := res.G1Affine.MultiExp(ps, frs, multiExpConfig(tasks)); err != nil {
+		panic(fmt.Sprintf("MultiScalarMul failed [%s]", err.Error()))
+	}
+
+	return res
+}
+
+func (c *Bn254) ZrMontgomeryBytes(a driver.Zr) []byte {
+	var e fr.Element
+	e.SetBigInt(&a.(*common.BaseZr).Int)
+	return montgomeryBytes(e[:])
+}
+
+func (c *Bn254) NewZrFromMontgomery(b []byte) (driver.Zr, error) {
+	var e fr.Element
+	if err := setMontgomery(e[:], b, &c.Modulus); err != nil {
+		return nil, err
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	e.BigInt(&res.Int)
+	return res, nil
+}
+
+func (c *Bn254) SumG1(points []driver.G1) driver.G1 {
+	var acc bn254.G1Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bn254G1).G1Affine)
+	}
+
+	res := &bn254G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bn254) MulAddG1(acc driver.G1, s driver.Zr, P driver.G1) {
+	a := acc.(*bn254G1)
+
+	var j bn254.G1Jac
+	j.ScalarMultiplicationAffine(&P.(*bn254G1).G1Affine, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G1Affine)
+	a.G1Affine.FromJacobian(&j)
+}
+
+func (c *Bn254) MulAddG2(acc driver.G2, s driver.Zr, P driver.G2) {
+	a := acc.(*bn254G2)
+
+	var j bn254.G2Jac
+	j.FromAffine(&P.(*bn254G2).G2Affine)
+	j.ScalarMultiplication(&j, &s.(*common.BaseZr).Int)
+	j.AddMixed(&a.G2Affine)
+	a.G2Affine.FromJacobian(&j)
+}
+
+func (c *Bn254) InfinityG1() driver.G1 {
+	return &bn254G1{}
+}
+
+func (c *Bn254) InfinityG2() driver.G2 {
+	return &bn254G2{}
+}
+
+func (c *Bn254) SumG2(points []driver.G2) driver.G2 {
+	var acc bn254.G2Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*bn254G2).G2Affine)
+	}
+
+	res := &bn254G2{}
+	res.G2Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Bn254) NewG1FromBytes(b []byte) (driver.G1, error) {
 	v := &bn254G1{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bn254) NewG2FromBytes(b []byte) driver.G2 {
+func (c *Bn254) NewG2FromBytes(b []byte) (driver.G2, error) {
 	v := &bn254G2{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bn254) NewG1FromCompressed(b []byte) driver.G1 {
+// NewG1FromBytesUnchecked decodes b with neither the on-curve nor the
+// subgroup check, which the caller must perform if b is not trusted.
+func (c *Bn254) NewG1FromBytesUnchecked(b []byte) (driver.G1, error) {
+	v := &bn254G1{}
+	if err := bn254.NewDecoder(bytes.NewReader(b), bn254.NoSubgroupChecks()).Decode(&v.G1Affine); err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bn254) NewG2FromBytesUnchecked(b []byte) (driver.G2, error) {
+	v := &bn254G2{}
+	if err := bn254.NewDecoder(bytes.NewReader(b), bn254.NoSubgroupChecks()).Decode(&v.G2Affine); err != nil {
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Bn254) NewG1FromCompressed(b []byte) (driver.G1, error) {
 	v := &bn254G1{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bn254) NewG2FromCompressed(b []byte) driver.G2 {
+func (c *Bn254) NewG2FromCompressed(b []byte) (driver.G2, error) {
 	v := &bn254G2{}
 	_, err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err, v.G2Affine.IsOnCurve())
 	}
 
-	return v
+	return v, nil
 }
 
-func (c *Bn254) NewGtFromBytes(b []byte) driver.Gt {
+func (c *Bn254) NewGtFromBytes(b []byte) (driver.Gt, error) {
 	v := &bn254Gt{}
 	err := v.SetBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return v, nil
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fp; the point at
+// infinity is (0, 0) like any other pair of coordinates.
+func (c *Bn254) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*bn254G1).G1Affine, &b.(*bn254G1).G1Affine
+	rv := &bn254G1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+// SelectG2 is SelectG1 for G2, whose coordinates are selected component
+// by component.
+func (c *Bn254) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	p, q := &a.(*bn254G2).G2Affine, &b.(*bn254G2).G2Affine
+	rv := &bn254G2{}
+	rv.X.A0.Select(cond, &q.X.A0, &p.X.A0)
+	rv.X.A1.Select(cond, &q.X.A1, &p.X.A1)
+	rv.Y.A0.Select(cond, &q.Y.A0, &p.Y.A0)
+	rv.Y.A1.Select(cond, &q.Y.A1, &p.Y.A1)
+	return rv
+}
+
+// CompressedGt returns the torus-based compression of a, half the size of
+// Bytes, with the identity encoded as zeros. It fails unless a is in the
+// cyclotomic subgroup, as the outputs of FExp are.
+func (c *Bn254) CompressedGt(a driver.Gt) ([]byte, error) {
+	gt := &a.(*bn254Gt).GT
+
+	var y bn254.GT
+	if !gt.IsOne() {
+		var norm bn254.GT
+		if !norm.Conjugate(gt).Mul(&norm, gt).IsOne() {
+			return nil, errNotCyclotomic
+		}
+
+		var err error
+		if y.C0, err = gt.CompressTorus(); err != nil {
+			return nil, err
+		}
 	}
 
-	return v
+	raw := y.Bytes()
+	return raw[bn254.SizeOfGT/2:], nil
+}
+
+func (c *Bn254) NewGtFromCompressed(b []byte) (driver.Gt, error) {
+	if len(b) != bn254.SizeOfGT/2 {
+		return nil, fmt.Errorf("invalid compressed Gt length %d", len(b))
+	}
+
+	var y bn254.GT
+	if err := y.SetBytes(append(make([]byte, bn254.SizeOfGT/2), b...)); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	if y.C0.IsZero() {
+		return &bn254Gt{*y.SetOne()}, nil
+	}
+
+	return &bn254Gt{y.C0.DecompressTorus()}, nil
 }
 
 func (c *Bn254) HashToG1(data []byte) driver.G1 {
@@ -379,6 +738,72 @@ func (p *Bn254) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	return &bn254G1{g1}
 }
 
+func (p *Bn254) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	u, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = &common.BaseZr{Int: *u[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return p.MapToG1(els), els
+}
+
+func (p *Bn254) MapToG1(u []driver.Zr) driver.G1 {
+	var acc bn254.G1Jac
+	for _, e := range u {
+		var el fp.Element
+		el.SetBigInt(&e.(*common.BaseZr).Int)
+		q := bn254.MapToG1(el)
+		acc.AddMixed(&q)
+	}
+
+	res := &bn254G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+// MapFieldToG1 maps the base field element b to G1 with MapToG1, the
+// map_to_curve of RFC 9380 followed by clear_cofactor.
+func (p *Bn254) MapFieldToG1(b []byte) (driver.G1, error) {
+	var u fp.Element
+	if err := u.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return &bn254G1{bn254.MapToG1(u)}, nil
+}
+
+// MapFieldToG2 is MapFieldToG1 for G2, whose field elements are made of 2
+// components of the base field.
+func (p *Bn254) MapFieldToG2(b []byte) (driver.G2, error) {
+	if len(b) != 2*fp.Bytes {
+		return nil, fmt.Errorf("invalid field element length [%d]", len(b))
+	}
+
+	// x = A0 + A1*u, laid out from A1 to A0
+	var q bn254.G2Affine
+	for i, a := range []*fp.Element{&q.X.A1, &q.X.A0} {
+		if err := a.SetBytesCanonical(b[i*fp.Bytes : (i+1)*fp.Bytes]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bn254G2{bn254.MapToG2(q.X)}, nil
+}
+
+func (p *Bn254) NewFpFromBytes(b []byte) (driver.Fp, error) {
+	e := &bn254Fp{}
+	if err := e.SetBytesCanonical(b); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 func (p *Bn254) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2, err := bn254.HashToG2(data, domain)
 	if err != nil {
@@ -10,9 +10,11 @@ package gurvy
 import (
 	"errors"
 	"hash"
+	"math/big"
 	"unsafe"
 
 	"github.com/IBM/mathlib/driver/kilic"
+	"github.com/consensys/gnark-crypto/ecc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
 	"github.com/consensys/gnark-crypto/field/pool"
@@ -102,12 +104,12 @@ func ExpandMsgXmd(msg, dst []byte, lenInBytes int, hashFunc func() hash.Hash) ([
 	b1 := h.Sum(nil)
 
 	res := make([]byte, lenInBytes)
-	copy(res[:h.Size()], b1)
+	copy(res, b1)
 
+	strxor := make([]byte, h.Size())
 	for i := 2; i <= ell; i++ {
 		// b_i = H(strxor(b₀, b_(i - 1)) ∥ I2OSP(i, 1) ∥ DST_prime)
 		h.Reset()
-		strxor := make([]byte, h.Size())
 		for j := 0; j < h.Size(); j++ {
 			strxor[j] = b0[j] ^ b1[j]
 		}
@@ -123,7 +125,7 @@ func ExpandMsgXmd(msg, dst []byte, lenInBytes int, hashFunc func() hash.Hash) ([
 		if _, err := h.Write([]byte{sizeDomain}); err != nil {
 			return nil, err
 		}
-		b1 = h.Sum(nil)
+		b1 = h.Sum(b1[:0])
 		copy(res[h.Size()*(i-1):min(h.Size()*i, len(res))], b1)
 	}
 	return res, nil
@@ -158,6 +160,36 @@ func Hash(msg, dst []byte, count int, hashFunc func() hash.Hash) ([]fp.Element,
 	return res, nil
 }
 
+// HashToZrGenericBE hashes msg to an integer modulo q following
+// hash_to_field.
+func HashToZrGenericBE(msg, dst []byte, q *big.Int, hashFunc func() hash.Hash) (*big.Int, error) {
+	v, err := HashToZrsGenericBE(msg, dst, q, 1, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return v[0], nil
+}
+
+// HashToZrsGenericBE hashes msg to count integers modulo q following
+// hash_to_field, with a single expand_message_xmd of count * L bytes.
+func HashToZrsGenericBE(msg, dst []byte, q *big.Int, count int, hashFunc func() hash.Hash) ([]*big.Int, error) {
+	// L = ceil((ceil(log2(q)) + k) / 8), where k is the security parameter = 128
+	L := (q.BitLen() + 128 + 7) / 8
+	pseudoRandomBytes, err := ExpandMsgXmd(msg, dst, count*L, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*big.Int, count)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(pseudoRandomBytes[i*L : (i+1)*L])
+		res[i].Mod(res[i], q)
+	}
+
+	return res, nil
+}
+
 func HashToG1GenericBESwu(msg, dst []byte, hashFunc func() hash.Hash) (bls12381.G1Affine, error) {
 	u, err := Hash(msg, dst, 2*1, hashFunc)
 	if err != nil {
@@ -189,3 +221,16 @@ func HashToG1GenericBESwu(msg, dst []byte, hashFunc func() hash.Hash) (bls12381.
 	res := toGurvyAffine(&Q1)
 	return *res, nil
 }
+
+// maxMultiExpTasks is the most goroutines gnark's MultiExp accepts.
+const maxMultiExpTasks = 1024
+
+// multiExpConfig caps gnark's MultiExp at tasks goroutines; NbTasks left
+// at zero picks gnark's default.
+func multiExpConfig(tasks int) ecc.MultiExpConfig {
+	if tasks > maxMultiExpTasks {
+		tasks = maxMultiExpTasks
+	}
+
+	return ecc.MultiExpConfig{NbTasks: tasks}
+}
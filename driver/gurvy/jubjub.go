@@ -0,0 +1,387 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gurvy
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
+)
+
+var jubjub = twistededwards.GetEdwardsCurve()
+
+/*********************************************************************/
+
+type jubjubG1 struct {
+	twistededwards.PointAffine
+}
+
+func (g *jubjubG1) Clone(a driver.G1) {
+	g.PointAffine.Set(&a.(*jubjubG1).PointAffine)
+}
+
+func (g *jubjubG1) Copy() driver.G1 {
+	c := &jubjubG1{}
+	c.Set(&g.PointAffine)
+	return c
+}
+
+func (g *jubjubG1) Add(a driver.G1) {
+	g.PointAffine.Add(&g.PointAffine, &a.(*jubjubG1).PointAffine)
+}
+
+func (g *jubjubG1) Mul(a driver.Zr) driver.G1 {
+	ret := &jubjubG1{}
+	ret.PointAffine.ScalarMultiplication(&g.PointAffine, &a.(*common.BaseZr).Int)
+
+	return ret
+}
+
+func (g *jubjubG1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *jubjubG1) Equals(a driver.G1) bool {
+	return g.PointAffine.Equal(&a.(*jubjubG1).PointAffine)
+}
+
+// Bytes returns the encoding repr_J of the Zcash protocol specification:
+// v in little-endian order, with the parity of u in the most significant bit.
+func (g *jubjubG1) Bytes() []byte {
+	u := g.X.Bytes()
+	v := g.Y.Bytes()
+
+	b := make([]byte, fr.Bytes)
+	for i := range v {
+		b[i] = v[fr.Bytes-1-i]
+	}
+	b[fr.Bytes-1] |= u[fr.Bytes-1] & 1 << 7
+
+	return b
+}
+
+// Compressed returns the same encoding as Bytes, which is already compressed
+func (g *jubjubG1) Compressed() []byte {
+	return g.Bytes()
+}
+
+func (g *jubjubG1) Sub(a driver.G1) {
+	neg := &jubjubG1{}
+	neg.PointAffine.Neg(&a.(*jubjubG1).PointAffine)
+	g.PointAffine.Add(&g.PointAffine, &neg.PointAffine)
+}
+
+func (g *jubjubG1) IsInfinity() bool {
+	return g.PointAffine.IsZero()
+}
+
+func (g *jubjubG1) IsGenerator() bool {
+	return g.PointAffine.Equal(&jubjub.Base)
+}
+
+func (g *jubjubG1) String() string {
+	return "(" + g.X.BigInt(new(big.Int)).String() + "," + g.Y.BigInt(new(big.Int)).String() + ")"
+}
+
+func (g *jubjubG1) Neg() {
+	g.PointAffine.Neg(&g.PointAffine)
+}
+
+func (g *jubjubG1) inSubgroup() bool {
+	var p twistededwards.PointAffine
+	p.ScalarMultiplication(&g.PointAffine, &jubjub.Order)
+	return p.IsZero()
+}
+
+/*********************************************************************/
+
+func NewJubjub() *Jubjub {
+	return &Jubjub{common.CurveBase{Modulus: jubjub.Order}}
+}
+
+// Jubjub is the twisted Edwards curve defined over the scalar field of
+// BLS12-381, used to build circuits over the latter. It only provides the
+// prime order subgroup G1: G2, Gt and pairing operations panic with
+// driver.ErrUnsupported.
+type Jubjub struct {
+	common.CurveBase
+}
+
+func (c *Jubjub) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		G1Cofactor:         jubjub.Cofactor.BigInt(new(big.Int)),
+		BaseFieldModulus:   fr.Modulus(),
+		ScalarFieldModulus: new(big.Int).Set(&jubjub.Order),
+	}
+}
+
+func (c *Jubjub) SupportsPairing() bool {
+	return false
+}
+
+// BaseFieldModulus returns the order of the scalar field of BLS12-381
+func (c *Jubjub) BaseFieldModulus() *big.Int {
+	return fr.Modulus()
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fr, the base field of
+// the curve.
+func (c *Jubjub) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*jubjubG1).PointAffine, &b.(*jubjubG1).PointAffine
+	rv := &jubjubG1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+func (c *Jubjub) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) G1Coordinates(g driver.G1) (u, v *big.Int) {
+	p := g.(*jubjubG1)
+	return p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int))
+}
+
+func (c *Jubjub) NewG1FromCoordinates(u, v *big.Int) (driver.G1, error) {
+	if u.Sign() < 0 || u.Cmp(fr.Modulus()) >= 0 || v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+		return nil, errors.New("invalid coordinates [not in the base field]")
+	}
+
+	g := &jubjubG1{}
+	g.X.SetBigInt(u)
+	g.Y.SetBigInt(v)
+	if !g.IsOnCurve() {
+		return nil, fmt.Errorf("invalid coordinates [%w]", driver.ErrNotOnCurve)
+	}
+	if !g.inSubgroup() {
+		return nil, fmt.Errorf("invalid coordinates [%w]", driver.ErrNotInSubgroup)
+	}
+
+	return g, nil
+}
+
+func (c *Jubjub) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) FExp(a driver.Gt) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) GenG1() driver.G1 {
+	g := &jubjubG1{}
+	g.Set(&jubjub.Base)
+	return g
+}
+
+func (c *Jubjub) GenG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Jubjub) GenGt() driver.Gt {
+	return common.UnsupportedGt{}
+}
+
+func (c *Jubjub) CoordinateByteSize() int {
+	return fr.Bytes
+}
+
+func (c *Jubjub) G1ByteSize() int {
+	return fr.Bytes
+}
+
+func (c *Jubjub) CompressedG1ByteSize() int {
+	return fr.Bytes
+}
+
+func (c *Jubjub) G2ByteSize() int {
+	return 0
+}
+
+func (c *Jubjub) CompressedG2ByteSize() int {
+	return 0
+}
+
+func (c *Jubjub) GtByteSize() int {
+	return 0
+}
+
+func (c *Jubjub) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Jubjub) NewG1() driver.G1 {
+	return c.InfinityG1()
+}
+
+func (c *Jubjub) NewG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Jubjub) SumG1(points []driver.G1) driver.G1 {
+	res := c.InfinityG1().(*jubjubG1)
+
+	var acc twistededwards.PointProj
+	acc.FromAffine(&res.PointAffine)
+	for _, p := range points {
+		acc.MixedAdd(&acc, &p.(*jubjubG1).PointAffine)
+	}
+
+	res.PointAffine.FromProj(&acc)
+	return res
+}
+
+func (c *Jubjub) SumG2(points []driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) InfinityG1() driver.G1 {
+	g := &jubjubG1{}
+	g.Y.SetOne()
+	return g
+}
+
+func (c *Jubjub) InfinityG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+// NewG1FromBytes implements abst_J of the Zcash protocol specification,
+// rejecting non-canonical encodings as required since ZIP 216, and points
+// outside of the prime order subgroup.
+func (c *Jubjub) NewG1FromBytes(b []byte) (driver.G1, error) {
+	g, err := jubjubFromBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("set bytes failed [%w]", err)
+	}
+	if !g.inSubgroup() {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	return g, nil
+}
+
+func (c *Jubjub) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	return c.NewG1FromBytes(b)
+}
+
+func jubjubFromBytes(b []byte) (*jubjubG1, error) {
+	if len(b) != fr.Bytes {
+		return nil, fmt.Errorf("invalid length %d", len(b))
+	}
+
+	be := make([]byte, fr.Bytes)
+	for i := range b {
+		be[i] = b[fr.Bytes-1-i]
+	}
+	sign := be[0] >> 7
+	be[0] &= 0x7f
+
+	g := &jubjubG1{}
+	if err := g.Y.SetBytesCanonical(be); err != nil {
+		return nil, err
+	}
+
+	// -u^2 + v^2 = 1 + d * u^2 * v^2, i.e. u^2 = (v^2 - 1) / (d * v^2 + 1)
+	var num, den, one fr.Element
+	one.SetOne()
+	num.Square(&g.Y)
+	den.Mul(&num, &jubjub.D).Add(&den, &one)
+	num.Sub(&num, &one)
+	num.Div(&num, &den)
+	if g.X.Sqrt(&num) == nil {
+		return nil, driver.ErrNotOnCurve
+	}
+
+	if g.X.IsZero() && sign == 1 {
+		return nil, fmt.Errorf("non-canonical encoding")
+	}
+	u := g.X.Bytes()
+	if u[fr.Bytes-1]&1 != sign {
+		g.X.Neg(&g.X)
+	}
+
+	return g, nil
+}
+
+func (c *Jubjub) NewG2FromBytes(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Jubjub) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Jubjub) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Jubjub) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+// HashToG1WithDomain follows the structure of FindGroupHash^J* of the Zcash
+// protocol specification, with expand_message_xmd over SHA-256 in place of
+// BLAKE2s: the i-th candidate is the decoding of the hash of data || i,
+// multiplied by the cofactor. It does not run in constant time.
+func (c *Jubjub) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	msg := make([]byte, len(data)+1)
+	copy(msg, data)
+
+	for i := 0; i < 256; i++ {
+		msg[len(data)] = byte(i)
+
+		b, err := ExpandMsgXmd(msg, domain, fr.Bytes, sha256.New)
+		if err != nil {
+			panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+		}
+
+		g, err := jubjubFromBytes(b)
+		if err != nil {
+			continue
+		}
+
+		g.ScalarMultiplication(&g.PointAffine, jubjub.Cofactor.BigInt(new(big.Int)))
+		if !g.IsZero() {
+			return g
+		}
+	}
+
+	panic("HashToG1 failed [no valid candidate]")
+}
+
+func (c *Jubjub) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) MapToG1(u []driver.Zr) driver.G1 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) HashToG2(data []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Jubjub) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
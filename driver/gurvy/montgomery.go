@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gurvy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// montgomeryBytes lays out the limbs of a gnark field element, least
+// significant first and in Montgomery form, as big-endian bytes.
+func montgomeryBytes(limbs []uint64) []byte {
+	b := make([]byte, 8*len(limbs))
+	for i, l := range limbs {
+		binary.BigEndian.PutUint64(b[len(b)-8*(i+1):], l)
+	}
+
+	return b
+}
+
+// setMontgomery is the inverse of montgomeryBytes; it fails unless b holds
+// exactly the limbs of a value smaller than q.
+func setMontgomery(limbs []uint64, b []byte, q *big.Int) error {
+	if len(b) != 8*len(limbs) {
+		return fmt.Errorf("invalid length, expected %d bytes, got %d", 8*len(limbs), len(b))
+	}
+
+	if new(big.Int).SetBytes(b).Cmp(q) >= 0 {
+		return fmt.Errorf("value is not smaller than the modulus")
+	}
+
+	for i := range limbs {
+		limbs[i] = binary.BigEndian.Uint64(b[len(b)-8*(i+1):])
+	}
+
+	return nil
+}
@@ -0,0 +1,444 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gurvy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+// SEC1 point encodings
+const (
+	sec1Infinity     = 0x00
+	sec1EvenY        = 0x02
+	sec1OddY         = 0x03
+	sec1Uncompressed = 0x04
+)
+
+/*********************************************************************/
+
+type secp256k1G1 struct {
+	secp256k1.G1Affine
+}
+
+func (g *secp256k1G1) Clone(a driver.G1) {
+	g.G1Affine.Set(&a.(*secp256k1G1).G1Affine)
+}
+
+func (e *secp256k1G1) Copy() driver.G1 {
+	c := &secp256k1G1{}
+	c.Set(&e.G1Affine)
+	return c
+}
+
+func (g *secp256k1G1) Add(a driver.G1) {
+	j := secp256k1.G1Jac{}
+	j.FromAffine(&g.G1Affine)
+	j.AddMixed(&a.(*secp256k1G1).G1Affine)
+	g.G1Affine.FromJacobian(&j)
+}
+
+func (g *secp256k1G1) Mul(a driver.Zr) driver.G1 {
+	ret := &secp256k1G1{}
+	ret.G1Affine.ScalarMultiplication(&g.G1Affine, &a.(*common.BaseZr).Int)
+
+	return ret
+}
+
+func (g *secp256k1G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *secp256k1G1) Equals(a driver.G1) bool {
+	return g.G1Affine.Equal(&a.(*secp256k1G1).G1Affine)
+}
+
+// Bytes returns the SEC1 uncompressed encoding of g
+func (g *secp256k1G1) Bytes() []byte {
+	if g.G1Affine.IsInfinity() {
+		return []byte{sec1Infinity}
+	}
+
+	raw := g.G1Affine.RawBytes()
+	return append([]byte{sec1Uncompressed}, raw[:]...)
+}
+
+// Compressed returns the SEC1 compressed encoding of g
+func (g *secp256k1G1) Compressed() []byte {
+	if g.G1Affine.IsInfinity() {
+		return []byte{sec1Infinity}
+	}
+
+	x := g.G1Affine.X.Bytes()
+	y := g.G1Affine.Y.Bytes()
+	return append([]byte{sec1EvenY | y[fp.Bytes-1]&1}, x[:]...)
+}
+
+func (g *secp256k1G1) Sub(a driver.G1) {
+	j, k := secp256k1.G1Jac{}, secp256k1.G1Jac{}
+	j.FromAffine(&g.G1Affine)
+	k.FromAffine(&a.(*secp256k1G1).G1Affine)
+	j.SubAssign(&k)
+	g.G1Affine.FromJacobian(&j)
+}
+
+func (g *secp256k1G1) IsInfinity() bool {
+	return g.G1Affine.IsInfinity()
+}
+
+func (g *secp256k1G1) IsGenerator() bool {
+	_, g1 := secp256k1.Generators()
+	return g.G1Affine.Equal(&g1)
+}
+
+func (g *secp256k1G1) String() string {
+	return "(" + g.X.String() + "," + g.Y.String() + ")"
+}
+
+func (g *secp256k1G1) Neg() {
+	g.G1Affine.Neg(&g.G1Affine)
+}
+
+/*********************************************************************/
+
+func NewSecp256k1() *Secp256k1 {
+	return &Secp256k1{common.CurveBase{Modulus: *fr.Modulus()}}
+}
+
+// Secp256k1 only provides the prime order group G1: G2, Gt and pairing
+// operations panic with driver.ErrUnsupported.
+type Secp256k1 struct {
+	common.CurveBase
+}
+
+// SelectG1 returns a copy of a if cond is 1 and of b if it is 0, selecting
+// each coordinate with the constant-time Select of fp; the point at
+// infinity is (0, 0) like any other pair of coordinates.
+func (c *Secp256k1) SelectG1(cond int, a, b driver.G1) driver.G1 {
+	p, q := &a.(*secp256k1G1).G1Affine, &b.(*secp256k1G1).G1Affine
+	rv := &secp256k1G1{}
+	rv.X.Select(cond, &q.X, &p.X)
+	rv.Y.Select(cond, &q.Y, &p.Y)
+	return rv
+}
+
+func (c *Secp256k1) SelectG2(cond int, a, b driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		G1Cofactor:         big.NewInt(1),
+		BaseFieldModulus:   fp.Modulus(),
+		ScalarFieldModulus: fr.Modulus(),
+
+		InfinityG1ByteSize:           1,
+		CompressedInfinityG1ByteSize: 1,
+	}
+}
+
+func (c *Secp256k1) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModAddMul(a1, b1, m)
+	}
+
+	var sum, a, b fr.Element
+	for i := range a1 {
+		a.SetBigInt(&a1[i].(*common.BaseZr).Int)
+		b.SetBigInt(&b1[i].(*common.BaseZr).Int)
+		sum.Add(&sum, a.Mul(&a, &b))
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	sum.BigInt(&res.Int)
+	return res
+}
+
+func (c *Secp256k1) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if m.(*common.BaseZr).Int.Cmp(&c.Modulus) != 0 {
+		return c.CurveBase.ModMul3(a1, b1, c1, m)
+	}
+
+	var a, b, e fr.Element
+	a.SetBigInt(&a1.(*common.BaseZr).Int)
+	b.SetBigInt(&b1.(*common.BaseZr).Int)
+	e.SetBigInt(&c1.(*common.BaseZr).Int)
+	a.Mul(&a, &b).Mul(&a, &e)
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	a.BigInt(&res.Int)
+	return res
+}
+
+func (c *Secp256k1) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
+}
+
+func (c *Secp256k1) SupportsPairing() bool {
+	return false
+}
+
+func (c *Secp256k1) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) FExp(a driver.Gt) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) GenG1() driver.G1 {
+	_, g1 := secp256k1.Generators()
+	return &secp256k1G1{g1}
+}
+
+func (c *Secp256k1) GenG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Secp256k1) GenGt() driver.Gt {
+	return common.UnsupportedGt{}
+}
+
+func (c *Secp256k1) CoordinateByteSize() int {
+	return fp.Bytes
+}
+
+func (c *Secp256k1) G1ByteSize() int {
+	return 1 + 2*fp.Bytes
+}
+
+func (c *Secp256k1) CompressedG1ByteSize() int {
+	return 1 + fp.Bytes
+}
+
+func (c *Secp256k1) G2ByteSize() int {
+	return 0
+}
+
+func (c *Secp256k1) CompressedG2ByteSize() int {
+	return 0
+}
+
+func (c *Secp256k1) GtByteSize() int {
+	return 0
+}
+
+func (c *Secp256k1) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *Secp256k1) NewG1() driver.G1 {
+	return &secp256k1G1{}
+}
+
+func (c *Secp256k1) NewG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Secp256k1) MulMany(base driver.G1, scalars []driver.Zr) []driver.G1 {
+	frs := make([]fr.Element, len(scalars))
+	for i, s := range scalars {
+		frs[i].SetBigInt(&s.(*common.BaseZr).Int)
+	}
+
+	points := secp256k1.BatchScalarMultiplicationG1(&base.(*secp256k1G1).G1Affine, frs)
+
+	res := make([]driver.G1, len(points))
+	for i := range points {
+		res[i] = &secp256k1G1{points[i]}
+	}
+
+	return res
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]points[i], computed with
+// gnark's multi-exponentiation.
+func (c *Secp256k1) MultiScalarMul(points []driver.G1, scalars []driver.Zr, tasks int) driver.G1 {
+	ps := make([]secp256k1.G1Affine, len(points))
+	frs := make([]fr.Element, len(scalars))
+	for i := range points {
+		ps[i] = points[i].(*secp256k1G1).G1Affine
+		frs[i].SetBigInt(&scalars[i].(*common.BaseZr).Int)
+	}
+
+	res := &secp256k1G1{}
+	if _, err := res.G1Affine.MultiExp(ps, frs, multiExpConfig(tasks)); err != nil {
+		panic(fmt.Sprintf("MultiScalarMul failed [%s]", err.Error()))
+	}
+
+	return res
+}
+
+func (c *Secp256k1) ZrMontgomeryBytes(a driver.Zr) []byte {
+	var e fr.Element
+	e.SetBigInt(&a.(*common.BaseZr).Int)
+	return montgomeryBytes(e[:])
+}
+
+func (c *Secp256k1) NewZrFromMontgomery(b []byte) (driver.Zr, error) {
+	var e fr.Element
+	if err := setMontgomery(e[:], b, &c.Modulus); err != nil {
+		return nil, err
+	}
+
+	res := &common.BaseZr{Modulus: c.Modulus}
+	e.BigInt(&res.Int)
+	return res, nil
+}
+
+func (c *Secp256k1) SumG1(points []driver.G1) driver.G1 {
+	var acc secp256k1.G1Jac
+	for _, p := range points {
+		acc.AddMixed(&p.(*secp256k1G1).G1Affine)
+	}
+
+	res := &secp256k1G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Secp256k1) SumG2(points []driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) InfinityG1() driver.G1 {
+	return &secp256k1G1{}
+}
+
+func (c *Secp256k1) InfinityG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Secp256k1) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) == 1 && b[0] == sec1Infinity {
+		return &secp256k1G1{}, nil
+	}
+
+	if len(b) != c.G1ByteSize() || b[0] != sec1Uncompressed {
+		return nil, errors.New("set bytes failed [invalid SEC1 uncompressed encoding]")
+	}
+
+	v := &secp256k1G1{}
+	_, err := v.G1Affine.SetBytes(b[1:])
+	if err != nil {
+		return nil, setBytesFailed(err, v.G1Affine.IsOnCurve())
+	}
+
+	return v, nil
+}
+
+func (c *Secp256k1) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	if len(b) == 1 && b[0] == sec1Infinity {
+		return &secp256k1G1{}, nil
+	}
+
+	if len(b) != c.CompressedG1ByteSize() || (b[0] != sec1EvenY && b[0] != sec1OddY) {
+		return nil, errors.New("set bytes failed [invalid SEC1 compressed encoding]")
+	}
+
+	v := &secp256k1G1{}
+	if err := v.G1Affine.X.SetBytesCanonical(b[1:]); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	// y^2 = x^3 + 7
+	var rhs, seven fp.Element
+	seven.SetUint64(7)
+	rhs.Square(&v.G1Affine.X).Mul(&rhs, &v.G1Affine.X).Add(&rhs, &seven)
+	if v.G1Affine.Y.Sqrt(&rhs) == nil {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+
+	y := v.G1Affine.Y.Bytes()
+	if y[fp.Bytes-1]&1 != b[0]&1 {
+		v.G1Affine.Y.Neg(&v.G1Affine.Y)
+	}
+
+	return v, nil
+}
+
+func (c *Secp256k1) NewG2FromBytes(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Secp256k1) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Secp256k1) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	return nil, driver.ErrUnsupported
+}
+
+// HashToG1 hashes with expand_message_xmd over SHA-256 followed by the
+// Shallue-van de Woestijne map of RFC 9380, as implemented by gnark-crypto.
+// secp256k1 has a = 0, which rules out the plain SSWU map: the points differ
+// from those of the secp256k1_XMD:SHA-256_SSWU_RO_ suite, which goes through
+// a 3-isogenous curve.
+func (c *Secp256k1) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+func (c *Secp256k1) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g1, err := secp256k1.HashToG1(data, domain)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	return &secp256k1G1{g1}
+}
+
+func (c *Secp256k1) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	u, err := fp.Hash(data, domain, 2)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = &common.BaseZr{Int: *u[i].BigInt(new(big.Int)), Modulus: *fp.Modulus()}
+	}
+
+	return c.MapToG1(els), els
+}
+
+func (c *Secp256k1) MapToG1(u []driver.Zr) driver.G1 {
+	var acc secp256k1.G1Jac
+	for _, e := range u {
+		var el fp.Element
+		el.SetBigInt(&e.(*common.BaseZr).Int)
+		q := secp256k1.MapToG1(el)
+		acc.AddMixed(&q)
+	}
+
+	res := &secp256k1G1{}
+	res.G1Affine.FromJacobian(&acc)
+	return res
+}
+
+func (c *Secp256k1) HashToG2(data []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Secp256k1) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package kilic
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"hash"
 	"math/big"
 
 	"github.com/IBM/mathlib/driver"
@@ -15,6 +17,21 @@ import (
 	bls12381 "github.com/kilic/bls12-381"
 )
 
+// g1Gen and g2Gen are compared against by IsGenerator
+var (
+	g1Gen = bls12381.NewG1().One()
+	g2Gen = bls12381.NewG2().One()
+)
+
+// frModulus is the group order r
+var frModulus = bls12381.NewG1().Q()
+
+// toFr returns a modulo r as the Fr of the library, whose MulScalar splits
+// it for the endomorphism without the big.Int arithmetic of MulScalarBig.
+func toFr(a driver.Zr) *bls12381.Fr {
+	return a.(*zr).reduced(bls12381.NewFr())
+}
+
 /*********************************************************************/
 
 type bls12_381G1 struct {
@@ -40,7 +57,7 @@ func (g *bls12_381G1) Mul(a driver.Zr) driver.G1 {
 	g1 := bls12381.NewG1()
 	res := g1.New()
 
-	g1.MulScalarBig(res, &g.PointG1, &a.(*common.BaseZr).Int)
+	g1.MulScalar(res, &g.PointG1, toFr(a))
 
 	return &bls12_381G1{
 		G1:      *g1,
@@ -80,6 +97,14 @@ func (g *bls12_381G1) IsInfinity() bool {
 	return g.G1.IsZero(&g.PointG1)
 }
 
+func (g *bls12_381G1) IsGenerator() bool {
+	return g.G1.Equal(&g.PointG1, g1Gen)
+}
+
+func (g *bls12_381G1) IsOnCurve() bool {
+	return g.G1.IsOnCurve(&g.PointG1)
+}
+
 func (g *bls12_381G1) String() string {
 	gb := g.Bytes()
 	x := new(big.Int).SetBytes(gb[:len(gb)/2])
@@ -115,7 +140,7 @@ func (g *bls12_381G2) Mul(a driver.Zr) driver.G2 {
 	g2 := bls12381.NewG2()
 	res := g2.New()
 
-	g2.MulScalarBig(res, &g.PointG2, &a.(*common.BaseZr).Int)
+	g2.MulScalar(res, &g.PointG2, toFr(a))
 
 	return &bls12_381G2{
 		G2:      *g2,
@@ -158,6 +183,14 @@ func (g *bls12_381G2) Equals(a driver.G2) bool {
 	return g2.Equal(&a.(*bls12_381G2).PointG2, &g.PointG2)
 }
 
+func (g *bls12_381G2) IsGenerator() bool {
+	return g.G2.Equal(&g.PointG2, g2Gen)
+}
+
+func (g *bls12_381G2) IsOnCurve() bool {
+	return g.G2.IsOnCurve(&g.PointG2)
+}
+
 /*********************************************************************/
 
 type bls12_381Gt struct {
@@ -169,7 +202,7 @@ type bls12_381Gt struct {
 func (g *bls12_381Gt) Exp(x driver.Zr) driver.Gt {
 	gt := bls12381.NewGT()
 	res := gt.New()
-	gt.Exp(res, &g.E, &x.(*common.BaseZr).Int)
+	gt.Exp(res, &g.E, x.(*zr).value(new(big.Int)))
 
 	return &bls12_381Gt{
 		E:             *res,
@@ -189,6 +222,11 @@ func (g *bls12_381Gt) Inverse() {
 	g.GT.Inverse(&g.E, &g.E)
 }
 
+// Conjugate falls back to Inverse, kilic does not export the conjugation.
+func (g *bls12_381Gt) Conjugate() {
+	g.Inverse()
+}
+
 func (g *bls12_381Gt) Mul(a driver.Gt) {
 	if !g.GTInitialised {
 		g.GT = *bls12381.NewGT()
@@ -215,8 +253,15 @@ func (g *bls12_381Gt) Bytes() []byte {
 
 /*********************************************************************/
 
+var fpModulus big.Int // p stored as big.Int
+func init() {
+	fpModulus.SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+}
+
 func NewBls12_381() *Bls12_381 {
-	return &Bls12_381{common.CurveBase{Modulus: *bls12381.NewG1().Q()}}
+	c := &Bls12_381{CurveBase: common.CurveBase{Modulus: *bls12381.NewG1().Q()}}
+	c.genGt = c.FExp(c.Pairing(c.GenG2(), c.GenG1())).(*bls12_381Gt).E
+	return c
 }
 
 func NewBls12_381BBS() *Bls12_381BBS {
@@ -225,6 +270,13 @@ func NewBls12_381BBS() *Bls12_381BBS {
 
 type Bls12_381 struct {
 	common.CurveBase
+
+	// genGt is computed once, GenGt returns copies of it
+	genGt bls12381.E
+}
+
+func (c *Bls12_381) Info() *driver.CurveInfo {
+	return common.BLS12_381Info()
 }
 
 type Bls12_381BBS struct {
@@ -254,6 +306,19 @@ func (c *Bls12_381) FExp(a driver.Gt) driver.Gt {
 	return a
 }
 
+// MultiPairing feeds all the pairs to a single engine, which shares their
+// Miller loop and final exponentiation.
+func (c *Bls12_381) MultiPairing(p2 []driver.G2, p1 []driver.G1) driver.Gt {
+	bls := bls12381.NewEngine()
+	for i := range p1 {
+		bls.AddPair(&p1[i].(*bls12_381G1).PointG1, &p2[i].(*bls12_381G2).PointG2)
+	}
+
+	return &bls12_381Gt{
+		E: *bls.Result(),
+	}
+}
+
 func (c *Bls12_381) GenG1() driver.G1 {
 	g := bls12381.NewG1()
 	g1 := g.One()
@@ -273,11 +338,7 @@ func (c *Bls12_381) GenG2() driver.G2 {
 }
 
 func (c *Bls12_381) GenGt() driver.Gt {
-	g1 := c.GenG1()
-	g2 := c.GenG2()
-	gengt := c.Pairing(g2, g1)
-	gengt = c.FExp(gengt)
-	return gengt
+	return &bls12_381Gt{E: c.genGt}
 }
 
 func (c *Bls12_381) CoordinateByteSize() int {
@@ -300,6 +361,10 @@ func (c *Bls12_381) CompressedG2ByteSize() int {
 	return 2 * fpByteSize
 }
 
+func (c *Bls12_381) GtByteSize() int {
+	return 12 * fpByteSize
+}
+
 func (c *Bls12_381) ScalarByteSize() int {
 	return common.ScalarByteSize
 }
@@ -312,70 +377,124 @@ func (c *Bls12_381) NewG2() driver.G2 {
 	return &bls12_381G2{G2: *bls12381.NewG2()}
 }
 
-func (c *Bls12_381) NewG1FromBytes(b []byte) driver.G1 {
+func (c *Bls12_381) InfinityG1() driver.G1 {
+	g := bls12381.NewG1()
+	return &bls12_381G1{
+		G1:      *g,
+		PointG1: *g.Zero(),
+	}
+}
+
+func (c *Bls12_381) InfinityG2() driver.G2 {
+	g := bls12381.NewG2()
+	return &bls12_381G2{
+		G2:      *g,
+		PointG2: *g.Zero(),
+	}
+}
+
+// SumG1 accumulates in a copy of the first point: the points of the kilic
+// library are projective already, so there is no conversion to save.
+func (c *Bls12_381) SumG1(points []driver.G1) driver.G1 {
+	if len(points) == 0 {
+		return c.NewG1()
+	}
+
+	acc := points[0].Copy().(*bls12_381G1)
+	for _, p := range points[1:] {
+		acc.G1.Add(&acc.PointG1, &acc.PointG1, &p.(*bls12_381G1).PointG1)
+	}
+
+	return acc
+}
+
+func (c *Bls12_381) SumG2(points []driver.G2) driver.G2 {
+	acc := c.InfinityG2()
+	for _, q := range points {
+		acc.Add(q)
+	}
+
+	return acc
+}
+
+// setBytesFailed returns the error of a decoder for which kilic returned
+// err, wrapping driver.ErrNotOnCurve or driver.ErrNotInSubgroup if that is
+// the cause, which kilic only tells in the message.
+func setBytesFailed(err error) error {
+	switch err.Error() {
+	case "point is not on curve":
+		return fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	case "point is not on correct subgroup":
+		return fmt.Errorf("set bytes failed [%w]", driver.ErrNotInSubgroup)
+	}
+
+	return fmt.Errorf("set bytes failed [%s]", err.Error())
+}
+
+func (c *Bls12_381) NewG1FromBytes(b []byte) (driver.G1, error) {
 	g1 := bls12381.NewG1()
 	p, err := g1.FromUncompressed(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err)
 	}
 
 	return &bls12_381G1{
 		PointG1: *p,
 		G1:      *g1,
-	}
+	}, nil
 }
 
-func (c *Bls12_381) NewG2FromBytes(b []byte) driver.G2 {
+func (c *Bls12_381) NewG2FromBytes(b []byte) (driver.G2, error) {
 	g2 := bls12381.NewG2()
 	p, err := g2.FromUncompressed(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err)
 	}
 
 	return &bls12_381G2{
 		G2:      *g2,
 		PointG2: *p,
-	}
+	}, nil
 }
 
-func (c *Bls12_381) NewG1FromCompressed(b []byte) driver.G1 {
+func (c *Bls12_381) NewG1FromCompressed(b []byte) (driver.G1, error) {
 	g1 := bls12381.NewG1()
 	p, err := g1.FromCompressed(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err)
 	}
 
 	return &bls12_381G1{
 		PointG1: *p,
 		G1:      *g1,
-	}
+	}, nil
 }
 
-func (c *Bls12_381) NewG2FromCompressed(b []byte) driver.G2 {
+func (c *Bls12_381) NewG2FromCompressed(b []byte) (driver.G2, error) {
 	g2 := bls12381.NewG2()
 	p, err := g2.FromCompressed(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, setBytesFailed(err)
 	}
 
 	return &bls12_381G2{
 		G2:      *g2,
 		PointG2: *p,
-	}
+	}, nil
 }
 
-func (c *Bls12_381) NewGtFromBytes(b []byte) driver.Gt {
+func (c *Bls12_381) NewGtFromBytes(b []byte) (driver.Gt, error) {
 	gt := bls12381.NewGT()
 	p, err := gt.FromBytes(b)
 	if err != nil {
-		panic(fmt.Sprintf("set bytes failed [%s]", err.Error()))
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
 	}
 
 	return &bls12_381Gt{
 		E:             *p,
 		GT:            *gt,
 		GTInitialised: true,
-	}
+	}, nil
 }
 
 func (c *Bls12_381) HashToG1(data []byte) driver.G1 {
@@ -417,6 +536,70 @@ func (c *Bls12_381) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	}
 }
 
+func (c *Bls12_381) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	// hash_to_field from the BLS12381G1_XMD:SHA-256_SSWU_RO_ suite
+	uniform, err := expandMsgXMD(sha256.New, data, domain, 2*64)
+	if err != nil {
+		panic(fmt.Sprintf("HashToCurve failed [%s]", err.Error()))
+	}
+
+	els := make([]driver.Zr, 2)
+	for i := range els {
+		el := &common.BaseZr{Modulus: fpModulus}
+		el.Int.SetBytes(uniform[i*64 : (i+1)*64])
+		el.Int.Mod(&el.Int, &fpModulus)
+		els[i] = el
+	}
+
+	return c.MapToG1(els), els
+}
+
+func (c *Bls12_381) MapToG1(u []driver.Zr) driver.G1 {
+	g1 := bls12381.NewG1()
+	acc := g1.Zero()
+	for _, e := range u {
+		q, err := g1.MapToCurve(new(big.Int).Mod(&e.(*common.BaseZr).Int, &fpModulus).FillBytes(make([]byte, fpByteSize)))
+		if err != nil {
+			panic(fmt.Sprintf("MapToCurve failed [%s]", err.Error()))
+		}
+		g1.Add(acc, acc, q)
+	}
+
+	return &bls12_381G1{
+		PointG1: *g1.Affine(acc),
+		G1:      *g1,
+	}
+}
+
+// MapFieldToG1 maps the base field element b to G1 with the map_to_curve of
+// RFC 9380 followed by clear_cofactor.
+func (c *Bls12_381) MapFieldToG1(b []byte) (driver.G1, error) {
+	g1 := bls12381.NewG1()
+	p, err := g1.MapToCurve(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bls12_381G1{
+		PointG1: *p,
+		G1:      *g1,
+	}, nil
+}
+
+// MapFieldToG2 is MapFieldToG1 for G2, with b = c1 || c0.
+func (c *Bls12_381) MapFieldToG2(b []byte) (driver.G2, error) {
+	g2 := bls12381.NewG2()
+	p, err := g2.MapToCurve(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bls12_381G2{
+		PointG2: *p,
+		G2:      *g2,
+	}, nil
+}
+
 func (c *Bls12_381) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2 := bls12381.NewG2()
 	p, err := g2.HashToCurve(data, domain)
@@ -467,6 +650,45 @@ func (c *Bls12_381BBS) HashToG1WithDomain(data, domain []byte) driver.G1 {
 	}
 }
 
+func (c *Bls12_381BBS) HashToZrWithDomain(data, domain []byte) driver.Zr {
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
+
+	v, err := HashToZrGenericBE(data, domain, &c.Modulus, hashFunc)
+	if err != nil {
+		panic(fmt.Sprintf("HashToZr failed [%s]", err.Error()))
+	}
+
+	return newZr(v)
+}
+
+func (c *Bls12_381BBS) HashToZrBatch(data, domain []byte, count int) []driver.Zr {
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
+
+	vs, err := HashToZrsGenericBE(data, domain, &c.Modulus, count, hashFunc)
+	if err != nil {
+		panic(fmt.Sprintf("HashToZr failed [%s]", err.Error()))
+	}
+
+	res := make([]driver.Zr, count)
+	for i, v := range vs {
+		res[i] = newZr(v)
+	}
+
+	return res
+}
+
+func (c *Bls12_381BBS) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	panic("HashToG1WithU is not available for this curve")
+}
+
+func (c *Bls12_381BBS) MapToG1(u []driver.Zr) driver.G1 {
+	panic("MapToG1 is not available for this curve")
+}
+
 func (c *Bls12_381BBS) HashToG2WithDomain(data, domain []byte) driver.G2 {
 	g2 := bls12381.NewG2()
 	p, err := g2.HashToCurve(data, domain)
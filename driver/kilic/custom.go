@@ -10,6 +10,8 @@ package kilic
 import (
 	"errors"
 	"hash"
+	"math/big"
+	"sync"
 	"unsafe"
 	_ "unsafe"
 
@@ -194,12 +196,20 @@ func feAtPos(pos int, p *bls12381.PointG1) *Fe {
 	return (*Fe)(unsafe.Pointer(&(p[pos])))
 }
 
-func HashToG1GenericBESwu(data, domain []byte) (*bls12381.PointG1, error) {
-	hashFunc := func() hash.Hash {
+// blake2bPool recycles the blake2b-512 hashers used by HashToG1GenericBESwu
+// and the BBS hashes to Zr; expandMsgXMD resets them before use.
+var blake2bPool = sync.Pool{
+	New: func() interface{} {
 		// We pass a null key so error is impossible here.
 		h, _ := blake2b.New512(nil) //nolint:errcheck
 		return h
-	}
+	},
+}
+
+func HashToG1GenericBESwu(data, domain []byte) (*bls12381.PointG1, error) {
+	h := blake2bPool.Get().(hash.Hash)
+	defer blake2bPool.Put(h)
+	hashFunc := func() hash.Hash { return h }
 
 	p, err := HashToCurveGenericBESwu(data, domain, hashFunc)
 	if err != nil {
@@ -209,6 +219,37 @@ func HashToG1GenericBESwu(data, domain []byte) (*bls12381.PointG1, error) {
 	return p, nil
 }
 
+// HashToZrGenericBE hashes msg to an integer modulo q following
+// hash_to_field. The BBS curves pass the same blake2b hash as
+// HashToG1GenericBESwu.
+func HashToZrGenericBE(msg, dst []byte, q *big.Int, hashFunc func() hash.Hash) (*big.Int, error) {
+	v, err := HashToZrsGenericBE(msg, dst, q, 1, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return v[0], nil
+}
+
+// HashToZrsGenericBE hashes msg to count integers modulo q following
+// hash_to_field, with a single expand_message_xmd of count * L bytes.
+func HashToZrsGenericBE(msg, dst []byte, q *big.Int, count int, hashFunc func() hash.Hash) ([]*big.Int, error) {
+	// L = ceil((ceil(log2(q)) + k) / 8), where k is the security parameter = 128
+	l := (q.BitLen() + 128 + 7) / 8
+	randBytes, err := expandMsgXMD(hashFunc, msg, dst, count*l)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*big.Int, count)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(randBytes[i*l : (i+1)*l])
+		res[i].Mod(res[i], q)
+	}
+
+	return res, nil
+}
+
 func HashToCurveGenericBESwu(msg, domain []byte, hashFunc func() hash.Hash) (*bls12381.PointG1, error) {
 	g := bls12381.NewG1()
 	hashRes, err := hashToFpXMD(hashFunc, msg, domain, 2)
@@ -253,6 +294,7 @@ func expandMsgXMD(f func() hash.Hash, msg []byte, domain []byte, outLen int) ([]
 	if domainLen > 255 {
 		return nil, errors.New("invalid domain length")
 	}
+	h.Reset()
 
 	// DST_prime = DST || I2OSP(len(DST), 1)
 	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
@@ -276,10 +318,10 @@ func expandMsgXMD(f func() hash.Hash, msg []byte, domain []byte, outLen int) ([]
 	ell := (outLen + h.Size() - 1) / h.Size()
 	bi := b1
 	out := make([]byte, outLen)
+	tmp := make([]byte, h.Size())
 	for i := 1; i < ell; i++ {
 		h.Reset()
 		// b_i = H(strxor(b_0, b_(i - 1)) || I2OSP(i, 1) || DST_prime)
-		tmp := make([]byte, h.Size())
 		for j := 0; j < h.Size(); j++ {
 			tmp[j] = b0[j] ^ bi[j]
 		}
@@ -0,0 +1,460 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kilic
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// frQ is r as the limbs of an Fr, for the range checks; frHalf is the
+// inverse of 2 modulo r.
+var (
+	frQ    bls12381.Fr
+	frHalf bls12381.Fr
+)
+
+func init() {
+	setLimbs(&frQ, frModulus.FillBytes(make([]byte, frByteSize)))
+	setLimbs(&frHalf, new(big.Int).Rsh(new(big.Int).Add(frModulus, big.NewInt(1)), 1).FillBytes(make([]byte, frByteSize)))
+}
+
+const frByteSize = 32
+
+// setLimbs sets e to the 32 big-endian bytes of b, without reducing them.
+func setLimbs(e *bls12381.Fr, b []byte) {
+	for i := range e {
+		e[i] = binary.BigEndian.Uint64(b[frByteSize-8*(i+1):])
+	}
+}
+
+// putLimbs writes e to b as 32 big-endian bytes.
+func putLimbs(b []byte, e *bls12381.Fr) {
+	for i := range e {
+		binary.BigEndian.PutUint64(b[frByteSize-8*(i+1):], e[i])
+	}
+}
+
+// zr is a scalar of BLS12-381 held, for the values in [0, r), as the Fr of
+// the library, on which its multiplications split the scalar for the
+// endomorphism. The Zr interface also carries values outside that range:
+// the results of Plus, Minus and Neg before any reduction, the group order
+// itself and residues modulo other moduli. Those are held in wide and
+// handled with big.Int, as common.BaseZr does; they are reduced modulo r
+// when they meet the arithmetic of Fr.
+type zr struct {
+	bls12381.Fr
+	wide *big.Int
+}
+
+func newZr(v *big.Int) *zr {
+	z := &zr{}
+	z.set(v)
+	return z
+}
+
+// set sets z to v, keeping it in wide if it is outside [0, r).
+func (z *zr) set(v *big.Int) {
+	if v.Sign() >= 0 && v.Cmp(frModulus) < 0 {
+		var b [frByteSize]byte
+		setLimbs(&z.Fr, v.FillBytes(b[:]))
+		z.wide = nil
+		return
+	}
+
+	z.wide = new(big.Int).Set(v)
+}
+
+// value sets dst to the value of z and returns it.
+func (z *zr) value(dst *big.Int) *big.Int {
+	if z.wide != nil {
+		return dst.Set(z.wide)
+	}
+
+	var b [frByteSize]byte
+	putLimbs(b[:], &z.Fr)
+	return dst.SetBytes(b[:])
+}
+
+// reduced returns z modulo r, in dst unless z is held as an Fr.
+func (z *zr) reduced(dst *bls12381.Fr) *bls12381.Fr {
+	if z.wide == nil {
+		return &z.Fr
+	}
+
+	var b [frByteSize]byte
+	setLimbs(dst, new(big.Int).Mod(z.wide, frModulus).FillBytes(b[:]))
+	return dst
+}
+
+// isOrder reports whether z is the group order, i.e. the modulus of the
+// Mod* operations that they can run on Fr.
+func (z *zr) isOrder() bool {
+	return z.wide != nil && z.wide.Cmp(frModulus) == 0
+}
+
+func (z *zr) Plus(a driver.Zr) driver.Zr {
+	b := a.(*zr)
+	if z.wide == nil && b.wide == nil {
+		rv := &zr{}
+		rv.Fr.Add(&z.Fr, &b.Fr)
+		if rv.Fr.Cmp(&z.Fr) >= 0 {
+			return rv
+		}
+	}
+
+	// the sum is not reduced
+	v := z.value(new(big.Int))
+	return newZr(v.Add(v, b.value(new(big.Int))))
+}
+
+func (z *zr) Minus(a driver.Zr) driver.Zr {
+	b := a.(*zr)
+	if z.wide == nil && b.wide == nil && z.Fr.Cmp(&b.Fr) >= 0 {
+		rv := &zr{}
+		rv.Fr.Sub(&z.Fr, &b.Fr)
+		return rv
+	}
+
+	// the difference is not reduced
+	v := z.value(new(big.Int))
+	return newZr(v.Sub(v, b.value(new(big.Int))))
+}
+
+func (z *zr) Mul(a driver.Zr) driver.Zr {
+	var x, y bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Mul(z.reduced(&x), a.(*zr).reduced(&y))
+	return rv
+}
+
+// AddInPlace sets z to z + a modulo r.
+func (z *zr) AddInPlace(a driver.Zr) {
+	var x, y bls12381.Fr
+	z.Fr.Add(z.reduced(&x), a.(*zr).reduced(&y))
+	z.wide = nil
+}
+
+// SubInPlace sets z to z - a modulo r.
+func (z *zr) SubInPlace(a driver.Zr) {
+	var x, y bls12381.Fr
+	z.Fr.Sub(z.reduced(&x), a.(*zr).reduced(&y))
+	z.wide = nil
+}
+
+// MulInPlace sets z to z * a modulo r.
+func (z *zr) MulInPlace(a driver.Zr) {
+	var x, y bls12381.Fr
+	z.Fr.Mul(z.reduced(&x), a.(*zr).reduced(&y))
+	z.wide = nil
+}
+
+func (z *zr) Mod(a driver.Zr) {
+	m := a.(*zr)
+	if m.isOrder() {
+		var x bls12381.Fr
+		z.Fr.Set(z.reduced(&x))
+		z.wide = nil
+		return
+	}
+
+	v := z.value(new(big.Int))
+	z.set(v.Mod(v, m.value(new(big.Int))))
+}
+
+// PowMod returns z^x modulo r; a negative x raises the inverse of z, and a
+// z with no inverse yields zero.
+func (z *zr) PowMod(x driver.Zr) driver.Zr {
+	var b bls12381.Fr
+	base := z.reduced(&b)
+
+	e := x.(*zr).value(new(big.Int))
+	if e.Sign() < 0 {
+		b.Inverse(base)
+		base = &b
+		e.Neg(e)
+	}
+
+	rv := &zr{}
+	rv.Fr.Exp(base, e)
+	return rv
+}
+
+func (z *zr) InvModP(p driver.Zr) {
+	m := p.(*zr)
+	if z.wide == nil && m.isOrder() {
+		// like big.Int.ModInverse, Fr leaves zero as it is
+		z.Fr.Inverse(&z.Fr)
+		return
+	}
+
+	v := z.value(new(big.Int))
+	if v.ModInverse(v, m.value(new(big.Int))) != nil {
+		z.set(v)
+	}
+}
+
+func (z *zr) Bytes() []byte {
+	if z.wide != nil {
+		return common.BigToBytes(common.Normalize(z.wide, frModulus))
+	}
+
+	b := make([]byte, frByteSize)
+	putLimbs(b, &z.Fr)
+	return b
+}
+
+// PutBytes writes Bytes into dst, see driver.BytesPutter.
+func (z *zr) PutBytes(dst []byte) int {
+	if z.wide != nil {
+		return copy(dst, z.Bytes())
+	}
+
+	putLimbs(dst[:frByteSize], &z.Fr)
+	return frByteSize
+}
+
+func (z *zr) Equals(a driver.Zr) bool {
+	b := a.(*zr)
+	if z.wide == nil && b.wide == nil {
+		return z.Fr.Equal(&b.Fr)
+	}
+
+	return common.Normalize(z.value(new(big.Int)), frModulus).Cmp(common.Normalize(b.value(new(big.Int)), frModulus)) == 0
+}
+
+func (z *zr) Copy() driver.Zr {
+	rv := &zr{}
+	rv.Clone(z)
+	return rv
+}
+
+func (z *zr) Clone(a driver.Zr) {
+	b := a.(*zr)
+	z.Fr.Set(&b.Fr)
+	z.wide = nil
+	if b.wide != nil {
+		z.wide = new(big.Int).Set(b.wide)
+	}
+}
+
+func (z *zr) String() string {
+	return common.Normalize(z.value(new(big.Int)), frModulus).Text(16)
+}
+
+// Neg negates z without reducing it, as common.BaseZr does.
+func (z *zr) Neg() {
+	if z.wide == nil && z.Fr.IsZero() {
+		return
+	}
+
+	v := z.value(new(big.Int))
+	z.set(v.Neg(v))
+}
+
+func (z *zr) Halve() driver.Zr {
+	var x bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Mul(z.reduced(&x), &frHalf)
+	return rv
+}
+
+func (z *zr) IsNegative() bool {
+	return z.wide != nil && z.wide.Sign() < 0
+}
+
+func (z *zr) Signed() *big.Int {
+	var x bls12381.Fr
+	rv := (&zr{Fr: *z.reduced(&x)}).value(new(big.Int))
+	if rv.Cmp(new(big.Int).Rsh(frModulus, 1)) > 0 {
+		rv.Sub(rv, frModulus)
+	}
+	return rv
+}
+
+func (z *zr) CmpAbs(a driver.Zr) int {
+	return z.Signed().CmpAbs(a.(*zr).Signed())
+}
+
+/*********************************************************************/
+
+func (c *Bls12_381) GroupOrder() driver.Zr {
+	return newZr(&c.Modulus)
+}
+
+func (c *Bls12_381) NewZrFromBytes(b []byte) driver.Zr {
+	rv := &zr{}
+	if len(b) == frByteSize {
+		setLimbs(&rv.Fr, b)
+		if rv.Fr.Cmp(&frQ) < 0 {
+			return rv
+		}
+	}
+
+	rv.set(new(big.Int).SetBytes(b))
+	return rv
+}
+
+func (c *Bls12_381) NewZrFromInt64(i int64) driver.Zr {
+	if i < 0 {
+		return newZr(big.NewInt(i))
+	}
+
+	return &zr{Fr: bls12381.Fr{uint64(i)}}
+}
+
+func (c *Bls12_381) NewZrFromUint64(i uint64) driver.Zr {
+	return &zr{Fr: bls12381.Fr{i}}
+}
+
+// NewRandomZr draws the scalar as crypto/rand.Int does, i.e. it reads the
+// same bytes from rng and returns the same value, without going through
+// big.Int.
+func (c *Bls12_381) NewRandomZr(rng io.Reader) driver.Zr {
+	var b [frByteSize]byte
+	rv := &zr{}
+	for {
+		if _, err := io.ReadFull(rng, b[:]); err != nil {
+			panic(err)
+		}
+
+		// r has 255 bits
+		b[0] &= 0x7f
+		setLimbs(&rv.Fr, b[:])
+		if rv.Fr.Cmp(&frQ) < 0 {
+			return rv
+		}
+	}
+}
+
+func (c *Bls12_381) HashToZr(data []byte) driver.Zr {
+	digest := sha256.Sum256(data)
+	return newZr(new(big.Int).Mod(new(big.Int).SetBytes(digest[:]), frModulus))
+}
+
+// The modular operations run on Fr when m is the group order and on
+// big.Int otherwise.
+
+// modBig returns v modulo m.
+func modBig(v *big.Int, m driver.Zr) driver.Zr {
+	return newZr(v.Mod(v, m.(*zr).value(new(big.Int))))
+}
+
+func (c *Bls12_381) ModNeg(a1, m driver.Zr) driver.Zr {
+	a := a1.(*zr)
+	if !m.(*zr).isOrder() {
+		v := m.(*zr).value(new(big.Int))
+		return modBig(v.Sub(v, a.value(new(big.Int))), m)
+	}
+
+	var x bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Neg(a.reduced(&x))
+	return rv
+}
+
+func (c *Bls12_381) ModAdd(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*zr), b1.(*zr)
+	if !m.(*zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig(v.Add(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Add(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+func (c *Bls12_381) ModSub(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*zr), b1.(*zr)
+	if !m.(*zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig(v.Sub(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Sub(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+// ModAdd2 sets a1 to a1 + b1 + c1 modulo m
+func (c *Bls12_381) ModAdd2(a1, b1, c1, m driver.Zr) {
+	a := a1.(*zr)
+	if !m.(*zr).isOrder() {
+		v := a.value(new(big.Int))
+		v.Add(v, b1.(*zr).value(new(big.Int)))
+		v.Add(v, c1.(*zr).value(new(big.Int)))
+		a.Clone(modBig(v, m))
+		return
+	}
+
+	var x, y, z bls12381.Fr
+	a.Fr.Add(a.reduced(&x), b1.(*zr).reduced(&y))
+	a.Fr.Add(&a.Fr, c1.(*zr).reduced(&z))
+	a.wide = nil
+}
+
+func (c *Bls12_381) ModMul(a1, b1, m driver.Zr) driver.Zr {
+	a, b := a1.(*zr), b1.(*zr)
+	if !m.(*zr).isOrder() {
+		v := a.value(new(big.Int))
+		return modBig(v.Mul(v, b.value(new(big.Int))), m)
+	}
+
+	var x, y bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Mul(a.reduced(&x), b.reduced(&y))
+	return rv
+}
+
+// ModMul3 returns a1 * b1 * c1 modulo m.
+func (c *Bls12_381) ModMul3(a1, b1, c1, m driver.Zr) driver.Zr {
+	if !m.(*zr).isOrder() {
+		v := a1.(*zr).value(new(big.Int))
+		v.Mul(v, b1.(*zr).value(new(big.Int)))
+		return modBig(v.Mul(v, c1.(*zr).value(new(big.Int))), m)
+	}
+
+	var x, y, z bls12381.Fr
+	rv := &zr{}
+	rv.Fr.Mul(a1.(*zr).reduced(&x), b1.(*zr).reduced(&y))
+	rv.Fr.Mul(&rv.Fr, c1.(*zr).reduced(&z))
+	return rv
+}
+
+// ModAddMul returns the sum of a1[i] * b1[i] modulo m
+func (c *Bls12_381) ModAddMul(a1, b1 []driver.Zr, m driver.Zr) driver.Zr {
+	if !m.(*zr).isOrder() {
+		sum, v := new(big.Int), new(big.Int)
+		for i := range a1 {
+			a1[i].(*zr).value(v)
+			sum.Add(sum, v.Mul(v, b1[i].(*zr).value(new(big.Int))))
+		}
+		return modBig(sum, m)
+	}
+
+	var x, y, prod bls12381.Fr
+	rv := &zr{}
+	for i := range a1 {
+		prod.Mul(a1[i].(*zr).reduced(&x), b1[i].(*zr).reduced(&y))
+		rv.Fr.Add(&rv.Fr, &prod)
+	}
+	return rv
+}
+
+// ModAddMul2 returns a1 * c1 + b1 * c2 modulo m
+func (c *Bls12_381) ModAddMul2(a1, c1, b1, c2, m driver.Zr) driver.Zr {
+	return c.ModAddMul([]driver.Zr{a1, b1}, []driver.Zr{c1, c2}, m)
+}
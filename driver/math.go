@@ -7,15 +7,194 @@ SPDX-License-Identifier: Apache-2.0
 package driver
 
 import (
+	"errors"
 	"io"
+	"math/big"
 )
 
+// ErrUnsupported is the panic value of drivers that do not implement an
+// operation, e.g. pairings on a curve that only provides a prime order group.
+var ErrUnsupported = errors.New("operation not supported by this curve")
+
+// ErrNotOnCurve and ErrNotInSubgroup are wrapped by the errors of drivers
+// that fail to decode a point because it does not satisfy the curve
+// equation, or because it lies outside the prime order subgroup. Other
+// decoding errors are reported as invalid encodings.
+var (
+	ErrNotOnCurve    = errors.New("point is not on the curve")
+	ErrNotInSubgroup = errors.New("point is not in the prime order subgroup")
+)
+
+// PairingSupport is implemented by drivers that may lack G2, Gt and pairings;
+// drivers that do not implement it are assumed to support them.
+type PairingSupport interface {
+	SupportsPairing() bool
+}
+
+// ZrDomainHasher is implemented by drivers that hash to Zr with a domain
+// separation tag. HashToZrBatch derives count scalars from a single
+// expansion of data, the first of which is HashToZrWithDomain's if count
+// is one.
+type ZrDomainHasher interface {
+	HashToZrWithDomain(data, domain []byte) Zr
+	HashToZrBatch(data, domain []byte, count int) []Zr
+}
+
+// GtCompressor is implemented by drivers that provide a compressed
+// encoding of Gt elements.
+type GtCompressor interface {
+	CompressedGt(Gt) ([]byte, error)
+	NewGtFromCompressed([]byte) (Gt, error)
+}
+
+// MultiPairer is implemented by drivers that compute the product of many
+// pairings with a single Miller loop and final exponentiation. Unlike
+// Pairing2, the result needs no FExp. Callers pass slices of the same,
+// non-zero, length.
+type MultiPairer interface {
+	MultiPairing(p2 []G2, p1 []G1) Gt
+}
+
+// MillerLooper is implemented by drivers that run the Miller loop of many
+// pairs at once. Like the output of Pairing2, the result needs FExp.
+// Callers pass slices of the same, non-zero, length.
+type MillerLooper interface {
+	MillerLoop(p2 []G2, p1 []G1) Gt
+}
+
+// ZrMontgomeryEncoder is implemented by drivers whose library holds
+// scalars in Montgomery form, i.e. as aR mod r for a power of two R. The
+// encoding is the big-endian bytes of aR mod r, which only that library
+// understands.
+type ZrMontgomeryEncoder interface {
+	ZrMontgomeryBytes(Zr) []byte
+	NewZrFromMontgomery([]byte) (Zr, error)
+}
+
+// FieldMapper is implemented by drivers that expose the map_to_curve of
+// RFC 9380 followed by clear_cofactor, i.e. encode_to_curve without its
+// hash_to_field. The field elements are canonical and big-endian; those of
+// G2 have several components, laid out from the highest to the lowest.
+type FieldMapper interface {
+	MapFieldToG1(b []byte) (G1, error)
+	MapFieldToG2(b []byte) (G2, error)
+}
+
+// UncheckedDecoder is implemented by drivers that can decode uncompressed
+// points without checking that they are on the curve and in the subgroup,
+// e.g. to inspect invalid points with IsOnCurve.
+type UncheckedDecoder interface {
+	NewG1FromBytesUnchecked(b []byte) (G1, error)
+	NewG2FromBytesUnchecked(b []byte) (G2, error)
+}
+
+// BaseFieldProvider is implemented by drivers with their own arithmetic in
+// the base field of the curve; the others get one based on big.Int. b is
+// canonical and big-endian, CoordinateByteSize bytes long.
+type BaseFieldProvider interface {
+	NewFpFromBytes(b []byte) (Fp, error)
+}
+
+// IncrementMapper is implemented by drivers that map data to G1 with a
+// try-and-increment method shared with other drivers of the same curve.
+type IncrementMapper interface {
+	MapToG1Increment(data []byte) G1
+}
+
+// MultiScalarMuler is implemented by drivers with a native multi-scalar
+// multiplication, returning the sum of [scalars[i]]points[i] with at most
+// tasks goroutines, or the library's default if tasks is not positive.
+// Callers pass slices of the same, non-zero, length.
+type MultiScalarMuler interface {
+	MultiScalarMul(points []G1, scalars []Zr, tasks int) G1
+}
+
+// SubgroupChecker is implemented by G1 and G2 elements, e.g. the gnark
+// ones, with a subgroup test cheaper than a multiplication by the order,
+// such as one based on an endomorphism.
+type SubgroupChecker interface {
+	IsInSubGroup() bool
+}
+
+// BytesPutter is implemented by elements, e.g. the gnark ones, that write
+// the output of Bytes into dst without allocating, and return its length.
+// Callers pass a dst at least as long as that output, whose length is the
+// size of the encoding given by the curve.
+type BytesPutter interface {
+	PutBytes(dst []byte) int
+}
+
+// CompressedPutter is like BytesPutter for the output of Compressed.
+type CompressedPutter interface {
+	PutCompressed(dst []byte) int
+}
+
+// G1Summer is implemented by drivers that add up many points faster than
+// one Add at a time, e.g. by normalizing the sum only once, like SumG2.
+type G1Summer interface {
+	SumG1(points []G1) G1
+}
+
+// PointSelector is implemented by drivers that select between two points in
+// constant time, with the conditional moves of their field: SelectG1 and
+// SelectG2 return a copy of a if cond is 1 and of b if it is 0.
+type PointSelector interface {
+	SelectG1(cond int, a, b G1) G1
+	SelectG2(cond int, a, b G2) G2
+}
+
+// MulAdder is implemented by drivers that set acc to acc + [s]P with a
+// single conversion to affine coordinates, keeping [s]P projective.
+type MulAdder interface {
+	MulAddG1(acc G1, s Zr, P G1)
+	MulAddG2(acc G2, s Zr, P G2)
+}
+
+// EmbeddedCurve is implemented by drivers of curves defined over the scalar
+// field of another curve, e.g. Jubjub over BLS12-381. Coordinates are
+// integers in [0, BaseFieldModulus()).
+type EmbeddedCurve interface {
+	BaseFieldModulus() *big.Int
+	G1Coordinates(G1) (u, v *big.Int)
+	NewG1FromCoordinates(u, v *big.Int) (G1, error)
+}
+
+// CurveInfo describes the parameters of a curve. Curves without pairings
+// have EmbeddingDegree 0 and a nil G2Cofactor.
+type CurveInfo struct {
+	SecurityLevelBits  int
+	EmbeddingDegree    int
+	G1Cofactor         *big.Int
+	G2Cofactor         *big.Int
+	BaseFieldModulus   *big.Int
+	ScalarFieldModulus *big.Int
+
+	// lengths of the encodings of the points at infinity where they differ
+	// from those of the other points, as on SEC1 curves which encode them
+	// on a single byte; zero means the same length
+	InfinityG1ByteSize           int
+	CompressedInfinityG1ByteSize int
+	InfinityG2ByteSize           int
+	CompressedInfinityG2ByteSize int
+}
+
+// InfoProvider is implemented by drivers that describe their curve.
+type InfoProvider interface {
+	Info() *CurveInfo
+}
+
+// Curve is implemented by every driver. Pairing returns the unity of Gt if
+// either point is at infinity, and Pairing2 leaves such pairs out.
 type Curve interface {
 	Pairing(G2, G1) Gt
 	Pairing2(p2a, p2b G2, p1a, p1b G1) Gt
 	FExp(Gt) Gt
 	ModMul(a1, b1, m Zr) Zr
+	ModMul3(a1, b1, c1, m Zr) Zr
 	ModNeg(a1, m Zr) Zr
+	ModAdd2(a1, b1, c1, m Zr)
+	ModAddMul(a1, b1 []Zr, m Zr) Zr
+	ModAddMul2(a1, c1, b1, c2, m Zr) Zr
 	GenG1() G1
 	GenG2() G2
 	GenGt() Gt
@@ -25,32 +204,42 @@ type Curve interface {
 	CompressedG1ByteSize() int
 	G2ByteSize() int
 	CompressedG2ByteSize() int
+	GtByteSize() int
 	ScalarByteSize() int
 	NewG1() G1
 	NewG2() G2
+	InfinityG1() G1
+	InfinityG2() G2
 	NewZrFromBytes(b []byte) Zr
 	NewZrFromInt64(i int64) Zr
 	NewZrFromUint64(i uint64) Zr
-	NewG1FromBytes(b []byte) G1
-	NewG1FromCompressed(b []byte) G1
-	NewG2FromBytes(b []byte) G2
-	NewG2FromCompressed(b []byte) G2
-	NewGtFromBytes(b []byte) Gt
+	NewG1FromBytes(b []byte) (G1, error)
+	NewG1FromCompressed(b []byte) (G1, error)
+	NewG2FromBytes(b []byte) (G2, error)
+	NewG2FromCompressed(b []byte) (G2, error)
+	NewGtFromBytes(b []byte) (Gt, error)
 	ModAdd(a, b, m Zr) Zr
 	ModSub(a, b, m Zr) Zr
 	HashToZr(data []byte) Zr
 	HashToG1(data []byte) G1
 	HashToG1WithDomain(data, domain []byte) G1
+	HashToG1WithU(data, domain []byte) (G1, []Zr)
+	MapToG1(u []Zr) G1
 	HashToG2(data []byte) G2
 	HashToG2WithDomain(data, domain []byte) G2
 	NewRandomZr(rng io.Reader) Zr
 	Rand() (io.Reader, error)
+	MulMany(base G1, scalars []Zr) []G1
+	SumG2(points []G2) G2
 }
 
 type Zr interface {
 	Plus(Zr) Zr
 	Minus(Zr) Zr
 	Mul(Zr) Zr
+	AddInPlace(Zr)
+	SubInPlace(Zr)
+	MulInPlace(Zr)
 	Mod(Zr)
 	PowMod(Zr) Zr
 	InvModP(Zr)
@@ -60,6 +249,10 @@ type Zr interface {
 	Clone(a Zr)
 	String() string
 	Neg()
+	Halve() Zr
+	IsNegative() bool
+	Signed() *big.Int
+	CmpAbs(Zr) int
 }
 
 type G1 interface {
@@ -73,6 +266,10 @@ type G1 interface {
 	Compressed() []byte
 	Sub(G1)
 	IsInfinity() bool
+	IsGenerator() bool
+	// IsOnCurve reports whether the point satisfies the curve equation,
+	// regardless of the subgroup it is in.
+	IsOnCurve() bool
 	String() string
 	Neg()
 }
@@ -88,11 +285,36 @@ type G2 interface {
 	Compressed() []byte
 	String() string
 	Equals(G2) bool
+	IsGenerator() bool
+	// IsOnCurve reports whether the point satisfies the equation of the
+	// twist, regardless of the subgroup it is in.
+	IsOnCurve() bool
+}
+
+// Fp is an element of the base field of a curve. Its operations return new
+// elements, leaving their operands untouched.
+type Fp interface {
+	Add(Fp) Fp
+	Sub(Fp) Fp
+	Mul(Fp) Fp
+	Neg() Fp
+	// Inverse returns the inverse of the element, which must not be zero.
+	Inverse() Fp
+	// Sqrt returns a square root of the element, or false if it has none.
+	Sqrt() (Fp, bool)
+	Equals(Fp) bool
+	IsZero() bool
+	// Bytes returns the canonical big-endian encoding of the element.
+	Bytes() []byte
+	String() string
 }
 
 type Gt interface {
 	Equals(Gt) bool
 	Inverse()
+	// Conjugate sets the element to its conjugate, which is its inverse
+	// if it is unitary, as the elements of Gt after FExp are.
+	Conjugate()
 	Mul(Gt)
 	IsUnity() bool
 	ToString() string
@@ -0,0 +1,370 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nist
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/IBM/mathlib/driver/gurvy"
+)
+
+const coordinateByteSize = 32
+
+// SEC1 point encodings
+const (
+	sec1Infinity     = 0x00
+	sec1Uncompressed = 0x04
+)
+
+var p256 = elliptic.P256()
+
+// parameters of the SSWU map of the P256_XMD:SHA-256_SSWU_RO_ suite
+var (
+	sswuA = big.NewInt(-3)
+	sswuZ = big.NewInt(-10)
+)
+
+/*********************************************************************/
+
+// p256G1 holds the affine coordinates of a point, (0, 0) being the point
+// at infinity as in crypto/elliptic.
+type p256G1 struct {
+	x, y big.Int
+}
+
+func (g *p256G1) set(x, y *big.Int) *p256G1 {
+	g.x.Set(x)
+	g.y.Set(y)
+	return g
+}
+
+func (g *p256G1) Clone(a driver.G1) {
+	g.set(&a.(*p256G1).x, &a.(*p256G1).y)
+}
+
+func (g *p256G1) Copy() driver.G1 {
+	return new(p256G1).set(&g.x, &g.y)
+}
+
+func (g *p256G1) Add(a driver.G1) {
+	g.set(p256.Add(&g.x, &g.y, &a.(*p256G1).x, &a.(*p256G1).y))
+}
+
+func (g *p256G1) Mul(a driver.Zr) driver.G1 {
+	k := new(big.Int).Mod(&a.(*common.BaseZr).Int, p256.Params().N)
+	return new(p256G1).set(p256.ScalarMult(&g.x, &g.y, k.Bytes()))
+}
+
+func (g *p256G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+func (g *p256G1) Equals(a driver.G1) bool {
+	return g.x.Cmp(&a.(*p256G1).x) == 0 && g.y.Cmp(&a.(*p256G1).y) == 0
+}
+
+// Bytes returns the SEC1 uncompressed encoding of g
+func (g *p256G1) Bytes() []byte {
+	if g.IsInfinity() {
+		return []byte{sec1Infinity}
+	}
+
+	return elliptic.Marshal(p256, &g.x, &g.y)
+}
+
+// Compressed returns the SEC1 compressed encoding of g
+func (g *p256G1) Compressed() []byte {
+	if g.IsInfinity() {
+		return []byte{sec1Infinity}
+	}
+
+	return elliptic.MarshalCompressed(p256, &g.x, &g.y)
+}
+
+func (g *p256G1) Sub(a driver.G1) {
+	neg := a.Copy()
+	neg.Neg()
+	g.Add(neg)
+}
+
+func (g *p256G1) IsInfinity() bool {
+	return g.x.Sign() == 0 && g.y.Sign() == 0
+}
+
+func (g *p256G1) IsGenerator() bool {
+	return g.x.Cmp(p256.Params().Gx) == 0 && g.y.Cmp(p256.Params().Gy) == 0
+}
+
+func (g *p256G1) IsOnCurve() bool {
+	return g.IsInfinity() || p256.IsOnCurve(&g.x, &g.y)
+}
+
+func (g *p256G1) String() string {
+	return "(" + g.x.String() + "," + g.y.String() + ")"
+}
+
+func (g *p256G1) Neg() {
+	if !g.IsInfinity() {
+		g.y.Sub(p256.Params().P, &g.y)
+	}
+}
+
+/*********************************************************************/
+
+func NewP256() *P256 {
+	return &P256{common.CurveBase{Modulus: *p256.Params().N}}
+}
+
+// P256 is the NIST curve built on crypto/elliptic, whose P-256
+// implementation runs in constant time. It only provides the prime order
+// group G1: G2, Gt and pairing operations panic with driver.ErrUnsupported.
+type P256 struct {
+	common.CurveBase
+}
+
+func (c *P256) Info() *driver.CurveInfo {
+	params := p256.Params()
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		G1Cofactor:         big.NewInt(1),
+		BaseFieldModulus:   new(big.Int).Set(params.P),
+		ScalarFieldModulus: new(big.Int).Set(params.N),
+
+		InfinityG1ByteSize:           1,
+		CompressedInfinityG1ByteSize: 1,
+	}
+}
+
+func (c *P256) SupportsPairing() bool {
+	return false
+}
+
+func (c *P256) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *P256) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *P256) FExp(a driver.Gt) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *P256) GenG1() driver.G1 {
+	return new(p256G1).set(p256.Params().Gx, p256.Params().Gy)
+}
+
+func (c *P256) GenG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *P256) GenGt() driver.Gt {
+	return common.UnsupportedGt{}
+}
+
+func (c *P256) CoordinateByteSize() int {
+	return coordinateByteSize
+}
+
+func (c *P256) G1ByteSize() int {
+	return 1 + 2*coordinateByteSize
+}
+
+func (c *P256) CompressedG1ByteSize() int {
+	return 1 + coordinateByteSize
+}
+
+func (c *P256) G2ByteSize() int {
+	return 0
+}
+
+func (c *P256) CompressedG2ByteSize() int {
+	return 0
+}
+
+func (c *P256) GtByteSize() int {
+	return 0
+}
+
+func (c *P256) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+func (c *P256) NewG1() driver.G1 {
+	return &p256G1{}
+}
+
+func (c *P256) NewG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *P256) SumG2(points []driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *P256) InfinityG1() driver.G1 {
+	return &p256G1{}
+}
+
+func (c *P256) InfinityG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *P256) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) == 1 && b[0] == sec1Infinity {
+		return &p256G1{}, nil
+	}
+
+	if len(b) != c.G1ByteSize() || b[0] != sec1Uncompressed {
+		return nil, errors.New("set bytes failed [invalid SEC1 uncompressed encoding]")
+	}
+
+	x, y := elliptic.Unmarshal(p256, b)
+	if x == nil {
+		return nil, fmt.Errorf("set bytes failed [%w]", driver.ErrNotOnCurve)
+	}
+
+	return new(p256G1).set(x, y), nil
+}
+
+func (c *P256) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	if len(b) == 1 && b[0] == sec1Infinity {
+		return &p256G1{}, nil
+	}
+
+	x, y := elliptic.UnmarshalCompressed(p256, b)
+	if x == nil {
+		return nil, errors.New("set bytes failed [invalid SEC1 compressed encoding]")
+	}
+
+	return new(p256G1).set(x, y), nil
+}
+
+func (c *P256) NewG2FromBytes(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *P256) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *P256) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *P256) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+// HashToG1WithDomain implements the P256_XMD:SHA-256_SSWU_RO_ suite of
+// RFC 9380.
+func (c *P256) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g, _ := c.HashToG1WithU(data, domain)
+	return g
+}
+
+func (c *P256) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	// L = ceil((ceil(log2(p)) + k) / 8), where k is the security parameter = 128
+	const L = 48
+
+	b, err := gurvy.ExpandMsgXmd(data, domain, 2*L, sha256.New)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	u := make([]driver.Zr, 2)
+	for i := range u {
+		v := new(big.Int).SetBytes(b[i*L : (i+1)*L])
+		u[i] = &common.BaseZr{Int: *v.Mod(v, p256.Params().P), Modulus: *p256.Params().P}
+	}
+
+	return c.MapToG1(u), u
+}
+
+func (c *P256) MapToG1(u []driver.Zr) driver.G1 {
+	res := &p256G1{}
+	for _, e := range u {
+		res.Add(sswu(&e.(*common.BaseZr).Int))
+	}
+
+	return res
+}
+
+// sswu is the simplified Shallue-van de Woestijne-Ulas map of RFC 9380,
+// Section 6.6.2; P-256 has cofactor 1, so its output needs no clearing.
+func sswu(u *big.Int) *p256G1 {
+	p := p256.Params().P
+	B := p256.Params().B
+
+	gx := func(x *big.Int) *big.Int {
+		// x^3 + A * x + B
+		r := new(big.Int).Mul(x, x)
+		r.Add(r, sswuA)
+		r.Mul(r, x)
+		r.Add(r, B)
+		return r.Mod(r, p)
+	}
+
+	// tv1 = inv0(Z^2 * u^4 + Z * u^2)
+	zu2 := new(big.Int).Mul(u, u)
+	zu2.Mul(zu2, sswuZ)
+	zu2.Mod(zu2, p)
+	tv1 := new(big.Int).Mul(zu2, zu2)
+	tv1.Add(tv1, zu2)
+	tv1.Mod(tv1, p)
+
+	x1 := new(big.Int)
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z * A)
+		x1.Mul(sswuZ, sswuA)
+		x1.ModInverse(x1.Mod(x1, p), p)
+		x1.Mul(x1, B)
+	} else {
+		// x1 = (-B / A) * (1 + tv1)
+		tv1.ModInverse(tv1, p)
+		tv1.Add(tv1, big.NewInt(1))
+		x1.ModInverse(new(big.Int).Mod(sswuA, p), p)
+		x1.Mul(x1, B)
+		x1.Neg(x1)
+		x1.Mul(x1, tv1)
+	}
+	x1.Mod(x1, p)
+
+	x := x1
+	y := new(big.Int).ModSqrt(gx(x1), p)
+	if y == nil {
+		// x2 = Z * u^2 * x1
+		x = new(big.Int).Mul(zu2, x1)
+		x.Mod(x, p)
+		y = new(big.Int).ModSqrt(gx(x), p)
+	}
+
+	if u.Bit(0) != y.Bit(0) {
+		y.Sub(p, y)
+	}
+
+	return new(p256G1).set(x, y)
+}
+
+func (c *P256) HashToG2(data []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *P256) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
@@ -0,0 +1,504 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ristretto
+
+import (
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/IBM/mathlib/driver/gurvy"
+)
+
+const encodingByteSize = 32
+
+var fpModulus big.Int // 2^255 - 19 stored as big.Int
+var frModulus big.Int // l stored as big.Int
+func init() {
+	fpModulus.SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+	frModulus.SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+}
+
+// constants of RFC 9496, Section 4.1
+var (
+	feD              = feFromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	feSqrtM1         = feFromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	feSqrtADMinusOne = feFromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	feInvSqrtAMinusD = feFromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	feOneMinusDSq    = feFromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	feDMinusOneSq    = feFromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+)
+
+func feFromDecimal(s string) *field.Element {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid constant")
+	}
+
+	return feFromBig(v)
+}
+
+func feFromBig(v *big.Int) *field.Element {
+	fe, err := new(field.Element).SetBytes(reversed(v.FillBytes(make([]byte, encodingByteSize))))
+	if err != nil {
+		panic(err)
+	}
+
+	return fe
+}
+
+func reversed(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+func toScalar(z driver.Zr) *edwards25519.Scalar {
+	b := new(big.Int).Mod(&z.(*common.BaseZr).Int, &frModulus).FillBytes(make([]byte, encodingByteSize))
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(reversed(b))
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func fromScalar(s *edwards25519.Scalar) *common.BaseZr {
+	return &common.BaseZr{Int: *new(big.Int).SetBytes(reversed(s.Bytes())), Modulus: frModulus}
+}
+
+/*********************************************************************/
+
+// ristretto255G1 is an element of the ristretto255 group, represented by
+// any of the edwards25519 points of its coset.
+type ristretto255G1 struct {
+	edwards25519.Point
+}
+
+func newG1() *ristretto255G1 {
+	g := &ristretto255G1{}
+	g.Point.Set(edwards25519.NewIdentityPoint())
+	return g
+}
+
+// genG1 is compared against by IsGenerator
+var genG1 = (&Ristretto255{}).GenG1()
+
+func (g *ristretto255G1) Clone(a driver.G1) {
+	g.Point.Set(&a.(*ristretto255G1).Point)
+}
+
+func (g *ristretto255G1) Copy() driver.G1 {
+	c := &ristretto255G1{}
+	c.Point.Set(&g.Point)
+	return c
+}
+
+func (g *ristretto255G1) Add(a driver.G1) {
+	g.Point.Add(&g.Point, &a.(*ristretto255G1).Point)
+}
+
+func (g *ristretto255G1) Mul(a driver.Zr) driver.G1 {
+	res := &ristretto255G1{}
+	res.Point.ScalarMult(toScalar(a), &g.Point)
+	return res
+}
+
+func (g *ristretto255G1) Mul2(e driver.Zr, Q driver.G1, f driver.Zr) driver.G1 {
+	a := g.Mul(e)
+	b := Q.Mul(f)
+	a.Add(b)
+
+	return a
+}
+
+// Equals compares the cosets of g and a as in RFC 9496, Section 4.3.3
+func (g *ristretto255G1) Equals(a driver.G1) bool {
+	x1, y1, _, _ := g.Point.ExtendedCoordinates()
+	x2, y2, _, _ := a.(*ristretto255G1).Point.ExtendedCoordinates()
+
+	var l, r field.Element
+	eq := l.Multiply(x1, y2).Equal(r.Multiply(y1, x2))
+	eq |= l.Multiply(y1, y2).Equal(r.Multiply(x1, x2))
+
+	return eq == 1
+}
+
+// Bytes returns the canonical encoding of RFC 9496, Section 4.3.2
+func (g *ristretto255G1) Bytes() []byte {
+	x0, y0, z0, t0 := g.Point.ExtendedCoordinates()
+
+	var u1, u2, tmp field.Element
+	u1.Multiply(tmp.Add(z0, y0), u1.Subtract(z0, y0))
+	u2.Multiply(x0, y0)
+
+	// ignore was_square since this is always square
+	var invsqrt field.Element
+	invsqrt.SqrtRatio(new(field.Element).One(), tmp.Multiply(&u1, tmp.Square(&u2)))
+
+	var den1, den2, zInv field.Element
+	den1.Multiply(&invsqrt, &u1)
+	den2.Multiply(&invsqrt, &u2)
+	zInv.Multiply(&den1, zInv.Multiply(&den2, t0))
+
+	var ix0, iy0, enchantedDenominator field.Element
+	ix0.Multiply(x0, feSqrtM1)
+	iy0.Multiply(y0, feSqrtM1)
+	enchantedDenominator.Multiply(&den1, feInvSqrtAMinusD)
+
+	rotate := tmp.Multiply(t0, &zInv).IsNegative()
+
+	var x, y, denInv field.Element
+	x.Select(&iy0, x0, rotate)
+	y.Select(&ix0, y0, rotate)
+	denInv.Select(&enchantedDenominator, &den2, rotate)
+
+	var minusY field.Element
+	y.Select(minusY.Negate(&y), &y, tmp.Multiply(&x, &zInv).IsNegative())
+
+	var s field.Element
+	s.Absolute(s.Multiply(&denInv, s.Subtract(z0, &y)))
+
+	return s.Bytes()
+}
+
+func (g *ristretto255G1) Compressed() []byte {
+	return g.Bytes()
+}
+
+func (g *ristretto255G1) Sub(a driver.G1) {
+	g.Point.Subtract(&g.Point, &a.(*ristretto255G1).Point)
+}
+
+func (g *ristretto255G1) IsInfinity() bool {
+	return g.Equals(newG1())
+}
+
+func (g *ristretto255G1) IsGenerator() bool {
+	return g.Equals(genG1)
+}
+
+// IsOnCurve always holds: every encoding that decodes is a group element.
+func (g *ristretto255G1) IsOnCurve() bool {
+	return true
+}
+
+func (g *ristretto255G1) String() string {
+	return fmt.Sprintf("%x", g.Bytes())
+}
+
+func (g *ristretto255G1) Neg() {
+	g.Point.Negate(&g.Point)
+}
+
+/*********************************************************************/
+
+func NewRistretto255() *Ristretto255 {
+	return &Ristretto255{common.CurveBase{Modulus: frModulus}}
+}
+
+// Ristretto255 is the prime order group of RFC 9496 built on top of
+// edwards25519. Like secp256k1 it only provides G1: G2, Gt and pairing
+// operations panic with driver.ErrUnsupported.
+type Ristretto255 struct {
+	common.CurveBase
+}
+
+// Info describes the ristretto255 group, which has prime order even though
+// edwards25519 has cofactor 8.
+func (c *Ristretto255) Info() *driver.CurveInfo {
+	return &driver.CurveInfo{
+		SecurityLevelBits:  128,
+		G1Cofactor:         big.NewInt(1),
+		BaseFieldModulus:   common.HexInt("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed"),
+		ScalarFieldModulus: new(big.Int).Set(&frModulus),
+	}
+}
+
+func (c *Ristretto255) SupportsPairing() bool {
+	return false
+}
+
+func (c *Ristretto255) Pairing(p2 driver.G2, p1 driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Ristretto255) Pairing2(p2a, p2b driver.G2, p1a, p1b driver.G1) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Ristretto255) FExp(a driver.Gt) driver.Gt {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Ristretto255) GenG1() driver.G1 {
+	g := &ristretto255G1{}
+	g.Point.Set(edwards25519.NewGeneratorPoint())
+	return g
+}
+
+func (c *Ristretto255) GenG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Ristretto255) GenGt() driver.Gt {
+	return common.UnsupportedGt{}
+}
+
+func (c *Ristretto255) CoordinateByteSize() int {
+	return encodingByteSize
+}
+
+func (c *Ristretto255) G1ByteSize() int {
+	return encodingByteSize
+}
+
+func (c *Ristretto255) CompressedG1ByteSize() int {
+	return encodingByteSize
+}
+
+func (c *Ristretto255) G2ByteSize() int {
+	return 0
+}
+
+func (c *Ristretto255) CompressedG2ByteSize() int {
+	return 0
+}
+
+func (c *Ristretto255) GtByteSize() int {
+	return 0
+}
+
+func (c *Ristretto255) ScalarByteSize() int {
+	return common.ScalarByteSize
+}
+
+// NewRandomZr reduces 64 random bytes modulo l, so that the bias is
+// negligible.
+func (c *Ristretto255) NewRandomZr(rng io.Reader) driver.Zr {
+	var b [64]byte
+	if _, err := io.ReadFull(rng, b[:]); err != nil {
+		panic(err)
+	}
+
+	s, err := edwards25519.NewScalar().SetUniformBytes(b[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return fromScalar(s)
+}
+
+// HashToZr reduces a SHA-512 digest modulo l: a SHA-256 one would be
+// noticeably biased since l is close to 2^252.
+func (c *Ristretto255) HashToZr(data []byte) driver.Zr {
+	digest := sha512.Sum512(data)
+
+	s, err := edwards25519.NewScalar().SetUniformBytes(digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return fromScalar(s)
+}
+
+func (c *Ristretto255) NewG1() driver.G1 {
+	return newG1()
+}
+
+func (c *Ristretto255) NewG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+func (c *Ristretto255) SumG2(points []driver.G2) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Ristretto255) InfinityG1() driver.G1 {
+	return newG1()
+}
+
+func (c *Ristretto255) InfinityG2() driver.G2 {
+	return common.UnsupportedG2{}
+}
+
+// NewG1FromBytes decodes b as in RFC 9496, Section 4.3.1
+func (c *Ristretto255) NewG1FromBytes(b []byte) (driver.G1, error) {
+	if len(b) != encodingByteSize {
+		return nil, fmt.Errorf("set bytes failed [invalid length %d]", len(b))
+	}
+
+	s, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	// s must be canonical and non-negative
+	if s.IsNegative() == 1 || string(s.Bytes()) != string(b) {
+		return nil, errors.New("set bytes failed [invalid encoding]")
+	}
+
+	one := new(field.Element).One()
+	ss := new(field.Element).Square(s)
+	u1 := new(field.Element).Subtract(one, ss)
+	u2 := new(field.Element).Add(one, ss)
+	u2Sqr := new(field.Element).Square(u2)
+
+	// v = -(D * u1^2) - u2_sqr
+	v := new(field.Element).Square(u1)
+	v.Multiply(v, feD)
+	v.Negate(v)
+	v.Subtract(v, u2Sqr)
+
+	invsqrt, wasSquare := new(field.Element).SqrtRatio(one, new(field.Element).Multiply(v, u2Sqr))
+
+	denX := new(field.Element).Multiply(invsqrt, u2)
+	denY := new(field.Element).Multiply(invsqrt, denX)
+	denY.Multiply(denY, v)
+
+	x := new(field.Element).Multiply(s, denX)
+	x.Add(x, x)
+	x.Absolute(x)
+	y := new(field.Element).Multiply(u1, denY)
+	t := new(field.Element).Multiply(x, y)
+
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(new(field.Element).Zero()) == 1 {
+		return nil, errors.New("set bytes failed [invalid encoding]")
+	}
+
+	g := &ristretto255G1{}
+	if _, err := g.Point.SetExtendedCoordinates(x, y, one, t); err != nil {
+		return nil, fmt.Errorf("set bytes failed [%s]", err.Error())
+	}
+
+	return g, nil
+}
+
+// NewG1FromCompressed accepts the same encoding as NewG1FromBytes since
+// ristretto255 elements have a single encoding.
+func (c *Ristretto255) NewG1FromCompressed(b []byte) (driver.G1, error) {
+	return c.NewG1FromBytes(b)
+}
+
+func (c *Ristretto255) NewG2FromBytes(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Ristretto255) NewG2FromCompressed(b []byte) (driver.G2, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Ristretto255) NewGtFromBytes(b []byte) (driver.Gt, error) {
+	return nil, driver.ErrUnsupported
+}
+
+func (c *Ristretto255) HashToG1(data []byte) driver.G1 {
+	return c.HashToG1WithDomain(data, []byte{})
+}
+
+// HashToG1WithDomain implements hash_to_ristretto255 of RFC 9380, i.e.
+// expand_message_xmd over SHA-512 followed by the one-way map of RFC 9496.
+func (c *Ristretto255) HashToG1WithDomain(data, domain []byte) driver.G1 {
+	g, _ := c.HashToG1WithU(data, domain)
+	return g
+}
+
+// HashToG1WithU returns the two field elements whose images under the
+// one-way map of RFC 9496, Section 4.3.4 add up to the hash.
+func (c *Ristretto255) HashToG1WithU(data, domain []byte) (driver.G1, []driver.Zr) {
+	b, err := gurvy.ExpandMsgXmd(data, domain, 2*encodingByteSize, sha512.New)
+	if err != nil {
+		panic(fmt.Sprintf("HashToG1 failed [%s]", err.Error()))
+	}
+
+	u := make([]driver.Zr, 2)
+	for i := range u {
+		t := reversed(b[i*encodingByteSize : (i+1)*encodingByteSize])
+		t[0] &= 0x7f
+
+		v := new(big.Int).SetBytes(t)
+		u[i] = &common.BaseZr{Int: *v.Mod(v, &fpModulus), Modulus: fpModulus}
+	}
+
+	return c.MapToG1(u), u
+}
+
+func (c *Ristretto255) MapToG1(u []driver.Zr) driver.G1 {
+	res := newG1()
+	for _, e := range u {
+		res.Point.Add(&res.Point, elligator(feFromBig(&e.(*common.BaseZr).Int)))
+	}
+
+	return res
+}
+
+// elligator is the MAP function of RFC 9496, Section 4.3.4
+func elligator(t *field.Element) *edwards25519.Point {
+	one := new(field.Element).One()
+	minusOne := new(field.Element).Negate(one)
+
+	// r = SQRT_M1 * t^2
+	r := new(field.Element).Square(t)
+	r.Multiply(r, feSqrtM1)
+
+	// u = (r + 1) * ONE_MINUS_D_SQ
+	u := new(field.Element).Add(r, one)
+	u.Multiply(u, feOneMinusDSq)
+
+	// v = (-1 - r*D) * (r + D)
+	v := new(field.Element).Multiply(r, feD)
+	v.Subtract(minusOne, v)
+	v.Multiply(v, new(field.Element).Add(r, feD))
+
+	s, wasSquare := new(field.Element).SqrtRatio(u, v)
+
+	sPrime := new(field.Element).Multiply(s, t)
+	sPrime.Absolute(sPrime)
+	sPrime.Negate(sPrime)
+
+	s.Select(s, sPrime, wasSquare)
+	c := new(field.Element).Select(minusOne, r, wasSquare)
+
+	// N = c * (r - 1) * D_MINUS_ONE_SQ - v
+	n := new(field.Element).Subtract(r, one)
+	n.Multiply(n, c)
+	n.Multiply(n, feDMinusOneSq)
+	n.Subtract(n, v)
+
+	sSq := new(field.Element).Square(s)
+	w0 := new(field.Element).Multiply(s, v)
+	w0.Add(w0, w0)
+	w1 := new(field.Element).Multiply(n, feSqrtADMinusOne)
+	w2 := new(field.Element).Subtract(one, sSq)
+	w3 := new(field.Element).Add(one, sSq)
+
+	p, err := new(edwards25519.Point).SetExtendedCoordinates(
+		new(field.Element).Multiply(w0, w3),
+		new(field.Element).Multiply(w2, w1),
+		new(field.Element).Multiply(w1, w3),
+		new(field.Element).Multiply(w0, w2))
+	if err != nil {
+		panic(fmt.Sprintf("map to curve failed [%s]", err.Error()))
+	}
+
+	return p
+}
+
+func (c *Ristretto255) HashToG2(data []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
+
+func (c *Ristretto255) HashToG2WithDomain(data, domain []byte) driver.G2 {
+	panic(driver.ErrUnsupported)
+}
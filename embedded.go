@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/pkg/errors"
+)
+
+// ErrBaseFieldMismatch is returned when the scalar field of the curve passed
+// as base is not the base field of an embedded curve.
+var ErrBaseFieldMismatch = errors.New("scalar field of the base curve is not the base field of the embedded curve")
+
+// embedding returns the driver of c, provided that c is embedded in base,
+// e.g. c is JUBJUB and base is one of the BLS12-381 curves.
+func (c *Curve) embedding(base *Curve) (driver.EmbeddedCurve, error) {
+	ec, ok := c.c.(driver.EmbeddedCurve)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	if base.Order().Cmp(ec.BaseFieldModulus()) != 0 {
+		return nil, ErrBaseFieldMismatch
+	}
+
+	return ec, nil
+}
+
+// ZrFromBaseField converts e, a scalar of base, to a scalar of the embedded
+// curve c by reducing it modulo the group order of c.
+func (c *Curve) ZrFromBaseField(e *Zr, base *Curve) (*Zr, error) {
+	if _, err := c.embedding(base); err != nil {
+		return nil, err
+	}
+
+	return c.NewZrFromBytes(e.Bytes()), nil
+}
+
+// ZrToBaseField converts z, a scalar of the embedded curve c, to a scalar of
+// base holding the same value: the group order of c is smaller than the
+// modulus of its base field, so no reduction takes place.
+func (c *Curve) ZrToBaseField(z *Zr, base *Curve) (*Zr, error) {
+	if _, err := c.embedding(base); err != nil {
+		return nil, err
+	}
+
+	return base.NewZrFromBytes(z.Bytes()), nil
+}
+
+// G1Coordinates returns the affine coordinates of g, an element of the
+// embedded curve c, as scalars of base.
+func (c *Curve) G1Coordinates(g *G1, base *Curve) (u, v *Zr, err error) {
+	ec, err := c.embedding(base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x, y := ec.G1Coordinates(g.g1)
+	return base.NewZrFromBytes(x.Bytes()), base.NewZrFromBytes(y.Bytes()), nil
+}
+
+// NewG1FromCoordinates is the inverse of G1Coordinates; it errors if (u, v)
+// is not an element of the prime order group of c.
+func (c *Curve) NewG1FromCoordinates(u, v *Zr, base *Curve) (*G1, error) {
+	ec, err := c.embedding(base)
+	if err != nil {
+		return nil, err
+	}
+
+	x := new(big.Int).SetBytes(u.Bytes())
+	y := new(big.Int).SetBytes(v.Bytes())
+	g, err := ec.NewG1FromCoordinates(x, y)
+	if err != nil {
+		return nil, decodeError("G1 from coordinates", c.curveID, err)
+	}
+
+	return &G1{g1: g, curve: c}, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// Errors returned, wrapped with the curve and operation involved, when
+// decoding an element fails; use errors.Is to tell them apart.
+// ErrNotOnCurve and ErrNotInSubgroup are those of package driver, which
+// drivers wrap to report these causes.
+var (
+	ErrInvalidLength   = errors.New("invalid length")
+	ErrInvalidEncoding = errors.New("invalid encoding")
+	ErrNotOnCurve      = driver.ErrNotOnCurve
+	ErrNotInSubgroup   = driver.ErrNotInSubgroup
+	ErrWrongCurve      = errors.New("wrong curve")
+)
+
+func curveName(id CurveID) string {
+	if id < 0 || int(id) >= len(Curves) {
+		return fmt.Sprintf("curve %d", id)
+	}
+
+	return CurveIDToString(id)
+}
+
+func lengthError(op string, id CurveID, got, want int) error {
+	return fmt.Errorf("mathlib: %s on %s: %w: got %d, want %d", op, curveName(id), ErrInvalidLength, got, want)
+}
+
+// decodeError wraps the error of a driver that failed to decode an element
+// with the curve and operation involved. Errors that wrap neither
+// ErrNotOnCurve nor ErrNotInSubgroup are reported as ErrInvalidEncoding.
+func decodeError(op string, id CurveID, err error) error {
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		return ErrUnsupported
+	case errors.Is(err, ErrNotOnCurve), errors.Is(err, ErrNotInSubgroup):
+		return fmt.Errorf("mathlib: %s on %s: %w", op, curveName(id), err)
+	}
+
+	return fmt.Errorf("mathlib: %s on %s: %w: %s", op, curveName(id), ErrInvalidEncoding, err)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// DefaultFixedBaseWindow is the window size in bits of the tables built by
+// NewFixedBaseG1 and NewFixedBaseG2 and of those behind GenG1Mul and
+// GenG2Mul: for a 255-bit order, 43 rows of 63 points each.
+const DefaultFixedBaseWindow = 6
+
+// FixedBaseG1 multiplies a fixed point by many scalars. It precomputes
+// [d*2^(w*i)]base for every w-bit digit d and row i, so that Mul only adds
+// one table point per digit of the scalar, with no doublings.
+type FixedBaseG1 struct {
+	table  [][]driver.G1
+	window int
+	curve  *Curve
+}
+
+// FixedBaseG2 is FixedBaseG1 for G2.
+type FixedBaseG2 struct {
+	table  [][]driver.G2
+	window int
+	curve  *Curve
+}
+
+// NewFixedBaseG1 precomputes the table of base with DefaultFixedBaseWindow.
+func (c *Curve) NewFixedBaseG1(base *G1) *FixedBaseG1 {
+	return c.NewFixedBaseG1Window(base, DefaultFixedBaseWindow)
+}
+
+// NewFixedBaseG1Window precomputes the table of base with w-bit windows,
+// trading (2^w-1)*ceil(bits/w) points of memory for ceil(bits/w) additions
+// per multiplication; it panics unless 1 <= w <= 16.
+func (c *Curve) NewFixedBaseG1Window(base *G1, w int) *FixedBaseG1 {
+	rows := c.fixedBaseRows(w)
+
+	f := &FixedBaseG1{table: make([][]driver.G1, rows), window: w, curve: c}
+	row := base.g1.Copy()
+	for i := range f.table {
+		f.table[i] = make([]driver.G1, 1<<w-1)
+		f.table[i][0] = row
+		for d := 1; d < len(f.table[i]); d++ {
+			f.table[i][d] = f.table[i][d-1].Copy()
+			f.table[i][d].Add(row)
+		}
+
+		// [2^w]row, the first entry of the next row
+		row = f.table[i][len(f.table[i])-1].Copy()
+		row.Add(f.table[i][0])
+	}
+
+	return f
+}
+
+// NewFixedBaseG2 precomputes the table of base with DefaultFixedBaseWindow.
+func (c *Curve) NewFixedBaseG2(base *G2) *FixedBaseG2 {
+	return c.NewFixedBaseG2Window(base, DefaultFixedBaseWindow)
+}
+
+// NewFixedBaseG2Window is NewFixedBaseG1Window for G2.
+func (c *Curve) NewFixedBaseG2Window(base *G2, w int) *FixedBaseG2 {
+	rows := c.fixedBaseRows(w)
+
+	f := &FixedBaseG2{table: make([][]driver.G2, rows), window: w, curve: c}
+	row := base.g2.Copy()
+	for i := range f.table {
+		f.table[i] = make([]driver.G2, 1<<w-1)
+		f.table[i][0] = row
+		for d := 1; d < len(f.table[i]); d++ {
+			f.table[i][d] = f.table[i][d-1].Copy()
+			f.table[i][d].Add(row)
+		}
+
+		row = f.table[i][len(f.table[i])-1].Copy()
+		row.Add(f.table[i][0])
+	}
+
+	return f
+}
+
+func (c *Curve) fixedBaseRows(w int) int {
+	if w < 1 || w > 16 {
+		panic(fmt.Sprintf("invalid fixed-base window [%d]", w))
+	}
+
+	bits := c.Order().BitLen()
+	return (bits + w - 1) / w
+}
+
+// Mul returns [x]base.
+func (f *FixedBaseG1) Mul(x *Zr) *G1 {
+	c := f.curve
+
+	k := new(big.Int).SetBytes(x.Bytes())
+	points := make([]driver.G1, 0, len(f.table))
+	for i, row := range f.table {
+		if d := msmDigit(k, uint(i*f.window), uint(f.window)); d != 0 {
+			points = append(points, row[d-1])
+		}
+	}
+
+	if len(points) == 0 {
+		return c.InfinityG1()
+	}
+
+	if s, ok := c.c.(driver.G1Summer); ok {
+		return &G1{g1: s.SumG1(points), curve: c}
+	}
+
+	res := points[0].Copy()
+	for _, p := range points[1:] {
+		res.Add(p)
+	}
+
+	return &G1{g1: res, curve: c}
+}
+
+// Mul returns [x]base.
+func (f *FixedBaseG2) Mul(x *Zr) *G2 {
+	c := f.curve
+
+	k := new(big.Int).SetBytes(x.Bytes())
+	points := make([]driver.G2, 0, len(f.table))
+	for i, row := range f.table {
+		if d := msmDigit(k, uint(i*f.window), uint(f.window)); d != 0 {
+			points = append(points, row[d-1])
+		}
+	}
+
+	return &G2{g2: c.c.SumG2(points), curve: c}
+}
+
+// GenG1Mul returns [x]GenG1 like GenG1.Mul(x), from a table of GenG1
+// precomputed on the first call.
+func (c *Curve) GenG1Mul(x *Zr) *G1 {
+	c.genG1Once.Do(func() {
+		c.genG1Table = c.NewFixedBaseG1(c.GenG1)
+	})
+
+	return c.genG1Table.Mul(x)
+}
+
+// GenG2Mul is GenG1Mul for GenG2.
+func (c *Curve) GenG2Mul(x *Zr) *G2 {
+	c.genG2Once.Do(func() {
+		c.genG2Table = c.NewFixedBaseG2(c.GenG2)
+	})
+
+	return c.genG2Table.Mul(x)
+}
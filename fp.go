@@ -0,0 +1,151 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/common"
+	"github.com/pkg/errors"
+)
+
+// Fp is an element of the base field of a curve, the field of the affine
+// coordinates of G1, e.g. for custom point compression or x-only protocols.
+// Its operations return new elements. The gnark drivers back it with their
+// own field arithmetic, the others with big.Int.
+type Fp struct {
+	fp    driver.Fp
+	curve *Curve
+}
+
+// NewFpFromBytes decodes the canonical big-endian encoding of an element of
+// the base field, CoordByteSize bytes long and smaller than the modulus
+// FieldModulusBytes. It returns ErrUnsupported if the driver provides
+// neither the field arithmetic nor the modulus.
+func (c *Curve) NewFpFromBytes(b []byte) (*Fp, error) {
+	p := c.FieldModulusBytes()
+	if p == nil {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.CoordByteSize {
+		return nil, lengthError("Fp decode", c.curveID, len(b), c.CoordByteSize)
+	}
+
+	if bytes.Compare(b, p) >= 0 {
+		return nil, fmt.Errorf("mathlib: Fp decode on %s: %w: element not smaller than the modulus", curveName(c.curveID), ErrInvalidEncoding)
+	}
+
+	var (
+		fp  driver.Fp
+		err error
+	)
+	if bf, ok := c.c.(driver.BaseFieldProvider); ok {
+		fp, err = bf.NewFpFromBytes(b)
+	} else {
+		fp, err = common.NewBaseFpFromBytes(b, new(big.Int).SetBytes(p), c.CoordByteSize)
+	}
+	if err != nil {
+		return nil, decodeError("Fp decode", c.curveID, err)
+	}
+
+	return &Fp{fp: fp, curve: c}, nil
+}
+
+func (e *Fp) CurveID() CurveID {
+	return e.curve.curveID
+}
+
+func (e *Fp) Add(a *Fp) *Fp {
+	return &Fp{fp: e.fp.Add(a.fp), curve: e.curve}
+}
+
+func (e *Fp) Sub(a *Fp) *Fp {
+	return &Fp{fp: e.fp.Sub(a.fp), curve: e.curve}
+}
+
+func (e *Fp) Mul(a *Fp) *Fp {
+	return &Fp{fp: e.fp.Mul(a.fp), curve: e.curve}
+}
+
+func (e *Fp) Neg() *Fp {
+	return &Fp{fp: e.fp.Neg(), curve: e.curve}
+}
+
+// Inverse returns the inverse of e; it fails if e is zero.
+func (e *Fp) Inverse() (*Fp, error) {
+	if e.fp.IsZero() {
+		return nil, errors.New("zero has no inverse")
+	}
+
+	return &Fp{fp: e.fp.Inverse(), curve: e.curve}, nil
+}
+
+// Sqrt returns a square root of e, or false if e is not a square. The
+// other root is its negation.
+func (e *Fp) Sqrt() (*Fp, bool) {
+	r, ok := e.fp.Sqrt()
+	if !ok {
+		return nil, false
+	}
+
+	return &Fp{fp: r, curve: e.curve}, true
+}
+
+func (e *Fp) Equals(a *Fp) bool {
+	return e.curve == a.curve && e.fp.Equals(a.fp)
+}
+
+func (e *Fp) IsZero() bool {
+	return e.fp.IsZero()
+}
+
+// Bytes returns the encoding that NewFpFromBytes reads, CoordByteSize bytes
+// long.
+func (e *Fp) Bytes() []byte {
+	return e.fp.Bytes()
+}
+
+func (e *Fp) String() string {
+	return e.fp.String()
+}
+
+// X returns the affine x coordinate of g, see G1.XY. It returns nil for the
+// point at infinity and on curves whose encoding does not hold the
+// coordinates.
+func (g *G1) X() *Fp {
+	x, _ := g.coordinates()
+	return x
+}
+
+// Y returns the affine y coordinate of g, like X.
+func (g *G1) Y() *Fp {
+	_, y := g.coordinates()
+	return y
+}
+
+func (g *G1) coordinates() (*Fp, *Fp) {
+	x, y, err := g.XY()
+	if err != nil {
+		return nil, nil
+	}
+
+	c := g.curve
+	fx, err := c.NewFpFromBytes(x.FillBytes(make([]byte, c.CoordByteSize)))
+	if err != nil {
+		return nil, nil
+	}
+	fy, err := c.NewFpFromBytes(y.FillBytes(make([]byte, c.CoordByteSize)))
+	if err != nil {
+		return nil, nil
+	}
+
+	return fx, fy
+}
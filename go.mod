@@ -3,15 +3,18 @@ module github.com/IBM/mathlib
 go 1.18
 
 require (
+	filippo.io/edwards25519 v1.1.0
+	github.com/cloudflare/circl v1.3.3
 	github.com/consensys/gnark-crypto v0.12.1
 	github.com/hyperledger/fabric-amcl v0.0.0-20230602173724-9e02669dceb2
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.8.2
+	github.com/supranational/blst v0.3.14
 	golang.org/x/crypto v0.10.0
 )
 
 require (
-	github.com/bits-and-blooms/bitset v1.7.0 // indirect
+	github.com/bits-and-blooms/bitset v1.7.0
 	github.com/consensys/bavard v0.1.13 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kilic/bls12-381 v0.1.0
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// CurveInfo describes the parameters of a curve, e.g. to pick one that
+// meets a security policy. Integers are encoded big-endian. Curves without
+// pairings have EmbeddingDegree 0 and no G2Cofactor.
+type CurveInfo struct {
+	SecurityLevelBits  int
+	EmbeddingDegree    int
+	G1Cofactor         []byte
+	G2Cofactor         []byte
+	BaseFieldModulus   []byte
+	ScalarFieldModulus []byte
+}
+
+// Info returns the parameters of the curve; it returns ErrUnsupported if
+// its driver does not implement driver.InfoProvider.
+func (c *Curve) Info() (*CurveInfo, error) {
+	ip, ok := c.c.(driver.InfoProvider)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	i := ip.Info()
+	info := &CurveInfo{
+		SecurityLevelBits:  i.SecurityLevelBits,
+		EmbeddingDegree:    i.EmbeddingDegree,
+		G1Cofactor:         i.G1Cofactor.Bytes(),
+		BaseFieldModulus:   i.BaseFieldModulus.Bytes(),
+		ScalarFieldModulus: i.ScalarFieldModulus.Bytes(),
+	}
+	if i.G2Cofactor != nil {
+		info.G2Cofactor = i.G2Cofactor.Bytes()
+	}
+
+	return info, nil
+}
+
+// FieldModulusBytes returns the modulus p of the base field, big-endian and
+// padded to CoordByteSize bytes, so that it can be compared with the encoded
+// coordinates of points: those are canonical if smaller than p. It returns
+// nil if the driver does not implement driver.InfoProvider.
+func (c *Curve) FieldModulusBytes() []byte {
+	ip, ok := c.c.(driver.InfoProvider)
+	if !ok {
+		return nil
+	}
+
+	return ip.Info().BaseFieldModulus.FillBytes(make([]byte, c.CoordByteSize))
+}
+
+// CofactorG1 returns the cofactor of G1 in the group of points of the
+// curve, e.g. to clear it from points built outside the library. It returns
+// nil if the driver does not implement driver.InfoProvider.
+func (c *Curve) CofactorG1() *big.Int {
+	ip, ok := c.c.(driver.InfoProvider)
+	if !ok {
+		return nil
+	}
+
+	return new(big.Int).Set(ip.Info().G1Cofactor)
+}
+
+// CofactorG2 is like CofactorG1 for G2; it also returns nil on curves
+// without pairings.
+func (c *Curve) CofactorG2() *big.Int {
+	ip, ok := c.c.(driver.InfoProvider)
+	if !ok {
+		return nil
+	}
+
+	h := ip.Info().G2Cofactor
+	if h == nil {
+		return nil
+	}
+
+	return new(big.Int).Set(h)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+	"github.com/IBM/mathlib/driver/amcl"
+	"github.com/pkg/errors"
+)
+
+// G1Layout describes the encoding returned by G1.Bytes. Drivers disagree on
+// it: amcl and the SEC1 curves put a 0x04 tag ahead of the coordinates,
+// while the zcash-style encodings of gnark, kilic, blst and circl fold
+// flags into the spare top bits of x.
+type G1Layout struct {
+	// XY reports whether Bytes holds the big-endian affine coordinates
+	// x || y, CoordByteSize bytes each; ristretto255 and Jubjub only have
+	// compressed encodings.
+	XY bool
+	// Prefix is the number of bytes ahead of x.
+	Prefix int
+	// FlagMask selects the bits of the first byte of x that are not part of
+	// the coordinate.
+	FlagMask byte
+}
+
+func g1Layout(d driver.Curve) G1Layout {
+	prefix := d.G1ByteSize() - 2*d.CoordinateByteSize()
+	if prefix < 0 {
+		return G1Layout{}
+	}
+
+	l := G1Layout{XY: true, Prefix: prefix}
+
+	ip, ok := d.(driver.InfoProvider)
+	if !ok {
+		return l
+	}
+
+	spare := 8*d.CoordinateByteSize() - ip.Info().BaseFieldModulus.BitLen()
+	if spare > 0 && spare < 8 {
+		l.FlagMask = ^byte(0xff >> spare)
+	}
+
+	return l
+}
+
+// G2Layout describes the encoding returned by G2.Bytes, whose coordinates
+// lie in an extension of degree Degree of the base field. Each is laid out
+// as Degree big-endian components of CoordByteSize bytes; on top of what
+// G1Layout says, the drivers disagree on their order.
+type G2Layout struct {
+	XY       bool
+	Prefix   int
+	FlagMask byte
+	Degree   int
+	// Reversed reports whether the components of a coordinate come from the
+	// highest to the lowest, e.g. c1 before c0 for x = c0 + c1*u, as in the
+	// zcash-style encodings; amcl lays them out the other way around.
+	Reversed bool
+}
+
+func g2Layout(d driver.Curve) G2Layout {
+	if ps, ok := d.(driver.PairingSupport); ok && !ps.SupportsPairing() {
+		return G2Layout{}
+	}
+
+	prefix := d.G2ByteSize() % (2 * d.CoordinateByteSize())
+	_, fabric := d.(*amcl.Fp256bn)
+
+	return G2Layout{
+		XY:       true,
+		Prefix:   prefix,
+		FlagMask: g1Layout(d).FlagMask,
+		Degree:   (d.G2ByteSize() - prefix) / (2 * d.CoordinateByteSize()),
+		Reversed: !fabric,
+	}
+}
+
+// XY returns the affine coordinates of g as laid out in Bytes, see
+// G1Layout. It returns ErrUnsupported on curves whose encoding does not
+// hold them, and an error for the point at infinity, which has none.
+func (g *G1) XY() (x, y *big.Int, err error) {
+	c := g.curve
+
+	if !c.G1Layout.XY {
+		return nil, nil, ErrUnsupported
+	}
+
+	if g.IsInfinity() {
+		return nil, nil, errors.New("the point at infinity has no affine coordinates")
+	}
+
+	b := g.Bytes()[c.G1Layout.Prefix:]
+	xb := append([]byte{}, b[:c.CoordByteSize]...)
+	xb[0] &^= c.G1Layout.FlagMask
+
+	return new(big.Int).SetBytes(xb), new(big.Int).SetBytes(b[c.CoordByteSize:]), nil
+}
+
+// XY returns the affine coordinates of g as laid out in Bytes, see
+// G2Layout, each as its components from the lowest to the highest. It
+// returns ErrUnsupported on curves without G2, and an error for the point
+// at infinity.
+func (g *G2) XY() (x, y []*big.Int, err error) {
+	c := g.curve
+
+	l := c.G2Layout
+	if !l.XY {
+		return nil, nil, ErrUnsupported
+	}
+
+	if g.Equals(c.InfinityG2()) {
+		return nil, nil, errors.New("the point at infinity has no affine coordinates")
+	}
+
+	b := append([]byte{}, g.Bytes()[l.Prefix:]...)
+	b[0] &^= l.FlagMask
+
+	coords := make([]*big.Int, 2*l.Degree)
+	for i := range coords {
+		coords[i] = new(big.Int).SetBytes(b[i*c.CoordByteSize : (i+1)*c.CoordByteSize])
+	}
+	x, y = coords[:l.Degree], coords[l.Degree:]
+	if l.Reversed {
+		reverse(x)
+		reverse(y)
+	}
+
+	return x, y, nil
+}
+
+func reverse(s []*big.Int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// NewG1FromXY returns the point with affine coordinates x and y, the
+// inverse of G1.XY; the point is checked as by NewG1FromBytes.
+func (c *Curve) NewG1FromXY(x, y *big.Int) (*G1, error) {
+	l := c.G1Layout
+	if !l.XY {
+		return nil, ErrUnsupported
+	}
+
+	b, err := c.putCoords("G1 decode", l.Prefix, l.FlagMask, []*big.Int{x, y})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG1FromBytes(b)
+}
+
+// NewG2FromXY returns the point with affine coordinates x and y, given as
+// by G2.XY; the point is checked as by NewG2FromBytes.
+func (c *Curve) NewG2FromXY(x, y []*big.Int) (*G2, error) {
+	l := c.G2Layout
+	if !l.XY {
+		return nil, ErrUnsupported
+	}
+
+	if len(x) != l.Degree || len(y) != l.Degree {
+		return nil, fmt.Errorf("mathlib: G2 decode on %s: %w: coordinates need %d components", curveName(c.curveID), ErrInvalidEncoding, l.Degree)
+	}
+
+	coords := make([]*big.Int, 0, 2*l.Degree)
+	coords = append(append(coords, x...), y...)
+	if l.Reversed {
+		reverse(coords[:l.Degree])
+		reverse(coords[l.Degree:])
+	}
+
+	b, err := c.putCoords("G2 decode", l.Prefix, l.FlagMask, coords)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewG2FromBytes(b)
+}
+
+// putCoords lays coords out after prefix bytes, which only SEC1 style
+// encodings have and tag with 0x04. The bits of mask must be left clear.
+func (c *Curve) putCoords(op string, prefix int, mask byte, coords []*big.Int) ([]byte, error) {
+	size := c.CoordByteSize
+	b := make([]byte, prefix+len(coords)*size)
+	if prefix > 0 {
+		b[0] = 0x04
+	}
+
+	for i, v := range coords {
+		if v == nil || v.Sign() < 0 || v.BitLen() > 8*size {
+			return nil, fmt.Errorf("mathlib: %s on %s: %w: coordinate out of range", op, curveName(c.curveID), ErrInvalidEncoding)
+		}
+		v.FillBytes(b[prefix+i*size : prefix+(i+1)*size])
+	}
+
+	if b[prefix]&mask != 0 {
+		return nil, fmt.Errorf("mathlib: %s on %s: %w: coordinate out of range", op, curveName(c.curveID), ErrInvalidEncoding)
+	}
+
+	return b, nil
+}
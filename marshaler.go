@@ -6,13 +6,35 @@ SPDX-License-Identifier: Apache-2.0
 
 package math
 
-import "encoding/json"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/IBM/mathlib/driver"
+)
 
 type curveElement struct {
 	CurveID      CurveID `json:"curve" validate:"required"`
 	ElementBytes []byte  `json:"element" validate:"required"`
 }
 
+// curveOf returns the curve with the given id, failing with ErrWrongCurve
+// if there is none and with ErrUnsupported if it is not part of this build.
+func curveOf(op string, id CurveID) (*Curve, error) {
+	if id < 0 || int(id) >= len(Curves) {
+		return nil, fmt.Errorf("mathlib: %s: %w: unknown curve %d", op, ErrWrongCurve, id)
+	}
+
+	if Curves[id] == nil {
+		return nil, fmt.Errorf("mathlib: %s: %w: %s is not built in", op, ErrUnsupported, CurveIDToString(id))
+	}
+
+	return Curves[id], nil
+}
+
 func (z *Zr) UnmarshalJSON(raw []byte) error {
 	ce := &curveElement{}
 	err := json.Unmarshal(raw, ce)
@@ -20,15 +42,20 @@ func (z *Zr) UnmarshalJSON(raw []byte) error {
 		return err
 	}
 
-	z.curveID = ce.CurveID
-	z.zr = Curves[z.curveID].NewZrFromBytes(ce.ElementBytes).zr
+	c, err := curveOf("Zr decode", ce.CurveID)
+	if err != nil {
+		return err
+	}
+
+	z.curve = c
+	z.zr = c.NewZrFromBytes(ce.ElementBytes).zr
 
 	return nil
 }
 
 func (z *Zr) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&curveElement{
-		CurveID:      z.curveID,
+		CurveID:      z.CurveID(),
 		ElementBytes: z.Bytes(),
 	})
 }
@@ -40,19 +67,24 @@ func (g *G1) UnmarshalJSON(raw []byte) error {
 		return err
 	}
 
-	g.curveID = ce.CurveID
-	g1, err := Curves[g.curveID].NewG1FromBytes(ce.ElementBytes)
+	c, err := curveOf("G1 decode", ce.CurveID)
+	if err != nil {
+		return err
+	}
+
+	g1, err := c.NewG1FromBytes(ce.ElementBytes)
 	if err != nil {
 		return err
 	}
 
+	g.curve = c
 	g.g1 = g1.g1
 	return nil
 }
 
 func (g *G1) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&curveElement{
-		CurveID:      g.curveID,
+		CurveID:      g.CurveID(),
 		ElementBytes: g.Bytes(),
 	})
 }
@@ -64,43 +96,244 @@ func (g *G2) UnmarshalJSON(raw []byte) error {
 		return err
 	}
 
-	g.curveID = ce.CurveID
-	g2, err := Curves[g.curveID].NewG2FromBytes(ce.ElementBytes)
+	c, err := curveOf("G2 decode", ce.CurveID)
+	if err != nil {
+		return err
+	}
+
+	g2, err := c.NewG2FromBytes(ce.ElementBytes)
 	if err != nil {
 		return err
 	}
 
+	g.curve = c
 	g.g2 = g2.g2
 	return nil
 }
 
 func (g *G2) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&curveElement{
-		CurveID:      g.curveID,
+		CurveID:      g.CurveID(),
 		ElementBytes: g.Bytes(),
 	})
 }
 
-func (g *Gt) UnmarshalJSON(raw []byte) error {
-	ce := &curveElement{}
-	err := json.Unmarshal(raw, ce)
+// affineG1 and affineG2 are the JSON objects of MarshalAffineJSON, with
+// the coordinates in hexadecimal; the point at infinity has none and sets
+// Infinity instead.
+type affineG1 struct {
+	CurveID  CurveID `json:"curve"`
+	X        string  `json:"x,omitempty"`
+	Y        string  `json:"y,omitempty"`
+	Infinity bool    `json:"infinity,omitempty"`
+}
+
+type affineG2 struct {
+	CurveID  CurveID  `json:"curve"`
+	X        []string `json:"x,omitempty"`
+	Y        []string `json:"y,omitempty"`
+	Infinity bool     `json:"infinity,omitempty"`
+}
+
+// MarshalAffineJSON encodes g as a JSON object with its affine coordinates,
+// {"curve": ..., "x": ..., "y": ...}, or {"curve": ..., "infinity": true}
+// for the point at infinity. It is meant for debugging and interchange;
+// MarshalJSON is more compact. It fails with ErrUnsupported where XY does.
+func (g *G1) MarshalAffineJSON() ([]byte, error) {
+	if g.IsInfinity() {
+		return json.Marshal(&affineG1{CurveID: g.CurveID(), Infinity: true})
+	}
+
+	x, y, err := g.XY()
 	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&affineG1{CurveID: g.CurveID(), X: x.Text(16), Y: y.Text(16)})
+}
+
+// UnmarshalAffineJSON decodes the encoding produced by MarshalAffineJSON.
+func (g *G1) UnmarshalAffineJSON(raw []byte) error {
+	a := &affineG1{}
+	if err := json.Unmarshal(raw, a); err != nil {
 		return err
 	}
 
-	g.curveID = ce.CurveID
-	gt, err := Curves[g.curveID].NewGtFromBytes(ce.ElementBytes)
+	c, err := curveOf("G1 decode", a.CurveID)
 	if err != nil {
 		return err
 	}
 
+	var g1 *G1
+	if a.Infinity {
+		g1 = c.InfinityG1()
+	} else {
+		coords, err := parseCoords("G1 decode", a.CurveID, []string{a.X, a.Y})
+		if err != nil {
+			return err
+		}
+
+		if g1, err = c.NewG1FromXY(coords[0], coords[1]); err != nil {
+			return err
+		}
+	}
+
+	g.curve = c
+	g.g1 = g1.g1
+	return nil
+}
+
+// MarshalAffineJSON is like G1.MarshalAffineJSON, with x and y given as
+// lists of components, see G2.XY.
+func (g *G2) MarshalAffineJSON() ([]byte, error) {
+	c := g.curve
+	if c.G2Layout.XY && g.Equals(c.InfinityG2()) {
+		return json.Marshal(&affineG2{CurveID: g.CurveID(), Infinity: true})
+	}
+
+	x, y, err := g.XY()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &affineG2{CurveID: g.CurveID()}
+	for i := range x {
+		a.X = append(a.X, x[i].Text(16))
+		a.Y = append(a.Y, y[i].Text(16))
+	}
+
+	return json.Marshal(a)
+}
+
+// UnmarshalAffineJSON decodes the encoding produced by MarshalAffineJSON.
+func (g *G2) UnmarshalAffineJSON(raw []byte) error {
+	a := &affineG2{}
+	if err := json.Unmarshal(raw, a); err != nil {
+		return err
+	}
+
+	c, err := curveOf("G2 decode", a.CurveID)
+	if err != nil {
+		return err
+	}
+
+	var g2 *G2
+	if a.Infinity {
+		g2 = c.InfinityG2()
+	} else {
+		x, err := parseCoords("G2 decode", a.CurveID, a.X)
+		if err != nil {
+			return err
+		}
+
+		y, err := parseCoords("G2 decode", a.CurveID, a.Y)
+		if err != nil {
+			return err
+		}
+
+		if g2, err = c.NewG2FromXY(x, y); err != nil {
+			return err
+		}
+	}
+
+	g.curve = c
+	g.g2 = g2.g2
+	return nil
+}
+
+func parseCoords(op string, id CurveID, hs []string) ([]*big.Int, error) {
+	coords := make([]*big.Int, len(hs))
+	for i, h := range hs {
+		v, ok := new(big.Int).SetString(h, 16)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("mathlib: %s on %s: %w: invalid coordinate %q", op, curveName(id), ErrInvalidEncoding, h)
+		}
+		coords[i] = v
+	}
+
+	return coords, nil
+}
+
+// UnmarshalJSON decodes the encoding produced by MarshalJSON, as well as
+// the {"curve": ..., "element": ...} object used by earlier versions.
+func (g *Gt) UnmarshalJSON(raw []byte) error {
+	var c *Curve
+	var b []byte
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		name, h, ok := strings.Cut(s, ":")
+		if !ok {
+			return fmt.Errorf("mathlib: Gt decode: %w: missing curve prefix", ErrInvalidEncoding)
+		}
+
+		id, ok := curveIDFromString(name)
+		if !ok {
+			return fmt.Errorf("mathlib: Gt decode: %w: unknown curve %q", ErrWrongCurve, name)
+		}
+		if c, err = curveOf("Gt decode", id); err != nil {
+			return err
+		}
+
+		if b, err = hex.DecodeString(h); err != nil {
+			return fmt.Errorf("mathlib: Gt decode on %s: %w: %s", name, ErrInvalidEncoding, err)
+		}
+	} else {
+		ce := &curveElement{}
+		if err := json.Unmarshal(raw, ce); err != nil {
+			return err
+		}
+
+		if c, err = curveOf("Gt decode", ce.CurveID); err != nil {
+			return err
+		}
+		b = ce.ElementBytes
+	}
+
+	gt, err := c.newGtFromJSON(b)
+	if err != nil {
+		return err
+	}
+
+	g.curve = gt.curve
 	g.gt = gt.gt
 	return nil
 }
 
+// MarshalJSON encodes g as a JSON string holding the name of its curve and
+// the hexadecimal encoding of g, separated by a colon. The encoding is the
+// compressed one on curves that provide it, and Bytes otherwise or if g
+// cannot be compressed.
 func (g *Gt) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&curveElement{
-		CurveID:      g.curveID,
-		ElementBytes: g.Bytes(),
-	})
+	b, err := g.curve.Compress(g)
+	if err != nil {
+		b = g.Bytes()
+	}
+
+	return json.Marshal(CurveIDToString(g.CurveID()) + ":" + hex.EncodeToString(b))
+}
+
+// newGtFromJSON decodes the bytes carried by the JSON encoding of a Gt
+// element, which are compressed unless they have the length of Bytes.
+func (c *Curve) newGtFromJSON(b []byte) (*Gt, error) {
+	if _, ok := c.c.(driver.GtCompressor); !ok || len(b) == c.GtByteSize {
+		return c.NewGtFromBytes(b)
+	}
+
+	e, err := c.Decompress(ElemGt, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.(*Gt), nil
+}
+
+func curveIDFromString(name string) (CurveID, bool) {
+	for i := range Curves {
+		if CurveIDToString(CurveID(i)) == name {
+			return CurveID(i), true
+		}
+	}
+
+	return 0, false
 }
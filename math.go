@@ -8,17 +8,33 @@ package math
 
 import (
 	"bytes"
-	"encoding/binary"
+	"crypto/sha256"
+	"crypto/subtle"
 	"fmt"
 	"io"
+	"math/big"
+	"math/bits"
+	"sync"
 
 	"github.com/IBM/mathlib/driver"
 	"github.com/IBM/mathlib/driver/amcl"
+	"github.com/IBM/mathlib/driver/circl"
+	"github.com/IBM/mathlib/driver/fp256bn"
 	"github.com/IBM/mathlib/driver/gurvy"
 	"github.com/IBM/mathlib/driver/kilic"
+	"github.com/IBM/mathlib/driver/nist"
+	"github.com/IBM/mathlib/driver/ristretto"
 	"github.com/pkg/errors"
 )
 
+// ErrUnsupported is returned, or used as panic value where no error can be
+// returned, by curves that lack an operation; see Curve.SupportsPairing.
+var ErrUnsupported = driver.ErrUnsupported
+
+// ErrPairingLengthMismatch is returned by PairingN when it is not given as
+// many G1 as G2 elements.
+var ErrPairingLengthMismatch = errors.New("pairing inputs have different lengths")
+
 type CurveID int
 
 const (
@@ -30,6 +46,14 @@ const (
 	BLS12_381_GURVY
 	BLS12_381_BBS
 	BLS12_381_BBS_GURVY
+	BLS12_381_BLST
+	BLS12_381_CIRCL
+	BLS24_315_GURVY
+	SECP256K1
+	RISTRETTO255
+	P256
+	JUBJUB
+	FP256BN
 )
 
 func CurveIDToString(id CurveID) string {
@@ -50,157 +74,164 @@ func CurveIDToString(id CurveID) string {
 		return "BLS12_381_BBS"
 	case BLS12_381_BBS_GURVY:
 		return "BLS12_381_BBS_GURVY"
+	case BLS12_381_BLST:
+		return "BLS12_381_BLST"
+	case BLS12_381_CIRCL:
+		return "BLS12_381_CIRCL"
+	case BLS24_315_GURVY:
+		return "BLS24_315_GURVY"
+	case SECP256K1:
+		return "SECP256K1"
+	case RISTRETTO255:
+		return "RISTRETTO255"
+	case P256:
+		return "P256"
+	case JUBJUB:
+		return "JUBJUB"
+	case FP256BN:
+		return "FP256BN"
 	default:
 		panic(fmt.Sprintf("unknown curve %d", id))
 	}
 }
 
+// Curves holds the supported curves, indexed by CurveID. The entry of
+// BLS12_381_BLST is nil unless the module is built with cgo and the
+// mathlib_blst tag.
 var Curves []*Curve = []*Curve{
-	{
-		c:                    amcl.NewFp256bn(),
-		GenG1:                &G1{g1: (&amcl.Fp256bn{}).GenG1(), curveID: FP256BN_AMCL},
-		GenG2:                &G2{g2: (&amcl.Fp256bn{}).GenG2(), curveID: FP256BN_AMCL},
-		GenGt:                &Gt{gt: (&amcl.Fp256bn{}).GenGt(), curveID: FP256BN_AMCL},
-		GroupOrder:           &Zr{zr: amcl.NewFp256bn().GroupOrder(), curveID: FP256BN_AMCL},
-		CoordByteSize:        (&amcl.Fp256bn{}).CoordinateByteSize(),
-		G1ByteSize:           (&amcl.Fp256bn{}).G1ByteSize(),
-		CompressedG1ByteSize: (&amcl.Fp256bn{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&amcl.Fp256bn{}).G2ByteSize(),
-		CompressedG2ByteSize: (&amcl.Fp256bn{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&amcl.Fp256bn{}).ScalarByteSize(),
-		curveID:              FP256BN_AMCL,
-	},
-	{
-		c:                    gurvy.NewBn254(),
-		GenG1:                &G1{g1: (&gurvy.Bn254{}).GenG1(), curveID: BN254},
-		GenG2:                &G2{g2: (&gurvy.Bn254{}).GenG2(), curveID: BN254},
-		GenGt:                &Gt{gt: (&gurvy.Bn254{}).GenGt(), curveID: BN254},
-		GroupOrder:           &Zr{zr: gurvy.NewBn254().GroupOrder(), curveID: BN254},
-		CoordByteSize:        (&gurvy.Bn254{}).CoordinateByteSize(),
-		G1ByteSize:           (&gurvy.Bn254{}).G1ByteSize(),
-		CompressedG1ByteSize: (&gurvy.Bn254{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&gurvy.Bn254{}).G2ByteSize(),
-		CompressedG2ByteSize: (&gurvy.Bn254{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&gurvy.Bn254{}).ScalarByteSize(),
-		curveID:              BN254,
-	},
-	{
-		c:                    amcl.NewFp256Miraclbn(),
-		GenG1:                &G1{g1: (&amcl.Fp256Miraclbn{}).GenG1(), curveID: FP256BN_AMCL_MIRACL},
-		GenG2:                &G2{g2: (&amcl.Fp256Miraclbn{}).GenG2(), curveID: FP256BN_AMCL_MIRACL},
-		GenGt:                &Gt{gt: (&amcl.Fp256Miraclbn{}).GenGt(), curveID: FP256BN_AMCL_MIRACL},
-		GroupOrder:           &Zr{zr: amcl.NewFp256Miraclbn().GroupOrder(), curveID: FP256BN_AMCL_MIRACL},
-		CoordByteSize:        (&amcl.Fp256Miraclbn{}).CoordinateByteSize(),
-		G1ByteSize:           (&amcl.Fp256Miraclbn{}).G1ByteSize(),
-		CompressedG1ByteSize: (&amcl.Fp256Miraclbn{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&amcl.Fp256Miraclbn{}).G2ByteSize(),
-		CompressedG2ByteSize: (&amcl.Fp256Miraclbn{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&amcl.Fp256Miraclbn{}).ScalarByteSize(),
-		curveID:              FP256BN_AMCL_MIRACL,
-	},
-	{
-		c:                    kilic.NewBls12_381(),
-		GenG1:                &G1{g1: (&kilic.Bls12_381{}).GenG1(), curveID: BLS12_381},
-		GenG2:                &G2{g2: (&kilic.Bls12_381{}).GenG2(), curveID: BLS12_381},
-		GenGt:                &Gt{gt: (&kilic.Bls12_381{}).GenGt(), curveID: BLS12_381},
-		GroupOrder:           &Zr{zr: kilic.NewBls12_381().GroupOrder(), curveID: BLS12_381},
-		CoordByteSize:        (&kilic.Bls12_381{}).CoordinateByteSize(),
-		G1ByteSize:           (&kilic.Bls12_381{}).G1ByteSize(),
-		CompressedG1ByteSize: (&kilic.Bls12_381{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&kilic.Bls12_381{}).G2ByteSize(),
-		CompressedG2ByteSize: (&kilic.Bls12_381{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&kilic.Bls12_381{}).ScalarByteSize(),
-		curveID:              BLS12_381,
-	},
-	{
-		c:                    gurvy.NewBls12_377(),
-		GenG1:                &G1{g1: (&gurvy.Bls12_377{}).GenG1(), curveID: BLS12_377_GURVY},
-		GenG2:                &G2{g2: (&gurvy.Bls12_377{}).GenG2(), curveID: BLS12_377_GURVY},
-		GenGt:                &Gt{gt: (&gurvy.Bls12_377{}).GenGt(), curveID: BLS12_377_GURVY},
-		GroupOrder:           &Zr{zr: gurvy.NewBls12_377().GroupOrder(), curveID: BLS12_377_GURVY},
-		CoordByteSize:        (&gurvy.Bls12_377{}).CoordinateByteSize(),
-		G1ByteSize:           (&gurvy.Bls12_377{}).G1ByteSize(),
-		CompressedG1ByteSize: (&gurvy.Bls12_377{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&gurvy.Bls12_377{}).G2ByteSize(),
-		CompressedG2ByteSize: (&gurvy.Bls12_377{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&gurvy.Bls12_377{}).ScalarByteSize(),
-		curveID:              BLS12_377_GURVY,
-	},
-	{
-		c:                    gurvy.NewBls12_381(),
-		GenG1:                &G1{g1: (&gurvy.Bls12_381{}).GenG1(), curveID: BLS12_381_GURVY},
-		GenG2:                &G2{g2: (&gurvy.Bls12_381{}).GenG2(), curveID: BLS12_381_GURVY},
-		GenGt:                &Gt{gt: (&gurvy.Bls12_381{}).GenGt(), curveID: BLS12_381_GURVY},
-		GroupOrder:           &Zr{zr: gurvy.NewBls12_381().GroupOrder(), curveID: BLS12_381_GURVY},
-		CoordByteSize:        (&gurvy.Bls12_381{}).CoordinateByteSize(),
-		G1ByteSize:           (&gurvy.Bls12_381{}).G1ByteSize(),
-		CompressedG1ByteSize: (&gurvy.Bls12_381{}).CompressedG1ByteSize(),
-		G2ByteSize:           (&gurvy.Bls12_381{}).G2ByteSize(),
-		CompressedG2ByteSize: (&gurvy.Bls12_381{}).CompressedG2ByteSize(),
-		ScalarByteSize:       (&gurvy.Bls12_381{}).ScalarByteSize(),
-		curveID:              BLS12_381_GURVY,
-	},
-	{
-		c:                    kilic.NewBls12_381BBS(),
-		GenG1:                &G1{g1: kilic.NewBls12_381BBS().GenG1(), curveID: BLS12_381_BBS},
-		GenG2:                &G2{g2: kilic.NewBls12_381BBS().GenG2(), curveID: BLS12_381_BBS},
-		GenGt:                &Gt{gt: kilic.NewBls12_381BBS().GenGt(), curveID: BLS12_381_BBS},
-		GroupOrder:           &Zr{zr: kilic.NewBls12_381().GroupOrder(), curveID: BLS12_381_BBS},
-		CoordByteSize:        kilic.NewBls12_381BBS().CoordinateByteSize(),
-		G1ByteSize:           kilic.NewBls12_381BBS().G1ByteSize(),
-		CompressedG1ByteSize: kilic.NewBls12_381BBS().CompressedG1ByteSize(),
-		G2ByteSize:           kilic.NewBls12_381BBS().G2ByteSize(),
-		CompressedG2ByteSize: kilic.NewBls12_381BBS().CompressedG2ByteSize(),
-		ScalarByteSize:       kilic.NewBls12_381BBS().ScalarByteSize(),
-		curveID:              BLS12_381_BBS,
-	},
-	{
-		c:                    gurvy.NewBls12_381BBS(),
-		GenG1:                &G1{g1: gurvy.NewBls12_381BBS().GenG1(), curveID: BLS12_381_BBS_GURVY},
-		GenG2:                &G2{g2: gurvy.NewBls12_381BBS().GenG2(), curveID: BLS12_381_BBS_GURVY},
-		GenGt:                &Gt{gt: gurvy.NewBls12_381BBS().GenGt(), curveID: BLS12_381_BBS_GURVY},
-		GroupOrder:           &Zr{zr: gurvy.NewBls12_381().GroupOrder(), curveID: BLS12_381_BBS_GURVY},
-		CoordByteSize:        gurvy.NewBls12_381BBS().CoordinateByteSize(),
-		G1ByteSize:           gurvy.NewBls12_381BBS().G1ByteSize(),
-		CompressedG1ByteSize: gurvy.NewBls12_381BBS().CompressedG1ByteSize(),
-		G2ByteSize:           gurvy.NewBls12_381BBS().G2ByteSize(),
-		CompressedG2ByteSize: gurvy.NewBls12_381BBS().CompressedG2ByteSize(),
-		ScalarByteSize:       gurvy.NewBls12_381BBS().ScalarByteSize(),
-		curveID:              BLS12_381_BBS_GURVY,
-	},
+	newCurve(FP256BN_AMCL, amcl.NewFp256bn()),
+	newCurve(BN254, gurvy.NewBn254()),
+	newCurve(FP256BN_AMCL_MIRACL, amcl.NewFp256Miraclbn()),
+	newCurve(BLS12_381, kilic.NewBls12_381()),
+	newCurve(BLS12_377_GURVY, gurvy.NewBls12_377()),
+	newCurve(BLS12_381_GURVY, gurvy.NewBls12_381()),
+	newCurve(BLS12_381_BBS, kilic.NewBls12_381BBS()),
+	newCurve(BLS12_381_BBS_GURVY, gurvy.NewBls12_381BBS()),
+	newBLSTCurve(),
+	newCurve(BLS12_381_CIRCL, circl.NewBls12_381()),
+	newCurve(BLS24_315_GURVY, gurvy.NewBls24_315()),
+	newCurve(SECP256K1, gurvy.NewSecp256k1()),
+	newCurve(RISTRETTO255, ristretto.NewRistretto255()),
+	newCurve(P256, nist.NewP256()),
+	newCurve(JUBJUB, gurvy.NewJubjub()),
+	newCurve(FP256BN, fp256bn.NewFp256bn()),
+}
+
+func newCurve(id CurveID, d driver.Curve) *Curve {
+	c := &Curve{
+		c:                    d,
+		CoordByteSize:        d.CoordinateByteSize(),
+		G1ByteSize:           d.G1ByteSize(),
+		CompressedG1ByteSize: d.CompressedG1ByteSize(),
+		G2ByteSize:           d.G2ByteSize(),
+		CompressedG2ByteSize: d.CompressedG2ByteSize(),
+		GtByteSize:           d.GtByteSize(),
+		ScalarByteSize:       d.ScalarByteSize(),
+		G1Layout:             g1Layout(d),
+		G2Layout:             g2Layout(d),
+		curveID:              id,
+	}
+	c.GenG1 = &G1{g1: d.GenG1(), curve: c}
+	c.GenG2 = &G2{g2: d.GenG2(), curve: c}
+	c.GenGt = &Gt{gt: d.GenGt(), curve: c}
+	c.GroupOrder = &Zr{zr: d.GroupOrder(), curve: c}
+
+	c.infinityG1ByteSize = c.G1ByteSize
+	c.compressedInfinityG1ByteSize = c.CompressedG1ByteSize
+	c.infinityG2ByteSize = c.G2ByteSize
+	c.compressedInfinityG2ByteSize = c.CompressedG2ByteSize
+	if ip, ok := d.(driver.InfoProvider); ok {
+		i := ip.Info()
+		c.infinityG1ByteSize = sizeOr(i.InfinityG1ByteSize, c.infinityG1ByteSize)
+		c.compressedInfinityG1ByteSize = sizeOr(i.CompressedInfinityG1ByteSize, c.compressedInfinityG1ByteSize)
+		c.infinityG2ByteSize = sizeOr(i.InfinityG2ByteSize, c.infinityG2ByteSize)
+		c.compressedInfinityG2ByteSize = sizeOr(i.CompressedInfinityG2ByteSize, c.compressedInfinityG2ByteSize)
+	}
+
+	return c
+}
+
+// sizeOr returns n, or def if n is zero.
+func sizeOr(n, def int) int {
+	if n == 0 {
+		return def
+	}
+
+	return n
+}
+
+// NewCurveFromDriver wraps a custom driver implementation, populating the
+// generators, group order and byte sizes by querying the driver. The
+// returned curve is not added to Curves; id is only used to tag the
+// elements it creates. Panics of the driver while it is queried are bugs
+// and are not recovered.
+func NewCurveFromDriver(id CurveID, d driver.Curve) (*Curve, error) {
+	if d == nil {
+		return nil, errors.New("nil driver")
+	}
+
+	return newCurve(id, d), nil
+}
+
+// SupportsPairing reports whether the curve provides G2, Gt and pairings;
+// curves that only provide the group G1 panic with ErrUnsupported on those.
+func (c *Curve) SupportsPairing() bool {
+	ps, ok := c.c.(driver.PairingSupport)
+	return !ok || ps.SupportsPairing()
 }
 
 /*********************************************************************/
 
 type Zr struct {
-	zr      driver.Zr
-	curveID CurveID
+	zr    driver.Zr
+	curve *Curve
 }
 
 func (z *Zr) CurveID() CurveID {
-	return z.curveID
+	return z.curve.curveID
 }
 
 func (z *Zr) Plus(a *Zr) *Zr {
-	return &Zr{zr: z.zr.Plus(a.zr), curveID: z.curveID}
+	return &Zr{zr: z.zr.Plus(a.zr), curve: z.curve}
 }
 
 func (z *Zr) Minus(a *Zr) *Zr {
-	return &Zr{zr: z.zr.Minus(a.zr), curveID: z.curveID}
+	return &Zr{zr: z.zr.Minus(a.zr), curve: z.curve}
 }
 
 func (z *Zr) Mul(a *Zr) *Zr {
-	return &Zr{zr: z.zr.Mul(a.zr), curveID: z.curveID}
+	return &Zr{zr: z.zr.Mul(a.zr), curve: z.curve}
+}
+
+// AddInPlace sets z to z + a modulo the group order; unlike Plus it does
+// not allocate a new Zr.
+func (z *Zr) AddInPlace(a *Zr) {
+	z.zr.AddInPlace(a.zr)
+}
+
+// SubInPlace sets z to z - a modulo the group order; unlike Minus it does
+// not allocate a new Zr.
+func (z *Zr) SubInPlace(a *Zr) {
+	z.zr.SubInPlace(a.zr)
 }
 
+// MulInPlace sets z to z * a modulo the group order; unlike Mul it does not
+// allocate a new Zr.
+func (z *Zr) MulInPlace(a *Zr) {
+	z.zr.MulInPlace(a.zr)
+}
+
+// Mod reduces z modulo a in place; see Modded for a non-mutating variant.
 func (z *Zr) Mod(a *Zr) {
 	z.zr.Mod(a.zr)
 }
 
 func (z *Zr) PowMod(a *Zr) *Zr {
-	return &Zr{zr: z.zr.PowMod(a.zr), curveID: z.curveID}
+	return &Zr{zr: z.zr.PowMod(a.zr), curve: z.curve}
 }
 
+// InvModP sets z to its inverse modulo a in place; see Inverted for a
+// non-mutating variant.
 func (z *Zr) InvModP(a *Zr) {
 	z.zr.InvModP(a.zr)
 }
@@ -209,14 +240,30 @@ func (z *Zr) Bytes() []byte {
 	return z.zr.Bytes()
 }
 
+// BytesLE returns the little-endian encoding of z, i.e. Bytes reversed.
+func (z *Zr) BytesLE() []byte {
+	return reversed(z.zr.Bytes())
+}
+
+// BytesInto writes the serialization of z into dst and returns the number
+// of bytes written; it errors if dst is too short.
+func (z *Zr) BytesInto(dst []byte) (int, error) {
+	if p, ok := z.zr.(driver.BytesPutter); ok && len(dst) >= z.curve.ScalarByteSize {
+		return p.PutBytes(dst), nil
+	}
+
+	return bytesInto(dst, z.zr.Bytes())
+}
+
 func (z *Zr) Equals(a *Zr) bool {
 	return z.zr.Equals(a.zr)
 }
 
 func (z *Zr) Copy() *Zr {
-	return &Zr{zr: z.zr.Copy(), curveID: z.curveID}
+	return &Zr{zr: z.zr.Copy(), curve: z.curve}
 }
 
+// Clone sets z to the value of a.
 func (z *Zr) Clone(a *Zr) {
 	z.zr.Clone(a.zr)
 }
@@ -225,40 +272,157 @@ func (z *Zr) String() string {
 	return z.zr.String()
 }
 
+// Text returns the representation of z in the given base, as big.Int.Text
+// does; String is Text(16).
+func (z *Zr) Text(base int) string {
+	return new(big.Int).SetBytes(z.Bytes()).Text(base)
+}
+
+// Bits returns the n lowest binary digits of z modulo the group order, the
+// least significant first, as taken by NewZrFromBits. It fails with
+// ErrInvalidLength if n exceeds the number of bits in the group order or if
+// z does not fit in n bits.
+func (z *Zr) Bits(n int) ([]bool, error) {
+	const op = "Zr to bits"
+
+	c := z.curve
+	if max := c.Order().BitLen(); n < 0 || n > max {
+		return nil, fmt.Errorf("mathlib: %s on %s: %w: got %d bits, want at most %d", op, curveName(c.curveID), ErrInvalidLength, n, max)
+	}
+
+	k := new(big.Int).SetBytes(z.Modded(c.GroupOrder).Bytes())
+	if k.BitLen() > n {
+		return nil, fmt.Errorf("mathlib: %s on %s: %w: %d bits do not fit in %d", op, curveName(c.curveID), ErrInvalidLength, k.BitLen(), n)
+	}
+
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = k.Bit(i) == 1
+	}
+
+	return bits, nil
+}
+
+// Neg negates z in place; see Negated for a non-mutating variant.
 func (z *Zr) Neg() {
 	z.zr.Neg()
 }
 
-var zerobytes = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
-var onebytes = []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}
+// Modded returns z modulo m, leaving z untouched.
+func (z *Zr) Modded(m *Zr) *Zr {
+	r := z.Copy()
+	r.Mod(m)
+	return r
+}
 
-func (z *Zr) Uint() (uint64, error) {
-	b := z.Bytes()
-	if !bytes.Equal(zerobytes, b[:32-8]) && !bytes.Equal(onebytes, b[:32-8]) {
-		return 0, fmt.Errorf("out of range")
+// Inverted returns the inverse of z modulo m, leaving z untouched.
+func (z *Zr) Inverted(m *Zr) *Zr {
+	r := z.Copy()
+	r.InvModP(m)
+	return r
+}
+
+// Negated returns -z, leaving z untouched.
+func (z *Zr) Negated() *Zr {
+	r := z.Copy()
+	r.Neg()
+	return r
+}
+
+// Reduce reduces z modulo the group order in place. Plus, Minus and
+// similar operations defer reduction; Bytes, Equals and String already
+// act on the reduced value, so Reduce is only needed to bound the size
+// of the internal representation.
+func (z *Zr) Reduce() {
+	z.zr.Mod(z.curve.GroupOrder.zr)
+}
+
+// Reduced returns a copy of z reduced modulo the group order.
+func (z *Zr) Reduced() *Zr {
+	r := z.Copy()
+	r.Reduce()
+	return r
+}
+
+func (z *Zr) Halve() *Zr {
+	return &Zr{zr: z.zr.Halve(), curve: z.curve}
+}
+
+// IsNegative reports whether the internal representation of z is
+// negative, as may happen after Neg or Minus and before any reduction.
+func (z *Zr) IsNegative() bool {
+	return z.zr.IsNegative()
+}
+
+// Signed returns the representative of z in (-order/2, order/2].
+func (z *Zr) Signed() *big.Int {
+	return z.zr.Signed()
+}
+
+// IsZero reports whether z is 0 modulo the group order.
+func (z *Zr) IsZero() bool {
+	return z.Signed().Sign() == 0
+}
+
+// IsOne reports whether z is 1 modulo the group order.
+func (z *Zr) IsOne() bool {
+	v := z.Signed()
+	return v.IsInt64() && v.Int64() == 1
+}
+
+// CmpAbs compares the magnitudes of the signed representatives of z and a
+// (see Signed), returning -1, 0 or +1 as |z| is smaller than, equal to or
+// larger than |a|; z and its negation have the same magnitude.
+func (z *Zr) CmpAbs(a *Zr) int {
+	return z.zr.CmpAbs(a.zr)
+}
+
+// Uint64 returns z as a uint64 if its reduced value is smaller than 2^64.
+func (z *Zr) Uint64() (uint64, error) {
+	v := new(big.Int).SetBytes(z.Bytes())
+	if !v.IsUint64() {
+		return 0, fmt.Errorf("out of range: value has bit length %d", v.BitLen())
 	}
 
-	return uint64(binary.BigEndian.Uint64(b[32-8:])), nil
+	return v.Uint64(), nil
 }
 
-func (z *Zr) Int() (int64, error) {
-	b := z.Bytes()
-	if !bytes.Equal(zerobytes, b[:32-8]) && !bytes.Equal(onebytes, b[:32-8]) {
-		return 0, fmt.Errorf("out of range")
+// Int64 returns z as an int64 if its signed representative (see Signed)
+// lies in [-2^63, 2^63).
+func (z *Zr) Int64() (int64, error) {
+	v := z.Signed()
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("out of range: value has bit length %d", v.BitLen())
 	}
 
-	return int64(binary.BigEndian.Uint64(b[32-8:])), nil
+	return v.Int64(), nil
+}
+
+// ProbablyPrime reports whether the value of z, as returned by Bytes, is
+// probably prime; see big.Int.ProbablyPrime for the meaning of n.
+func (z *Zr) ProbablyPrime(n int) bool {
+	return new(big.Int).SetBytes(z.Bytes()).ProbablyPrime(n)
+}
+
+// Uint is an alias of Uint64.
+func (z *Zr) Uint() (uint64, error) {
+	return z.Uint64()
+}
+
+// Int is an alias of Int64.
+func (z *Zr) Int() (int64, error) {
+	return z.Int64()
 }
 
 /*********************************************************************/
 
 type G1 struct {
-	g1      driver.G1
-	curveID CurveID
+	g1    driver.G1
+	curve *Curve
 }
 
 func (g *G1) CurveID() CurveID {
-	return g.curveID
+	return g.curve.curveID
 }
 
 func (g *G1) Clone(a *G1) {
@@ -266,7 +430,7 @@ func (g *G1) Clone(a *G1) {
 }
 
 func (g *G1) Copy() *G1 {
-	return &G1{g1: g.g1.Copy(), curveID: g.curveID}
+	return &G1{g1: g.g1.Copy(), curve: g.curve}
 }
 
 func (g *G1) Add(a *G1) {
@@ -274,11 +438,42 @@ func (g *G1) Add(a *G1) {
 }
 
 func (g *G1) Mul(a *Zr) *G1 {
-	return &G1{g1: g.g1.Mul(a.zr), curveID: g.curveID}
+	return &G1{g1: g.g1.Mul(a.zr), curve: g.curve}
+}
+
+// MulInt64 returns [k]g. For |k| up to smallScalar it adds and doubles
+// instead of going through the scalar multiplication of the driver, which
+// costs as much for k = 2 as for a full size scalar.
+func (g *G1) MulInt64(k int64) *G1 {
+	if k < -smallScalar || k > smallScalar {
+		return g.Mul(g.curve.NewZrFromInt(k))
+	}
+
+	if k == 0 {
+		return g.curve.InfinityG1()
+	}
+
+	res := g.Copy()
+	for _, bit := range smallScalarBits(k) {
+		res.Add(res.Copy())
+		if bit {
+			res.Add(g)
+		}
+	}
+	if k < 0 {
+		res.Neg()
+	}
+
+	return res
 }
 
 func (g *G1) Mul2(e *Zr, Q *G1, f *Zr) *G1 {
-	return &G1{g1: g.g1.Mul2(e.zr, Q.g1, f.zr), curveID: g.curveID}
+	return &G1{g1: g.g1.Mul2(e.zr, Q.g1, f.zr), curve: g.curve}
+}
+
+// Mul3 returns [a]g + [b]Q + [c]R, see Curve.LinCombG1.
+func (g *G1) Mul3(a *Zr, Q *G1, b *Zr, R *G1, c *Zr) *G1 {
+	return g.curve.LinCombG1([]*G1{g, Q, R}, []*Zr{a, b, c})
 }
 
 func (g *G1) Equals(a *G1) bool {
@@ -293,6 +488,26 @@ func (g *G1) Compressed() []byte {
 	return g.g1.Compressed()
 }
 
+// BytesInto writes the serialization of g into dst and returns the number
+// of bytes written; it errors if dst is too short. It does not allocate on
+// drivers whose elements implement driver.BytesPutter.
+func (g *G1) BytesInto(dst []byte) (int, error) {
+	if p, ok := g.g1.(driver.BytesPutter); ok && len(dst) >= g.curve.G1ByteSize {
+		return p.PutBytes(dst), nil
+	}
+
+	return bytesInto(dst, g.g1.Bytes())
+}
+
+// CompressedInto is like BytesInto for the compressed serialization.
+func (g *G1) CompressedInto(dst []byte) (int, error) {
+	if p, ok := g.g1.(driver.CompressedPutter); ok && len(dst) >= g.curve.CompressedG1ByteSize {
+		return p.PutCompressed(dst), nil
+	}
+
+	return bytesInto(dst, g.g1.Compressed())
+}
+
 func (g *G1) Sub(a *G1) {
 	g.g1.Sub(a.g1)
 }
@@ -301,6 +516,20 @@ func (g *G1) IsInfinity() bool {
 	return g.g1.IsInfinity()
 }
 
+// IsGenerator reports whether g equals the generator of G1 without
+// copying it as Equals(GenG1) would.
+func (g *G1) IsGenerator() bool {
+	return g.g1.IsGenerator()
+}
+
+// IsOnCurve reports whether g satisfies the curve equation. Together with
+// Curve.BatchInSubgroupG1 it tells a point off the curve from one of the
+// wrong subgroup, e.g. after NewG1FromBytesUnchecked; the points returned by
+// checked decoders and by arithmetic on them always are on the curve.
+func (g *G1) IsOnCurve() bool {
+	return g.g1.IsOnCurve()
+}
+
 func (g *G1) String() string {
 	return g.g1.String()
 }
@@ -309,15 +538,39 @@ func (g *G1) Neg() {
 	g.g1.Neg()
 }
 
+// AddDeferred adds all of points to g, as a sequence of Add does. On drivers
+// implementing driver.G1Summer, e.g. the gnark ones, g is converted to
+// projective coordinates and back once for all the additions rather than
+// once per addition.
+func (g *G1) AddDeferred(points ...*G1) {
+	s, ok := g.curve.c.(driver.G1Summer)
+	if !ok {
+		for _, p := range points {
+			g.Add(p)
+		}
+		return
+	}
+
+	all := make([]driver.G1, 0, len(points)+1)
+	all = append(all, g.g1)
+	for _, p := range points {
+		all = append(all, p.g1)
+	}
+
+	// the sum is a fresh point: taking it over skips Clone, which goes
+	// through the encoding on some drivers
+	g.g1 = s.SumG1(all)
+}
+
 /*********************************************************************/
 
 type G2 struct {
-	g2      driver.G2
-	curveID CurveID
+	g2    driver.G2
+	curve *Curve
 }
 
 func (g *G2) CurveID() CurveID {
-	return g.curveID
+	return g.curve.curveID
 }
 
 func (g *G2) Clone(a *G2) {
@@ -325,11 +578,55 @@ func (g *G2) Clone(a *G2) {
 }
 
 func (g *G2) Copy() *G2 {
-	return &G2{g2: g.g2.Copy(), curveID: g.curveID}
+	return &G2{g2: g.g2.Copy(), curve: g.curve}
 }
 
 func (g *G2) Mul(a *Zr) *G2 {
-	return &G2{g2: g.g2.Mul(a.zr), curveID: g.curveID}
+	return &G2{g2: g.g2.Mul(a.zr), curve: g.curve}
+}
+
+// MulInt64 is like G1.MulInt64.
+func (g *G2) MulInt64(k int64) *G2 {
+	if k < -smallScalar || k > smallScalar {
+		return g.Mul(g.curve.NewZrFromInt(k))
+	}
+
+	res := g.curve.InfinityG2()
+	if k == 0 {
+		return res
+	}
+
+	p := g.Copy()
+	for _, bit := range smallScalarBits(k) {
+		p.Add(p.Copy())
+		if bit {
+			p.Add(g)
+		}
+	}
+	if k < 0 {
+		res.Sub(p)
+		return res
+	}
+
+	return p
+}
+
+// smallScalar bounds the scalars that MulInt64 handles with additions.
+const smallScalar = 16
+
+// smallScalarBits returns the bits of |k| below the leading one, from the
+// highest, for double-and-add.
+func smallScalarBits(k int64) []bool {
+	if k < 0 {
+		k = -k
+	}
+
+	var res []bool
+	for i := 62 - bits.LeadingZeros64(uint64(k)); i >= 0; i-- {
+		res = append(res, k>>uint(i)&1 == 1)
+	}
+
+	return res
 }
 
 func (g *G2) Add(a *G2) {
@@ -352,6 +649,25 @@ func (g *G2) Compressed() []byte {
 	return g.g2.Compressed()
 }
 
+// BytesInto writes the serialization of g into dst and returns the number
+// of bytes written; it errors if dst is too short.
+func (g *G2) BytesInto(dst []byte) (int, error) {
+	if p, ok := g.g2.(driver.BytesPutter); ok && len(dst) >= g.curve.G2ByteSize {
+		return p.PutBytes(dst), nil
+	}
+
+	return bytesInto(dst, g.g2.Bytes())
+}
+
+// CompressedInto is like BytesInto for the compressed serialization.
+func (g *G2) CompressedInto(dst []byte) (int, error) {
+	if p, ok := g.g2.(driver.CompressedPutter); ok && len(dst) >= g.curve.CompressedG2ByteSize {
+		return p.PutCompressed(dst), nil
+	}
+
+	return bytesInto(dst, g.g2.Compressed())
+}
+
 func (g *G2) String() string {
 	return g.g2.String()
 }
@@ -360,15 +676,27 @@ func (g *G2) Equals(a *G2) bool {
 	return g.g2.Equals(a.g2)
 }
 
+// IsGenerator reports whether g equals the generator of G2 without
+// copying it as Equals(GenG2) would.
+func (g *G2) IsGenerator() bool {
+	return g.g2.IsGenerator()
+}
+
+// IsOnCurve reports whether g satisfies the equation of the twist, as
+// G1.IsOnCurve does for G1.
+func (g *G2) IsOnCurve() bool {
+	return g.g2.IsOnCurve()
+}
+
 /*********************************************************************/
 
 type Gt struct {
-	gt      driver.Gt
-	curveID CurveID
+	gt    driver.Gt
+	curve *Curve
 }
 
 func (g *Gt) CurveID() CurveID {
-	return g.curveID
+	return g.curve.curveID
 }
 
 func (g *Gt) Equals(a *Gt) bool {
@@ -379,12 +707,30 @@ func (g *Gt) Inverse() {
 	g.gt.Inverse()
 }
 
+// Conjugate inverts g like Inverse, but only if g went through FExp, which
+// makes it unitary; on such elements it is much cheaper than Inverse on
+// the drivers that provide the conjugation, and the same elsewhere.
+func (g *Gt) Conjugate() {
+	g.gt.Conjugate()
+}
+
 func (g *Gt) Mul(a *Gt) {
 	g.gt.Mul(a.gt)
 }
 
 func (g *Gt) Exp(z *Zr) *Gt {
-	return &Gt{gt: g.gt.Exp(z.zr), curveID: g.curveID}
+	return &Gt{gt: g.gt.Exp(z.zr), curve: g.curve}
+}
+
+// ExpInPlace sets g to g^z, as g = g.Exp(z) would without a new Gt.
+func (g *Gt) ExpInPlace(z *Zr) {
+	g.gt = g.gt.Exp(z.zr)
+}
+
+// IsInGroup reports whether g lies in Gt, the subgroup of order
+// GroupOrder, i.e. whether g^GroupOrder is the unity.
+func (g *Gt) IsInGroup() bool {
+	return g.curve.inGt(g.gt)
 }
 
 func (g *Gt) IsUnity() bool {
@@ -399,6 +745,16 @@ func (g *Gt) Bytes() []byte {
 	return g.gt.Bytes()
 }
 
+// BytesInto writes the serialization of g into dst and returns the number
+// of bytes written; it errors if dst is too short.
+func (g *Gt) BytesInto(dst []byte) (int, error) {
+	if p, ok := g.gt.(driver.BytesPutter); ok && len(dst) >= g.curve.GtByteSize {
+		return p.PutBytes(dst), nil
+	}
+
+	return bytesInto(dst, g.gt.Bytes())
+}
+
 /*********************************************************************/
 
 type Curve struct {
@@ -412,8 +768,38 @@ type Curve struct {
 	CompressedG1ByteSize int
 	G2ByteSize           int
 	CompressedG2ByteSize int
+	GtByteSize           int
 	ScalarByteSize       int
+	G1Layout             G1Layout
+	G2Layout             G2Layout
 	curveID              CurveID
+
+	// lengths of the encodings of the identities, which SEC1 curves encode
+	// on a single byte
+	infinityG1ByteSize           int
+	compressedInfinityG1ByteSize int
+	infinityG2ByteSize           int
+	compressedInfinityG2ByteSize int
+
+	// cached by Zero and One on first use, which return copies
+	constsOnce sync.Once
+	zero, one  *Zr
+
+	// the group order, cached by Order on first use
+	orderOnce sync.Once
+	order     *big.Int
+
+	// coefficients of y^2 = x^3 + a*x + b, the equation of G1, derived from
+	// two of its points on first use
+	equationOnce sync.Once
+	equationA    *Fp
+	equationB    *Fp
+
+	// fixed-base tables of GenG1 and GenG2, built on first use
+	genG1Once  sync.Once
+	genG1Table *FixedBaseG1
+	genG2Once  sync.Once
+	genG2Table *FixedBaseG2
 }
 
 func (c *Curve) Rand() (io.Reader, error) {
@@ -421,133 +807,809 @@ func (c *Curve) Rand() (io.Reader, error) {
 }
 
 func (c *Curve) NewRandomZr(rng io.Reader) *Zr {
-	return &Zr{zr: c.c.NewRandomZr(rng), curveID: c.curveID}
+	return &Zr{zr: c.c.NewRandomZr(rng), curve: c}
 }
 
+// NewRandomZrNonZero is NewRandomZr, but it draws again from rng until the
+// scalar is not zero, e.g. for blinding factors that must be invertible.
+func (c *Curve) NewRandomZrNonZero(rng io.Reader) *Zr {
+	for {
+		if z := c.NewRandomZr(rng); !z.IsZero() {
+			return z
+		}
+	}
+}
+
+// NewRandomZrVector returns n scalars drawn from rng with NewRandomZr.
+func (c *Curve) NewRandomZrVector(rng io.Reader, n int) []*Zr {
+	zrs := make([]*Zr, n)
+	for i := range zrs {
+		zrs[i] = c.NewRandomZr(rng)
+	}
+
+	return zrs
+}
+
+// NewZrFromBytes interprets b as a big-endian integer and reduces it
+// modulo the group order; inputs of any length are accepted.
+// Use NewZrFromBytesStrict to reject non-canonical encodings.
 func (c *Curve) NewZrFromBytes(b []byte) *Zr {
-	return &Zr{zr: c.c.NewZrFromBytes(b), curveID: c.curveID}
+	zr := c.c.NewZrFromBytes(b)
+	zr.Mod(c.GroupOrder.zr)
+	return &Zr{zr: zr, curve: c}
 }
 
-func (c *Curve) NewG1FromBytes(b []byte) (p *G1, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.Errorf("failure [%s]", r)
-			p = nil
-		}
-	}()
+// NewZrFromBytesLE is like NewZrFromBytes for a little-endian b.
+func (c *Curve) NewZrFromBytesLE(b []byte) *Zr {
+	return c.NewZrFromBytes(reversed(b))
+}
+
+// NewZrFromBytesStrict is like NewZrFromBytes but fails if b is longer
+// than ScalarByteSize or if its value is not smaller than the group order.
+func (c *Curve) NewZrFromBytesStrict(b []byte) (*Zr, error) {
+	if len(b) > c.ScalarByteSize {
+		return nil, errors.Errorf("invalid scalar length, expected at most %d bytes, got %d", c.ScalarByteSize, len(b))
+	}
+
+	zr := c.c.NewZrFromBytes(b)
+	reduced := zr.Copy()
+	reduced.Mod(c.GroupOrder.zr)
+	if !bytes.Equal(reduced.Bytes(), append(make([]byte, c.ScalarByteSize-len(b)), b...)) {
+		return nil, errors.Errorf("scalar is not smaller than the group order")
+	}
 
-	p = &G1{g1: c.c.NewG1FromBytes(b), curveID: c.curveID}
-	return
+	return &Zr{zr: zr, curve: c}, nil
 }
 
-func (c *Curve) NewG2FromBytes(b []byte) (p *G2, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.Errorf("failure [%s]", r)
-			p = nil
+// NewZrFromBits returns the scalar whose binary digits are bits, the least
+// significant first, reduced modulo the group order. It fails with
+// ErrInvalidLength if there are more bits than in the group order.
+func (c *Curve) NewZrFromBits(bits []bool) (*Zr, error) {
+	if max := c.Order().BitLen(); len(bits) > max {
+		return nil, fmt.Errorf("mathlib: Zr from bits on %s: %w: got %d bits, want at most %d", curveName(c.curveID), ErrInvalidLength, len(bits), max)
+	}
+
+	k := new(big.Int)
+	for i, b := range bits {
+		if b {
+			k.SetBit(k, i, 1)
 		}
-	}()
+	}
 
-	p = &G2{g2: c.c.NewG2FromBytes(b), curveID: c.curveID}
-	return
+	return c.NewZrFromBytes(k.Bytes()), nil
 }
 
-func (c *Curve) NewG1FromCompressed(b []byte) (p *G1, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.Errorf("failure [%s]", r)
-			p = nil
-		}
-	}()
+// NewG1FromBytes decodes the output of G1.Bytes. Like the other decoding
+// functions, it returns errors wrapping ErrInvalidLength, ErrInvalidEncoding,
+// ErrNotOnCurve or ErrNotInSubgroup.
+func (c *Curve) NewG1FromBytes(b []byte) (*G1, error) {
+	if len(b) != c.G1ByteSize && len(b) != c.infinityG1ByteSize {
+		return nil, lengthError("G1 decode", c.curveID, len(b), c.G1ByteSize)
+	}
+
+	g1, err := c.c.NewG1FromBytes(b)
+	if err != nil {
+		return nil, decodeError("G1 decode", c.curveID, err)
+	}
 
-	p = &G1{g1: c.c.NewG1FromCompressed(b), curveID: c.curveID}
-	return
+	return &G1{g1: g1, curve: c}, nil
 }
 
-func (c *Curve) NewG2FromCompressed(b []byte) (p *G2, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.Errorf("failure [%s]", r)
-			p = nil
-		}
-	}()
+func (c *Curve) NewG2FromBytes(b []byte) (*G2, error) {
+	if !c.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.G2ByteSize && len(b) != c.infinityG2ByteSize {
+		return nil, lengthError("G2 decode", c.curveID, len(b), c.G2ByteSize)
+	}
+
+	g2, err := c.c.NewG2FromBytes(b)
+	if err != nil {
+		return nil, decodeError("G2 decode", c.curveID, err)
+	}
 
-	p = &G2{g2: c.c.NewG2FromCompressed(b), curveID: c.curveID}
-	return
+	return &G2{g2: g2, curve: c}, nil
 }
 
-func (c *Curve) NewGtFromBytes(b []byte) (p *Gt, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.Errorf("failure [%s]", r)
-			p = nil
-		}
-	}()
+func (c *Curve) NewG1FromCompressed(b []byte) (*G1, error) {
+	if len(b) != c.CompressedG1ByteSize && len(b) != c.compressedInfinityG1ByteSize {
+		return nil, lengthError("G1 decompress", c.curveID, len(b), c.CompressedG1ByteSize)
+	}
+
+	g1, err := c.c.NewG1FromCompressed(b)
+	if err != nil {
+		return nil, decodeError("G1 decompress", c.curveID, err)
+	}
+
+	return &G1{g1: g1, curve: c}, nil
+}
+
+func (c *Curve) NewG2FromCompressed(b []byte) (*G2, error) {
+	if !c.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.CompressedG2ByteSize && len(b) != c.compressedInfinityG2ByteSize {
+		return nil, lengthError("G2 decompress", c.curveID, len(b), c.CompressedG2ByteSize)
+	}
+
+	g2, err := c.c.NewG2FromCompressed(b)
+	if err != nil {
+		return nil, decodeError("G2 decompress", c.curveID, err)
+	}
+
+	return &G2{g2: g2, curve: c}, nil
+}
+
+// NewG1FromBytesUnchecked is NewG1FromBytes without the on-curve and
+// subgroup checks, so that IsOnCurve can tell apart the two reasons for
+// which NewG1FromBytes rejects a point. Only the gurvy pairing curves skip
+// the checks, the others perform them anyway. Never do arithmetic on points
+// decoded from untrusted data without checking them first.
+func (c *Curve) NewG1FromBytesUnchecked(b []byte) (*G1, error) {
+	d, ok := c.c.(driver.UncheckedDecoder)
+	if !ok {
+		return c.NewG1FromBytes(b)
+	}
+
+	if len(b) != c.G1ByteSize && len(b) != c.infinityG1ByteSize {
+		return nil, lengthError("G1 decode", c.curveID, len(b), c.G1ByteSize)
+	}
+
+	g1, err := d.NewG1FromBytesUnchecked(b)
+	if err != nil {
+		return nil, decodeError("G1 decode", c.curveID, err)
+	}
 
-	p = &Gt{gt: c.c.NewGtFromBytes(b), curveID: c.curveID}
-	return
+	return &G1{g1: g1, curve: c}, nil
+}
+
+// NewG2FromBytesUnchecked is the counterpart of NewG1FromBytesUnchecked for
+// G2.
+func (c *Curve) NewG2FromBytesUnchecked(b []byte) (*G2, error) {
+	d, ok := c.c.(driver.UncheckedDecoder)
+	if !ok {
+		return c.NewG2FromBytes(b)
+	}
+
+	if len(b) != c.G2ByteSize && len(b) != c.infinityG2ByteSize {
+		return nil, lengthError("G2 decode", c.curveID, len(b), c.G2ByteSize)
+	}
+
+	g2, err := d.NewG2FromBytesUnchecked(b)
+	if err != nil {
+		return nil, decodeError("G2 decode", c.curveID, err)
+	}
+
+	return &G2{g2: g2, curve: c}, nil
+}
+
+// NewGtFromBytes decodes the output of Gt.Bytes. Elements of Gt, i.e. values
+// returned by FExp, are encoded in the same way by all the BLS12-381 curves,
+// each of which reads back the encodings of the others. It fails with
+// ErrNotInSubgroup unless the element lies in Gt, the subgroup of order
+// GroupOrder, as is not the case of the output of Pairing before FExp or of
+// a forged encoding: an element out of the subgroup can leak a secret
+// exponent through small-order components. Use NewGtFromBytesUnchecked to
+// skip the check on trusted data.
+func (c *Curve) NewGtFromBytes(b []byte) (*Gt, error) {
+	g, err := c.NewGtFromBytesUnchecked(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.inGt(g.gt) {
+		return nil, fmt.Errorf("mathlib: Gt decode on %s: %w", curveName(c.curveID), ErrNotInSubgroup)
+	}
+
+	return g, nil
+}
+
+// NewGtFromBytesChecked is NewGtFromBytes, named to contrast with
+// NewGtFromBytesUnchecked.
+func (c *Curve) NewGtFromBytesChecked(b []byte) (*Gt, error) {
+	return c.NewGtFromBytes(b)
+}
+
+// NewGtFromBytesUnchecked decodes b like NewGtFromBytes but skips the
+// exponentiation by the group order that checks the subgroup. It is faster
+// but only safe on trusted data, e.g. read back from local storage, and it
+// is the only way to decode the output of Pairing before FExp, whose
+// encoding depends on the driver. Some drivers check the subgroup while
+// decoding anyway.
+func (c *Curve) NewGtFromBytesUnchecked(b []byte) (*Gt, error) {
+	if !c.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.GtByteSize {
+		return nil, lengthError("Gt decode", c.curveID, len(b), c.GtByteSize)
+	}
+
+	gt, err := c.c.NewGtFromBytes(b)
+	if err != nil {
+		return nil, decodeError("Gt decode", c.curveID, err)
+	}
+
+	return &Gt{gt: gt, curve: c}, nil
+}
+
+// NewZrFromString parses s in the given base, as big.Int.SetString does,
+// and reduces it modulo the group order; it is the inverse of Zr.Text.
+func (c *Curve) NewZrFromString(s string, base int) (*Zr, error) {
+	v, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, errors.Errorf("invalid scalar %q in base %d", s, base)
+	}
+
+	z := c.NewZrFromBytes(v.Bytes())
+	if v.Sign() < 0 {
+		z = c.ModNeg(z, c.GroupOrder)
+	}
+
+	return z, nil
 }
 
 func (c *Curve) NewZrFromInt(i int64) *Zr {
-	return &Zr{zr: c.c.NewZrFromInt64(i), curveID: c.curveID}
+	return &Zr{zr: c.c.NewZrFromInt64(i), curve: c}
+}
+
+// Zero returns the scalar 0, as a copy of a value cached on the curve that
+// the caller is free to modify.
+func (c *Curve) Zero() *Zr {
+	c.constsOnce.Do(c.initConsts)
+	return c.zero.Copy()
+}
+
+// One returns the scalar 1, as a copy like Zero.
+func (c *Curve) One() *Zr {
+	c.constsOnce.Do(c.initConsts)
+	return c.one.Copy()
+}
+
+func (c *Curve) initConsts() {
+	c.zero = c.NewZrFromInt(0)
+	c.one = c.NewZrFromInt(1)
+}
+
+// Order returns the order of the groups, the modulus of Zr, as a fresh
+// big.Int. GroupOrder holds the same value as a scalar, which is reduced
+// like any other: its Bytes and String are those of zero.
+func (c *Curve) Order() *big.Int {
+	c.orderOnce.Do(func() {
+		// the drivers reduce the order itself to zero, so it is recovered
+		// from order - 1
+		order := new(big.Int).SetBytes(c.c.GroupOrder().Minus(c.c.NewZrFromInt64(1)).Bytes())
+		c.order = order.Add(order, big.NewInt(1))
+	})
+
+	return new(big.Int).Set(c.order)
 }
 
 func (c *Curve) NewZrFromUint64(i uint64) *Zr {
-	return &Zr{zr: c.c.NewZrFromUint64(i), curveID: c.curveID}
+	return &Zr{zr: c.c.NewZrFromUint64(i), curve: c}
+}
+
+// NewZrVectorFromInts returns the scalars NewZrFromInt(v) for the values in
+// vs.
+func (c *Curve) NewZrVectorFromInts(vs []int64) []*Zr {
+	zrs := make([]*Zr, len(vs))
+	for i, v := range vs {
+		zrs[i] = c.NewZrFromInt(v)
+	}
+
+	return zrs
 }
 
+// NewG2 returns an uninitialised G2 element, only meant to be used as
+// the destination of Clone. Use InfinityG2 for the identity element.
 func (c *Curve) NewG2() *G2 {
-	return &G2{g2: c.c.NewG2(), curveID: c.curveID}
+	return &G2{g2: c.c.NewG2(), curve: c}
 }
 
+// NewG1 returns an uninitialised G1 element, only meant to be used as
+// the destination of Clone. Use InfinityG1 for the identity element.
 func (c *Curve) NewG1() *G1 {
-	return &G1{g1: c.c.NewG1(), curveID: c.curveID}
+	return &G1{g1: c.c.NewG1(), curve: c}
+}
+
+func (c *Curve) InfinityG1() *G1 {
+	return &G1{g1: c.c.InfinityG1(), curve: c}
+}
+
+func (c *Curve) InfinityG2() *G2 {
+	return &G2{g2: c.c.InfinityG2(), curve: c}
+}
+
+// IdentityGt returns the unity of Gt, e.g. to start a product. Curves
+// without pairings panic with ErrUnsupported.
+func (c *Curve) IdentityGt() *Gt {
+	return &Gt{gt: c.c.GenGt().Exp(c.c.NewZrFromInt64(0)), curve: c}
+}
+
+// NewRandomGt returns GenGt raised to a random scalar drawn from rng, a
+// uniformly random element of Gt, e.g. to blind a product of pairings.
+// Curves without pairings panic with ErrUnsupported.
+func (c *Curve) NewRandomGt(rng io.Reader) *Gt {
+	return &Gt{gt: c.c.GenGt().Exp(c.c.NewRandomZr(rng)), curve: c}
 }
 
+// HashToGt hashes data to Gt as the final-exponentiated pairing of
+// HashToG1WithDomain(data, domain) with GenG2. Nobody knows the discrete
+// logarithm of the result to the base GenGt. Curves without pairings panic
+// with ErrUnsupported.
+func (c *Curve) HashToGt(data, domain []byte) *Gt {
+	if !c.SupportsPairing() {
+		panic(ErrUnsupported)
+	}
+
+	return c.FExp(c.Pairing(c.GenG2, c.HashToG1WithDomain(data, domain)))
+}
+
+// Pairing returns the pairing of a and b, which must be passed to FExp. It
+// is the unity of Gt, before and after FExp, if a or b is the point at
+// infinity, e.g. an empty aggregate; Pairing2 and PairingN likewise leave
+// out such pairs.
 func (c *Curve) Pairing(a *G2, b *G1) *Gt {
-	return &Gt{gt: c.c.Pairing(a.g2, b.g1), curveID: c.curveID}
+	return &Gt{gt: c.c.Pairing(a.g2, b.g1), curve: c}
 }
 
 func (c *Curve) Pairing2(p *G2, q *G1, r *G2, s *G1) *Gt {
-	return &Gt{gt: c.c.Pairing2(p.g2, r.g2, q.g1, s.g1), curveID: c.curveID}
+	return &Gt{gt: c.c.Pairing2(p.g2, r.g2, q.g1, s.g1), curve: c}
+}
+
+// PairingN returns the product of the pairings of p2[i] and p1[i], which,
+// like the output of Pairing2, must be passed to FExp.
+func (c *Curve) PairingN(p2 []*G2, p1 []*G1) (*Gt, error) {
+	if len(p2) != len(p1) {
+		return nil, ErrPairingLengthMismatch
+	}
+	if len(p2) == 0 {
+		return nil, errors.New("no pairs to compute")
+	}
+
+	var res driver.Gt
+	i := 0
+	for ; i+1 < len(p2); i += 2 {
+		t := c.c.Pairing2(p2[i].g2, p2[i+1].g2, p1[i].g1, p1[i+1].g1)
+		if res == nil {
+			res = t
+		} else {
+			res.Mul(t)
+		}
+	}
+	if i < len(p2) {
+		t := c.c.Pairing(p2[i].g2, p1[i].g1)
+		if res == nil {
+			res = t
+		} else {
+			res.Mul(t)
+		}
+	}
+
+	return &Gt{gt: res, curve: c}, nil
+}
+
+// PairingPair holds the points of one of the pairs of PairingPairs.
+type PairingPair struct {
+	G2 *G2
+	G1 *G1
+}
+
+// PairingPairs returns the product of the pairings of pairs, which, like
+// the output of PairingN, must be passed to FExp. Unlike Pairing2 and
+// PairingN, it cannot pair points of different positions by mistake. It is
+// the unity of Gt if there are no pairs.
+func (c *Curve) PairingPairs(pairs []PairingPair) *Gt {
+	if len(pairs) == 0 {
+		return c.IdentityGt()
+	}
+
+	p2 := make([]*G2, len(pairs))
+	p1 := make([]*G1, len(pairs))
+	for i, p := range pairs {
+		p2[i], p1[i] = p.G2, p.G1
+	}
+
+	res, err := c.PairingN(p2, p1)
+	if err != nil {
+		panic(err)
+	}
+
+	return res
+}
+
+// MillerLoop returns the product of the Miller loops of the pairs of g2s
+// and g1s, before the final exponentiation: callers may Mul the outputs of
+// several calls, e.g. partial products computed in different places, and
+// pass the result to FExp once. It is the unity of Gt if there are no
+// pairs, and it panics with ErrPairingLengthMismatch if g2s and g1s differ
+// in length. Drivers implementing driver.MillerLooper run a single loop for
+// all the pairs, the others go through PairingN. The kilic, blst and circl
+// libraries do not expose the Miller loop alone: on those drivers, whose
+// FExp is the identity, the result is already reduced, which costs more
+// but composes the same way.
+func (c *Curve) MillerLoop(g2s []*G2, g1s []*G1) *Gt {
+	if len(g2s) != len(g1s) {
+		panic(ErrPairingLengthMismatch)
+	}
+	if len(g2s) == 0 {
+		return c.IdentityGt()
+	}
+
+	ml, ok := c.c.(driver.MillerLooper)
+	if !ok {
+		res, err := c.PairingN(g2s, g1s)
+		if err != nil {
+			panic(err)
+		}
+		return res
+	}
+
+	p2 := make([]driver.G2, len(g2s))
+	p1 := make([]driver.G1, len(g1s))
+	for i := range g1s {
+		p2[i] = g2s[i].g2
+		p1[i] = g1s[i].g1
+	}
+
+	return &Gt{gt: ml.MillerLoop(p2, p1), curve: c}
 }
 
 func (c *Curve) FExp(a *Gt) *Gt {
-	return &Gt{gt: c.c.FExp(a.gt), curveID: c.curveID}
+	return &Gt{gt: c.c.FExp(a.gt), curve: c}
 }
 
 func (c *Curve) HashToZr(data []byte) *Zr {
-	return &Zr{zr: c.c.HashToZr(data), curveID: c.curveID}
+	return &Zr{zr: c.c.HashToZr(data), curve: c}
+}
+
+// HashToZrWithDomain hashes data to a uniform scalar with the same
+// expand_message_xmd that the curve uses in HashToG1WithDomain. Only the BBS
+// curves provide it; the others panic with ErrUnsupported.
+func (c *Curve) HashToZrWithDomain(data, domain []byte) *Zr {
+	h, ok := c.c.(driver.ZrDomainHasher)
+	if !ok {
+		panic(ErrUnsupported)
+	}
+
+	return &Zr{zr: h.HashToZrWithDomain(data, domain), curve: c}
+}
+
+// HashToZrBatch derives count uniform scalars from data with a single
+// expand_message_xmd of count times the length of one scalar, which is
+// cheaper than hashing count times, e.g. for a challenge and its blinders.
+// The BBS curves expand as in HashToZrWithDomain, which HashToZrBatch
+// returns for count one. The other curves run hash_to_field of RFC 9380,
+// Section 5.2, with expand_message_xmd over SHA-256 and the group order as
+// modulus.
+func (c *Curve) HashToZrBatch(data, domain []byte, count int) []*Zr {
+	if count <= 0 {
+		return []*Zr{}
+	}
+
+	h, ok := c.c.(driver.ZrDomainHasher)
+	if !ok {
+		return c.hashToZrsSHA256(data, domain, count)
+	}
+
+	zrs := h.HashToZrBatch(data, domain, count)
+	res := make([]*Zr, len(zrs))
+	for i, zr := range zrs {
+		res[i] = &Zr{zr: zr, curve: c}
+	}
+
+	return res
+}
+
+func (c *Curve) hashToZrsSHA256(data, domain []byte, count int) []*Zr {
+	vs, err := gurvy.HashToZrsGenericBE(data, domain, c.Order(), count, sha256.New)
+	if err != nil {
+		panic(fmt.Sprintf("HashToZr failed [%s]", err.Error()))
+	}
+
+	res := make([]*Zr, count)
+	for i, v := range vs {
+		res[i] = c.NewZrFromBytes(v.Bytes())
+	}
+
+	return res
 }
 
 func (c *Curve) HashToG1(data []byte) *G1 {
-	return &G1{g1: c.c.HashToG1(data), curveID: c.curveID}
+	return &G1{g1: c.c.HashToG1(data), curve: c}
 }
 
 func (c *Curve) HashToG1WithDomain(data, domain []byte) *G1 {
-	return &G1{g1: c.c.HashToG1WithDomain(data, domain), curveID: c.curveID}
+	return &G1{g1: c.c.HashToG1WithDomain(data, domain), curve: c}
+}
+
+// HashToG1WithU returns the same point as HashToG1WithDomain together with
+// the hash_to_field outputs u it was mapped from. The elements of u belong
+// to the base field of the curve: they are wrapped in Zr so that they can
+// be handed back to MapToG1, and must not be reduced modulo the group order.
+func (c *Curve) HashToG1WithU(data, domain []byte) (*G1, []*Zr) {
+	p, u := c.c.HashToG1WithU(data, domain)
+
+	res := make([]*Zr, len(u))
+	for i := range u {
+		res[i] = &Zr{zr: u[i], curve: c}
+	}
+
+	return &G1{g1: p, curve: c}, res
+}
+
+// MapToG1 maps each base field element in u to G1 and returns the sum of
+// the resulting points.
+func (c *Curve) MapToG1(u []*Zr) *G1 {
+	els := make([]driver.Zr, len(u))
+	for i := range u {
+		els[i] = u[i].zr
+	}
+
+	return &G1{g1: c.c.MapToG1(els), curve: c}
+}
+
+// MapToG1FromBytes maps a single base field element to G1 with the
+// map_to_curve of RFC 9380 followed by clear_cofactor, without hashing it
+// first, e.g. a uniformly random output of a VRF. b is the big-endian
+// encoding of the element, CoordByteSize bytes long and smaller than the
+// base field modulus. It returns ErrUnsupported on curves whose driver does
+// not implement driver.FieldMapper.
+func (c *Curve) MapToG1FromBytes(b []byte) (*G1, error) {
+	fm, ok := c.c.(driver.FieldMapper)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.CoordByteSize {
+		return nil, lengthError("G1 map", c.curveID, len(b), c.CoordByteSize)
+	}
+
+	p, err := fm.MapFieldToG1(b)
+	if err != nil {
+		return nil, decodeError("G1 map", c.curveID, err)
+	}
+
+	return &G1{g1: p, curve: c}, nil
+}
+
+// MapToG2FromBytes is MapToG1FromBytes for G2, whose field elements have
+// G2Layout.Degree components of CoordByteSize bytes each, laid out from the
+// highest to the lowest, e.g. c1 || c0.
+func (c *Curve) MapToG2FromBytes(b []byte) (*G2, error) {
+	fm, ok := c.c.(driver.FieldMapper)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	if size := c.G2Layout.Degree * c.CoordByteSize; len(b) != size {
+		return nil, lengthError("G2 map", c.curveID, len(b), size)
+	}
+
+	p, err := fm.MapFieldToG2(b)
+	if err != nil {
+		return nil, decodeError("G2 map", c.curveID, err)
+	}
+
+	return &G2{g2: p, curve: c}, nil
+}
+
+// PointFromHashAndIncrement maps data to G1 by try-and-increment, which
+// the two AMCL versions of FP256BN implement in the same way; other curves
+// return ErrUnsupported. Its running time depends on data.
+func (c *Curve) PointFromHashAndIncrement(data []byte) (*G1, error) {
+	im, ok := c.c.(driver.IncrementMapper)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	return &G1{g1: im.MapToG1Increment(data), curve: c}, nil
 }
 
 func (c *Curve) HashToG2(data []byte) *G2 {
-	return &G2{g2: c.c.HashToG2(data), curveID: c.curveID}
+	return &G2{g2: c.c.HashToG2(data), curve: c}
 }
 
 func (c *Curve) HashToG2WithDomain(data, domain []byte) *G2 {
-	return &G2{g2: c.c.HashToG2WithDomain(data, domain), curveID: c.curveID}
+	return &G2{g2: c.c.HashToG2WithDomain(data, domain), curve: c}
 }
 
 func (c *Curve) ModSub(a, b, m *Zr) *Zr {
-	return &Zr{zr: c.c.ModSub(a.zr, b.zr, m.zr), curveID: c.curveID}
+	return &Zr{zr: c.c.ModSub(a.zr, b.zr, m.zr), curve: c}
 }
 
 func (c *Curve) ModAdd(a, b, m *Zr) *Zr {
-	return &Zr{zr: c.c.ModAdd(a.zr, b.zr, m.zr), curveID: c.curveID}
+	return &Zr{zr: c.c.ModAdd(a.zr, b.zr, m.zr), curve: c}
 }
 
 func (c *Curve) ModMul(a1, b1, m *Zr) *Zr {
-	return &Zr{zr: c.c.ModMul(a1.zr, b1.zr, m.zr), curveID: c.curveID}
+	return &Zr{zr: c.c.ModMul(a1.zr, b1.zr, m.zr), curve: c}
+}
+
+// ModMul3 returns a1 * b1 * c1 modulo m, e.g. the product of a challenge
+// and two secrets in a response, without the intermediate reduction and
+// temporary of two ModMul calls. The gnark drivers multiply in the scalar
+// field if m is GroupOrder.
+func (c *Curve) ModMul3(a1, b1, c1, m *Zr) *Zr {
+	return &Zr{zr: c.c.ModMul3(a1.zr, b1.zr, c1.zr, m.zr), curve: c}
 }
 
 func (c *Curve) ModNeg(a1, m *Zr) *Zr {
-	return &Zr{zr: c.c.ModNeg(a1.zr, m.zr), curveID: c.curveID}
+	return &Zr{zr: c.c.ModNeg(a1.zr, m.zr), curve: c}
+}
+
+// ModAdd2 sets a to a + b + c1 modulo m, without allocating temporaries.
+func (c *Curve) ModAdd2(a, b, c1, m *Zr) {
+	c.c.ModAdd2(a.zr, b.zr, c1.zr, m.zr)
+}
+
+// ModAddMul returns the inner product of a1 and b1 modulo m; it panics if
+// a1 and b1 do not have the same length.
+func (c *Curve) ModAddMul(a1, b1 []*Zr, m *Zr) *Zr {
+	if len(a1) != len(b1) {
+		panic(fmt.Sprintf("ModAddMul failed [%d scalars against %d]", len(a1), len(b1)))
+	}
+
+	as := make([]driver.Zr, len(a1))
+	bs := make([]driver.Zr, len(b1))
+	for i := range a1 {
+		as[i] = a1[i].zr
+		bs[i] = b1[i].zr
+	}
+
+	return &Zr{zr: c.c.ModAddMul(as, bs, m.zr), curve: c}
+}
+
+// ModAddMul2 returns a1 * c1 + b1 * c2 modulo m.
+func (c *Curve) ModAddMul2(a1, c1, b1, c2, m *Zr) *Zr {
+	return &Zr{zr: c.c.ModAddMul2(a1.zr, c1.zr, b1.zr, c2.zr, m.zr), curve: c}
+}
+
+// SolveLinear returns the x such that a * x = b modulo GroupOrder, i.e.
+// b * a^-1; it errors if a is zero modulo GroupOrder, where there is either
+// no solution or no unique one.
+func (c *Curve) SolveLinear(a, b *Zr) (*Zr, error) {
+	if a.Modded(c.GroupOrder).Equals(c.Zero()) {
+		return nil, errors.New("cannot solve a * x = b for a zero a")
+	}
+
+	return c.ModMul(b, a.Inverted(c.GroupOrder), c.GroupOrder), nil
+}
+
+// ConditionalSelect returns a if cond is true and b otherwise, reduced
+// modulo the group order. Instead of branching on cond, it selects between
+// their fixed-width encodings with crypto/subtle, so that the choice does
+// not show in the timing, e.g. in MPC protocols. The big.Int arithmetic of
+// the drivers is not constant time, though.
+func (c *Curve) ConditionalSelect(cond bool, a, b *Zr) *Zr {
+	res := b.Bytes()
+	subtle.ConstantTimeCopy(boolToInt(cond), res, a.Bytes())
+
+	return c.NewZrFromBytes(res)
+}
+
+// ConditionalSelectG1 returns a copy of a if cond is true and of b
+// otherwise. On drivers that implement driver.PointSelector, i.e. the gnark
+// based ones and FP256BN, it selects between the coordinates of the points
+// with the constant-time conditional moves of their field. Elsewhere it
+// branches on cond, so it is not constant time. As for ConditionalSelect,
+// the rest of the driver arithmetic is not constant time either.
+func (c *Curve) ConditionalSelectG1(cond bool, a, b *G1) *G1 {
+	if ps, ok := c.c.(driver.PointSelector); ok {
+		return &G1{g1: ps.SelectG1(boolToInt(cond), a.g1, b.g1), curve: c}
+	}
+
+	if cond {
+		return a.Copy()
+	}
+	return b.Copy()
+}
+
+// ConditionalSelectG2 is ConditionalSelectG1 for G2.
+func (c *Curve) ConditionalSelectG2(cond bool, a, b *G2) *G2 {
+	if ps, ok := c.c.(driver.PointSelector); ok {
+		return &G2{g2: ps.SelectG2(boolToInt(cond), a.g2, b.g2), curve: c}
+	}
+
+	if cond {
+		return a.Copy()
+	}
+	return b.Copy()
+}
+
+// ConditionalNegG1 sets p to -p if cond is true and leaves it unchanged
+// otherwise, selecting with ConditionalSelectG1; it is constant time where
+// that is, up to the negation of the driver.
+func (c *Curve) ConditionalNegG1(cond bool, p *G1) {
+	neg := p.Copy()
+	neg.Neg()
+	p.Clone(c.ConditionalSelectG1(cond, neg, p))
+}
+
+// boolToInt returns 1 for true and 0 for false; the compiler turns it into
+// a zero extension of the bool rather than a branch.
+func boolToInt(b bool) int {
+	var i int
+	if b {
+		i = 1
+	}
+
+	return i
+}
+
+// MulMany returns [s]base for every scalar s. Drivers that support it
+// precompute a table for base once and reuse it across all scalars.
+func (c *Curve) MulMany(base *G1, scalars []*Zr) []*G1 {
+	zrs := make([]driver.Zr, len(scalars))
+	for i, s := range scalars {
+		zrs[i] = s.zr
+	}
+
+	points := c.c.MulMany(base.g1, zrs)
+
+	res := make([]*G1, len(points))
+	for i, p := range points {
+		res[i] = &G1{g1: p, curve: c}
+	}
+
+	return res
+}
+
+// AggregateG2 returns the sum of pks, e.g. to aggregate BLS public keys,
+// accumulating in a single projective point; it returns the point at
+// infinity if pks is empty.
+func (c *Curve) AggregateG2(pks []*G2) *G2 {
+	points := make([]driver.G2, len(pks))
+	for i, pk := range pks {
+		points[i] = pk.g2
+	}
+
+	return &G2{g2: c.c.SumG2(points), curve: c}
+}
+
+func (c *Curve) EqualG1Vectors(a, b []*G1) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Curve) EqualG2Vectors(a, b []*G2) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func bytesInto(dst, b []byte) (int, error) {
+	if len(dst) < len(b) {
+		return 0, errors.Errorf("buffer too short: need %d bytes, got %d", len(b), len(dst))
+	}
+
+	return copy(dst, b), nil
+}
+
+func reversed(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
 }
@@ -0,0 +1,47 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"github.com/IBM/mathlib/driver"
+)
+
+// MontgomeryBytes returns z as the gnark-crypto curves hold it in an
+// fr.Element, in Montgomery form: the big-endian bytes of zR mod
+// GroupOrder, for R = 2^(64*limbs). It is not interoperable with Bytes and
+// only meant for code that handles gnark's limbs directly, e.g. circuits;
+// the curves not backed by gnark, such as the amcl ones, return
+// ErrUnsupported.
+func (z *Zr) MontgomeryBytes() ([]byte, error) {
+	me, ok := z.curve.c.(driver.ZrMontgomeryEncoder)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	return me.ZrMontgomeryBytes(z.zr), nil
+}
+
+// NewZrFromMontgomery is the inverse of Zr.MontgomeryBytes. It fails with
+// ErrInvalidLength unless b has ScalarByteSize bytes and with
+// ErrInvalidEncoding if the value is not smaller than the group order.
+func (c *Curve) NewZrFromMontgomery(b []byte) (*Zr, error) {
+	me, ok := c.c.(driver.ZrMontgomeryEncoder)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	if len(b) != c.ScalarByteSize {
+		return nil, lengthError("Zr decode", c.curveID, len(b), c.ScalarByteSize)
+	}
+
+	zr, err := me.NewZrFromMontgomery(b)
+	if err != nil {
+		return nil, decodeError("Zr decode", c.curveID, err)
+	}
+
+	return &Zr{zr: zr, curve: c}, nil
+}
@@ -0,0 +1,387 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// MSMOption tunes MultiScalarMul, see WithTasks and WithSplit.
+type MSMOption func(*msmConfig)
+
+type msmConfig struct {
+	// tasks is zero unless set by WithTasks
+	tasks int
+	split int
+}
+
+// WithTasks caps MultiScalarMul at n goroutines, GOMAXPROCS of them if n is
+// not positive. It sets ecc.MultiExpConfig.NbTasks on the gnark drivers and
+// the workers of MultiScalarMulParallel on the others. Without it, the
+// former run with gnark's default and the latter on a single goroutine.
+func WithTasks(n int) MSMOption {
+	return func(cfg *msmConfig) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		cfg.tasks = n
+	}
+}
+
+// WithSplit has MultiScalarMul cut the terms into n chunks of about the
+// same size, each summed by a multi-scalar multiplication of its own. The
+// chunks run concurrently within the budget of WithTasks, if given: no more
+// than that many at once, each with its share of the tasks.
+func WithSplit(n int) MSMOption {
+	return func(cfg *msmConfig) {
+		cfg.split = n
+	}
+}
+
+// MultiScalarMul returns the sum of [scalars[i]]bases[i], computed with the
+// driver's own multi-scalar multiplication if it has one and otherwise with
+// Pippenger's bucket method, tuned by opts; it panics if bases and scalars
+// do not have the same length. The result does not depend on opts.
+func (c *Curve) MultiScalarMul(bases []*G1, scalars []*Zr, opts ...MSMOption) *G1 {
+	if len(bases) != len(scalars) {
+		panic(fmt.Sprintf("MultiScalarMul failed [%d bases against %d scalars]", len(bases), len(scalars)))
+	}
+
+	var cfg msmConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.split > 1 && len(bases) > 1 {
+		return c.multiScalarMulSplit(bases, scalars, cfg)
+	}
+
+	return c.multiScalarMul(bases, scalars, cfg.tasks)
+}
+
+// multiScalarMul is MultiScalarMul with at most tasks goroutines, or the
+// default of the implementation if tasks is zero.
+func (c *Curve) multiScalarMul(bases []*G1, scalars []*Zr, tasks int) *G1 {
+	msm, ok := c.c.(driver.MultiScalarMuler)
+	if !ok || len(bases) == 0 {
+		if tasks == 0 {
+			tasks = 1
+		}
+		return c.MultiScalarMulParallel(bases, scalars, tasks)
+	}
+
+	points := make([]driver.G1, len(bases))
+	zrs := make([]driver.Zr, len(scalars))
+	for i := range bases {
+		points[i] = bases[i].g1
+		zrs[i] = scalars[i].zr
+	}
+
+	return &G1{g1: msm.MultiScalarMul(points, zrs, tasks), curve: c}
+}
+
+func (c *Curve) multiScalarMulSplit(bases []*G1, scalars []*Zr, cfg msmConfig) *G1 {
+	size := (len(bases) + cfg.split - 1) / cfg.split
+	chunks := make([]*G1, (len(bases)+size-1)/size)
+
+	running, tasks := len(chunks), cfg.tasks
+	if tasks > 0 {
+		if running > tasks {
+			running = tasks
+		}
+		tasks /= len(chunks)
+		if tasks == 0 {
+			tasks = 1
+		}
+	}
+
+	sem := make(chan struct{}, running)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		end := (i + 1) * size
+		if end > len(bases) {
+			end = len(bases)
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i, start, end int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			chunks[i] = c.multiScalarMul(bases[start:end], scalars[start:end], tasks)
+		}(i, i*size, end)
+	}
+	wg.Wait()
+
+	res := chunks[0]
+	for _, chunk := range chunks[1:] {
+		res.Add(chunk)
+	}
+
+	return res
+}
+
+// LinCombG1 returns the sum of [scalars[i]]points[i] like MultiScalarMul,
+// which it only switches to beyond four terms and on drivers with their own
+// multi-scalar multiplication. Otherwise the terms go through Mul2 in
+// pairs, which some drivers compute with Shamir's trick.
+func (c *Curve) LinCombG1(points []*G1, scalars []*Zr) *G1 {
+	if len(points) != len(scalars) {
+		panic(fmt.Sprintf("LinCombG1 failed [%d points against %d scalars]", len(points), len(scalars)))
+	}
+
+	if _, ok := c.c.(driver.MultiScalarMuler); ok && len(points) > 4 {
+		return c.MultiScalarMul(points, scalars)
+	}
+
+	res := c.InfinityG1()
+	for i := 0; i+1 < len(points); i += 2 {
+		res.Add(points[i].Mul2(scalars[i], points[i+1], scalars[i+1]))
+	}
+	if len(points)%2 == 1 {
+		res.Add(points[len(points)-1].Mul(scalars[len(scalars)-1]))
+	}
+
+	return res
+}
+
+// AddPairsOfProducts returns the sum of [left[i]]leftgen[i] and
+// [right[i]]rightgen[i], computed as a single MultiScalarMul over the
+// concatenated terms; it panics if the four slices do not have the same
+// length.
+func (c *Curve) AddPairsOfProducts(left, right []*Zr, leftgen, rightgen []*G1) *G1 {
+	if len(left) != len(right) || len(left) != len(leftgen) || len(left) != len(rightgen) {
+		panic(fmt.Sprintf("AddPairsOfProducts failed [%d, %d scalars against %d, %d points]", len(left), len(right), len(leftgen), len(rightgen)))
+	}
+
+	bases := make([]*G1, 0, 2*len(left))
+	bases = append(append(bases, leftgen...), rightgen...)
+	scalars := make([]*Zr, 0, 2*len(left))
+	scalars = append(append(scalars, left...), right...)
+
+	return c.MultiScalarMul(bases, scalars)
+}
+
+// MulAddG1 sets acc to acc + [s]P, as acc.Add(P.Mul(s)) does but without
+// the intermediate point on drivers that can keep [s]P projective until it
+// is added; see driver.MulAdder.
+func (c *Curve) MulAddG1(acc *G1, s *Zr, P *G1) {
+	if ma, ok := c.c.(driver.MulAdder); ok {
+		ma.MulAddG1(acc.g1, s.zr, P.g1)
+		return
+	}
+
+	acc.Add(P.Mul(s))
+}
+
+// MulAddG2 is MulAddG1 for G2.
+func (c *Curve) MulAddG2(acc *G2, s *Zr, P *G2) {
+	if ma, ok := c.c.(driver.MulAdder); ok {
+		ma.MulAddG2(acc.g2, s.zr, P.g2)
+		return
+	}
+
+	acc.Add(P.Mul(s))
+}
+
+// MultiScalarMulParallel is MultiScalarMul with the windows of the bucket
+// method spread over up to workers goroutines, GOMAXPROCS of them if
+// workers is not positive. Each window is summed on its own and the partial
+// sums are combined in window order, so the result does not depend on the
+// number of workers.
+func (c *Curve) MultiScalarMulParallel(bases []*G1, scalars []*Zr, workers int) *G1 {
+	if len(bases) != len(scalars) {
+		panic(fmt.Sprintf("MultiScalarMul failed [%d bases against %d scalars]", len(bases), len(scalars)))
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ks := make([]*big.Int, len(scalars))
+	for i, s := range scalars {
+		ks[i] = new(big.Int).SetBytes(s.Bytes())
+	}
+
+	w := msmWindow(len(bases))
+	order := c.Order()
+	windows := make([]*G1, (order.BitLen()+w-1)/w)
+
+	if workers > len(windows) {
+		workers = len(windows)
+	}
+
+	var wg sync.WaitGroup
+	for j := 0; j < workers; j++ {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			for i := j; i < len(windows); i += workers {
+				windows[i] = c.msmWindowSum(bases, ks, uint(i*w), uint(w))
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	// Horner's rule from the top window, doubling w times in between
+	var res *G1
+	for i := len(windows) - 1; i >= 0; i-- {
+		for j := 0; res != nil && j < w; j++ {
+			res.Add(res.Copy())
+		}
+
+		switch {
+		case windows[i] == nil:
+		case res == nil:
+			res = windows[i]
+		default:
+			res.Add(windows[i])
+		}
+	}
+
+	if res == nil {
+		return c.InfinityG1()
+	}
+
+	return res
+}
+
+// msmWindowSum returns the sum of [d]bases[i], where d is the w-bit digit
+// of ks[i] starting at bit off, or nil if all the digits are zero.
+func (c *Curve) msmWindowSum(bases []*G1, ks []*big.Int, off, w uint) *G1 {
+	buckets := make([]*G1, 1<<w-1)
+	for i, k := range ks {
+		d := msmDigit(k, off, w)
+		if d == 0 {
+			continue
+		}
+
+		if buckets[d-1] == nil {
+			buckets[d-1] = bases[i].Copy()
+		} else {
+			buckets[d-1].Add(bases[i])
+		}
+	}
+
+	// sum_d [d]buckets[d-1] as the sum of the running sums from the top
+	var running, sum *G1
+	for d := len(buckets) - 1; d >= 0; d-- {
+		if buckets[d] != nil {
+			if running == nil {
+				running = buckets[d]
+			} else {
+				running.Add(buckets[d])
+			}
+		}
+
+		if running == nil {
+			continue
+		}
+
+		if sum == nil {
+			sum = running.Copy()
+		} else {
+			sum.Add(running)
+		}
+	}
+
+	return sum
+}
+
+func msmDigit(k *big.Int, off, w uint) int {
+	d := 0
+	for b := w; b > 0; b-- {
+		d = d<<1 | int(k.Bit(int(off+b-1)))
+	}
+
+	return d
+}
+
+// msmWindow picks the window size in bits for n points, about log2(n) - 2.
+func msmWindow(n int) int {
+	w := bits.Len(uint(n)) - 2
+	if w < 1 {
+		return 1
+	}
+	if w > 16 {
+		return 16
+	}
+
+	return w
+}
+
+// gtWindow is the window size in bits of ProductOfExpGt.
+const gtWindow = 4
+
+// ProductOfExpGt returns the product of bases[i]^exps[i], e.g. to combine
+// the results of pairings in a verifier. All the bases share the squarings
+// of a single windowed exponentiation. It fails with ErrInvalidLength if
+// the slices do not have the same length and with ErrNotInSubgroup if a
+// base is not in the group Gt of order GroupOrder, as is the output of a
+// pairing that did not go through FExp.
+func (c *Curve) ProductOfExpGt(bases []*Gt, exps []*Zr) (*Gt, error) {
+	const op = "Gt product of powers"
+
+	if !c.SupportsPairing() {
+		return nil, ErrUnsupported
+	}
+
+	if len(bases) != len(exps) {
+		return nil, lengthError(op, c.curveID, len(exps), len(bases))
+	}
+
+	for i, b := range bases {
+		if b.curve != c {
+			return nil, fmt.Errorf("mathlib: %s on %s: %w: base %d is on %s", op, curveName(c.curveID), ErrWrongCurve, i, curveName(b.CurveID()))
+		}
+
+		if !c.inGt(b.gt) {
+			return nil, fmt.Errorf("mathlib: %s on %s: %w: base %d", op, curveName(c.curveID), ErrNotInSubgroup, i)
+		}
+	}
+
+	// tables[i][d-1] = bases[i]^d
+	tables := make([][]driver.Gt, len(bases))
+	ks := make([]*big.Int, len(exps))
+	for i, b := range bases {
+		tables[i] = make([]driver.Gt, 1<<gtWindow-1)
+		for d := range tables[i] {
+			tables[i][d] = c.IdentityGt().gt
+			if d > 0 {
+				tables[i][d].Mul(tables[i][d-1])
+			}
+			tables[i][d].Mul(b.gt)
+		}
+
+		ks[i] = new(big.Int).SetBytes(exps[i].Bytes())
+	}
+
+	bitLen := c.Order().BitLen()
+	res := c.IdentityGt().gt
+	for off := (bitLen + gtWindow - 1) / gtWindow * gtWindow; off > 0; {
+		off -= gtWindow
+		for j := 0; j < gtWindow; j++ {
+			res.Mul(res)
+		}
+
+		for i, k := range ks {
+			if d := msmDigit(k, uint(off), gtWindow); d != 0 {
+				res.Mul(tables[i][d-1])
+			}
+		}
+	}
+
+	return &Gt{gt: res, curve: c}, nil
+}
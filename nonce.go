@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// DeterministicNonce derives a nonce in [1, GroupOrder) from the secret key
+// sk and msg with the HMAC-DRBG of RFC 6979, Section 3.2, instantiated with
+// SHA-256 and the group order as q. domain is fed to it as the additional
+// data k' of Section 3.6, so that an empty domain yields the nonces of
+// RFC 6979 itself. The output only depends on sk, msg and domain, and will
+// not change across releases.
+func (c *Curve) DeterministicNonce(sk *Zr, msg, domain []byte) *Zr {
+	q := c.Order()
+	qlen := q.BitLen()
+	rlen := (qlen + 7) / 8
+
+	bits2int := func(b []byte) *big.Int {
+		v := new(big.Int).SetBytes(b)
+		if blen := 8 * len(b); blen > qlen {
+			v.Rsh(v, uint(blen-qlen))
+		}
+		return v
+	}
+
+	x := new(big.Int).SetBytes(sk.Modded(c.GroupOrder).Bytes())
+	h1 := sha256.Sum256(msg)
+	z := bits2int(h1[:])
+	z.Mod(z, q)
+
+	seed := append(x.FillBytes(make([]byte, rlen)), z.FillBytes(make([]byte, rlen))...)
+	seed = append(seed, domain...)
+
+	v := bytes.Repeat([]byte{0x01}, sha256.Size)
+	k := make([]byte, sha256.Size)
+	k = hmacSHA256(k, v, []byte{0x00}, seed)
+	v = hmacSHA256(k, v)
+	k = hmacSHA256(k, v, []byte{0x01}, seed)
+	v = hmacSHA256(k, v)
+
+	for {
+		var t []byte
+		for 8*len(t) < qlen {
+			v = hmacSHA256(k, v)
+			t = append(t, v...)
+		}
+
+		if n := bits2int(t); n.Sign() > 0 && n.Cmp(q) < 0 {
+			return c.NewZrFromBytes(n.Bytes())
+		}
+
+		k = hmacSHA256(k, v, []byte{0x00})
+		v = hmacSHA256(k, v)
+	}
+}
+
+func hmacSHA256(key []byte, data ...[]byte) []byte {
+	h := hmac.New(sha256.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
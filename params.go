@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// CurveParams is a dump of the parameters of a curve and of the driver
+// behind it, e.g. for bug reports. Unlike CurveInfo, it is available on
+// every curve: the integers that only driver.InfoProvider knows are nil
+// without it.
+type CurveParams struct {
+	// Curve is the name of the curve, see CurveIDToString.
+	Curve string
+	// Driver is the type of the driver implementing the curve.
+	Driver string
+
+	// Modulus is the order of the groups, the modulus of Zr.
+	Modulus      *big.Int
+	FieldModulus *big.Int
+	G1Cofactor   *big.Int
+	G2Cofactor   *big.Int
+
+	CoordByteSize        int
+	G1ByteSize           int
+	CompressedG1ByteSize int
+	G2ByteSize           int
+	CompressedG2ByteSize int
+	GtByteSize           int
+	ScalarByteSize       int
+}
+
+// Params returns the parameters of the curve. The integers are fresh
+// copies.
+func (c *Curve) Params() CurveParams {
+	p := CurveParams{
+		Curve:                CurveIDToString(c.curveID),
+		Driver:               fmt.Sprintf("%T", c.c),
+		Modulus:              c.Order(),
+		CoordByteSize:        c.CoordByteSize,
+		G1ByteSize:           c.G1ByteSize,
+		CompressedG1ByteSize: c.CompressedG1ByteSize,
+		G2ByteSize:           c.G2ByteSize,
+		CompressedG2ByteSize: c.CompressedG2ByteSize,
+		GtByteSize:           c.GtByteSize,
+		ScalarByteSize:       c.ScalarByteSize,
+	}
+
+	if ip, ok := c.c.(driver.InfoProvider); ok {
+		i := ip.Info()
+		p.FieldModulus = new(big.Int).Set(i.BaseFieldModulus)
+		p.G1Cofactor = new(big.Int).Set(i.G1Cofactor)
+		if i.G2Cofactor != nil {
+			p.G2Cofactor = new(big.Int).Set(i.G2Cofactor)
+		}
+	}
+
+	return p
+}
+
+// String returns p one field per line, with the integers in hexadecimal and
+// "unknown" for those the driver does not provide.
+func (p CurveParams) String() string {
+	var sb strings.Builder
+
+	field := func(name string, v interface{}) {
+		fmt.Fprintf(&sb, "%s: %v\n", name, v)
+	}
+	integer := func(name string, n *big.Int) {
+		if n == nil {
+			field(name, "unknown")
+			return
+		}
+		field(name, fmt.Sprintf("%#x", n))
+	}
+
+	field("curve", p.Curve)
+	field("driver", p.Driver)
+	integer("modulus", p.Modulus)
+	integer("field modulus", p.FieldModulus)
+	integer("G1 cofactor", p.G1Cofactor)
+	integer("G2 cofactor", p.G2Cofactor)
+	field("coordinate size", p.CoordByteSize)
+	field("G1 size", p.G1ByteSize)
+	field("compressed G1 size", p.CompressedG1ByteSize)
+	field("G2 size", p.G2ByteSize)
+	field("compressed G2 size", p.CompressedG2ByteSize)
+	field("Gt size", p.GtByteSize)
+	field("scalar size", p.ScalarByteSize)
+
+	return sb.String()
+}
@@ -186,7 +186,7 @@ func Benchmark_Sequential_PedersenCommitmentPoKGurvy(b *testing.B) {
 
 func Benchmark_Sequential_PedersenCommitmentPoK(b *testing.B) {
 
-	for _, curve := range Curves {
+	for _, curve := range builtCurves() {
 		rng, g, h, x, err := pokPedersenCommittmentInit(b, curve)
 		if err != nil {
 			panic(err)
@@ -227,7 +227,11 @@ func Benchmark_Sequential_PedersenCommitmentPoK(b *testing.B) {
 
 func Benchmark_Sequential_BLS(b *testing.B) {
 
-	for _, curve := range Curves {
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
 		g, x, err := blsInit(b, curve)
 		if err != nil {
 			panic(err)
@@ -263,6 +267,47 @@ func Benchmark_Sequential_BLS(b *testing.B) {
 	}
 }
 
+func Benchmark_PairingAccumulator(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g2s := make([]*G2, 4)
+		g1s := make([]*G1, 4)
+		for i := range g1s {
+			g2s[i] = curve.GenG2.Mul(curve.NewRandomZr(rng))
+			g1s[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+		}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("accumulator curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				acc := curve.NewPairingAccumulator()
+				for j := range g1s {
+					acc.AddPair(g2s[j], g1s[j])
+				}
+				acc.Check()
+			}
+		})
+
+		b.Run(fmt.Sprintf("Pairing2 curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := curve.Pairing2(g2s[0], g1s[0], g2s[1], g1s[1])
+				p.Mul(curve.Pairing2(g2s[2], g1s[2], g2s[3], g1s[3]))
+				curve.FExp(p).IsUnity()
+			}
+		})
+	}
+}
+
 func Benchmark_Parallel_BLSGurvy(b *testing.B) {
 	g, x := blsInitGurvy(b)
 	pk := new(bls12381.G2Affine).ScalarMultiplication(g, x)
@@ -363,8 +408,8 @@ func Benchmark_Parallel_BLSKilic(b *testing.B) {
 }
 
 func Benchmark_Parallel_BLS(b *testing.B) {
-	for _, curve := range Curves {
-		if curve.curveID != BLS12_381 && curve.curveID != BLS12_381_GURVY {
+	for _, curve := range builtCurves() {
+		if curve.curveID != BLS12_381 && curve.curveID != BLS12_381_GURVY && curve.curveID != BLS12_381_BLST {
 			continue
 		}
 
@@ -561,3 +606,646 @@ func Benchmark_Parallel_IndividualOpsKilic(b *testing.B) {
 		})
 	})
 }
+
+func Benchmark_MulMany(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		base := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		scalars := make([]*Zr, 500)
+		for i := range scalars {
+			scalars[i] = curve.NewRandomZr(rng)
+		}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("MulMany curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.MulMany(base, scalars)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, s := range scalars {
+					base.Mul(s)
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_MultiScalarMulParallel(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		bases := make([]*G1, 50000)
+		scalars := make([]*Zr, len(bases))
+		for i := range bases {
+			bases[i] = g.Copy()
+			g.Add(curve.GenG1)
+			scalars[i] = curve.NewRandomZr(rng)
+		}
+
+		b.ResetTimer()
+
+		for _, workers := range []int{1, 4, 8} {
+			b.Run(fmt.Sprintf("curve %s workers %d", CurveIDToString(curve.curveID), workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					curve.MultiScalarMulParallel(bases, scalars, workers)
+				}
+			})
+		}
+	}
+}
+
+func Benchmark_MultiScalarMulTasks(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		bases := make([]*G1, 4096)
+		scalars := make([]*Zr, len(bases))
+		for i := range bases {
+			bases[i] = g.Copy()
+			g.Add(curve.GenG1)
+			scalars[i] = curve.NewRandomZr(rng)
+		}
+
+		b.ResetTimer()
+
+		for _, tasks := range []int{1, 2, 4} {
+			b.Run(fmt.Sprintf("curve %s tasks %d", CurveIDToString(curve.curveID), tasks), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					curve.MultiScalarMul(bases, scalars, WithTasks(tasks))
+				}
+			})
+
+			b.Run(fmt.Sprintf("curve %s tasks %d split 4", CurveIDToString(curve.curveID), tasks), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					curve.MultiScalarMul(bases, scalars, WithTasks(tasks), WithSplit(4))
+				}
+			})
+		}
+	}
+}
+
+func Benchmark_LinCombG1(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		for _, n := range []int{3, 16} {
+			points := make([]*G1, n)
+			for i := range points {
+				points[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+			}
+			scalars := curve.NewRandomZrVector(rng, n)
+
+			b.Run(fmt.Sprintf("LinCombG1 curve %s n %d", CurveIDToString(curve.curveID), n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					curve.LinCombG1(points, scalars)
+				}
+			})
+
+			b.Run(fmt.Sprintf("Mul curve %s n %d", CurveIDToString(curve.curveID), n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					res := curve.InfinityG1()
+					for j := range points {
+						res.Add(points[j].Mul(scalars[j]))
+					}
+				}
+			})
+		}
+	}
+}
+
+func Benchmark_AddPairsOfProducts(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		n := 200
+		left := curve.NewRandomZrVector(rng, n)
+		right := curve.NewRandomZrVector(rng, n)
+		leftgen := make([]*G1, n)
+		rightgen := make([]*G1, n)
+		for i := 0; i < n; i++ {
+			leftgen[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+			rightgen[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+		}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("AddPairsOfProducts curve %s n %d", CurveIDToString(curve.curveID), n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.AddPairsOfProducts(left, right, leftgen, rightgen)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul2 curve %s n %d", CurveIDToString(curve.curveID), n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				res := curve.InfinityG1()
+				for j := 0; j < n; j++ {
+					res.Add(leftgen[j].Mul2(left[j], rightgen[j], right[j]))
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_MulInt64(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		two := curve.NewZrFromInt(2)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("MulInt64 curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.MulInt64(2)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.Mul(two)
+			}
+		})
+	}
+}
+
+func Benchmark_BytesInto(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		points := make([]*G1, 10000)
+		for i := range points {
+			points[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+		}
+		buf := make([]byte, len(points)*curve.G1ByteSize)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("BytesInto curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				off := 0
+				for _, p := range points {
+					n, err := p.BytesInto(buf[off:])
+					if err != nil {
+						panic(err)
+					}
+					off += n
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Bytes curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out := make([]byte, 0, len(buf))
+				for _, p := range points {
+					out = append(out, p.Bytes()...)
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_G1String(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g := curve.GenG1.Mul(curve.NewRandomZr(rng))
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("String curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = g.String()
+			}
+		})
+	}
+}
+
+// withRecover runs f the way the decoding functions used to call drivers,
+// i.e. under a deferred recover, to measure what removing it saved
+func withRecover(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failure [%v]", r)
+		}
+	}()
+
+	f()
+	return
+}
+
+func Benchmark_RecoverOverhead(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		r := curve.NewRandomZr(rng)
+		g := curve.GenG1.Mul(r)
+		raw := g.Bytes()
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("NewG1FromBytes curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := curve.NewG1FromBytes(raw); err != nil {
+					panic(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("NewG1FromBytes with recover curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := withRecover(func() { curve.c.NewG1FromBytes(raw) }); err != nil {
+					panic(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.Mul(r)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul with recover curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := withRecover(func() { g.Mul(r) }); err != nil {
+					panic(err)
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_GenG1Mul(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		r := curve.NewRandomZr(rng)
+		curve.GenG1Mul(r)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("GenG1Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.GenG1Mul(r)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.GenG1.Mul(r)
+			}
+		})
+	}
+}
+
+func Benchmark_GenG2Mul(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		r := curve.NewRandomZr(rng)
+		curve.GenG2Mul(r)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("GenG2Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.GenG2Mul(r)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.GenG2.Mul(r)
+			}
+		})
+	}
+}
+
+func Benchmark_GenGt(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("GenGt curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.c.GenGt()
+			}
+		})
+	}
+}
+
+func Benchmark_MulAddG1(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		acc := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		P := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		s := curve.NewRandomZr(rng)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("MulAddG1 curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.MulAddG1(acc, s, P)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Add and Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				acc.Add(P.Mul(s))
+			}
+		})
+	}
+}
+
+func Benchmark_Pairing2FExp(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("Pairing2 and FExp curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.FExp(curve.Pairing2(curve.GenG2, curve.GenG1, curve.GenG2, curve.GenG1))
+			}
+		})
+	}
+}
+
+func Benchmark_GtExp(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		x := curve.FExp(curve.Pairing(curve.GenG2, curve.GenG1))
+		r := curve.NewRandomZr(rng)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("Exp curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				x.Exp(r)
+			}
+		})
+
+		b.Run(fmt.Sprintf("IsUnity curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				x.IsUnity()
+			}
+		})
+	}
+}
+
+func Benchmark_Mul2(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		g := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		h := curve.GenG1.Mul(curve.NewRandomZr(rng))
+		x, r := curve.NewRandomZr(rng), curve.NewRandomZr(rng)
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("Mul2 curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.Mul2(x, h, r)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Mul and Add curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c := g.Mul(x)
+				c.Add(h.Mul(r))
+			}
+		})
+	}
+}
+
+func Benchmark_ModOps(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		xs := []*Zr{curve.NewRandomZr(rng), curve.NewRandomZr(rng), curve.NewRandomZr(rng)}
+		ys := []*Zr{curve.NewRandomZr(rng), curve.NewRandomZr(rng), curve.NewRandomZr(rng)}
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("ModMul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.ModMul(xs[0], ys[0], curve.GroupOrder)
+			}
+		})
+
+		b.Run(fmt.Sprintf("ModAdd curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.ModAdd(xs[0], ys[0], curve.GroupOrder)
+			}
+		})
+
+		b.Run(fmt.Sprintf("ModAddMul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.ModAddMul(xs, ys, curve.GroupOrder)
+			}
+		})
+
+		b.Run(fmt.Sprintf("PowMod curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				xs[0].PowMod(ys[0])
+			}
+		})
+	}
+}
+
+func Benchmark_Mul(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		x := curve.NewRandomZr(rng)
+		g1 := curve.GenG1.Mul(curve.NewRandomZr(rng))
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("NewRandomZr curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.NewRandomZr(rng)
+			}
+		})
+
+		b.Run(fmt.Sprintf("G1 Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g1.Mul(x)
+			}
+		})
+
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		g2 := curve.GenG2.Mul(curve.NewRandomZr(rng))
+		b.Run(fmt.Sprintf("G2 Mul curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g2.Mul(x)
+			}
+		})
+	}
+}
+
+func Benchmark_GtConjugate(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		if !curve.SupportsPairing() {
+			continue
+		}
+
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		x := curve.FExp(curve.Pairing(curve.GenG2, curve.GenG1.Mul(curve.NewRandomZr(rng))))
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("Inverse curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x.Inverse()
+			}
+		})
+
+		b.Run(fmt.Sprintf("Conjugate curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x.Conjugate()
+			}
+		})
+	}
+}
+
+func Benchmark_G1AddDeferred(b *testing.B) {
+
+	for _, curve := range builtCurves() {
+		rng, err := curve.Rand()
+		if err != nil {
+			panic(err)
+		}
+
+		points := make([]*G1, 8)
+		for i := range points {
+			points[i] = curve.GenG1.Mul(curve.NewRandomZr(rng))
+		}
+		acc := curve.GenG1.Mul(curve.NewRandomZr(rng))
+
+		b.ResetTimer()
+
+		b.Run(fmt.Sprintf("Add curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, p := range points {
+					acc.Add(p)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("AddDeferred curve %s", CurveIDToString(curve.curveID)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				acc.AddDeferred(points...)
+			}
+		})
+	}
+}
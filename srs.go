@@ -0,0 +1,36 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+// SRSFromSeed derives the structured reference string [tau^i]G1 and
+// [tau^i]G2, for i from 0 to degree, with tau = HashToZr(seed).
+//
+// INSECURE: anyone who knows the seed knows tau and can forge proofs for the
+// schemes built on the SRS. It is only meant for tests; real deployments need
+// an SRS produced by a trusted setup ceremony. Curves without pairings only
+// return the G1 part.
+func (c *Curve) SRSFromSeed(seed []byte, degree int) (g1 []*G1, g2 []*G2) {
+	tau := c.HashToZr(seed)
+
+	powers := make([]*Zr, degree+1)
+	powers[0] = c.NewZrFromInt(1)
+	for i := 1; i <= degree; i++ {
+		powers[i] = c.ModMul(powers[i-1], tau, c.GroupOrder)
+	}
+
+	g1 = c.MulMany(c.GenG1, powers)
+	if !c.SupportsPairing() {
+		return g1, nil
+	}
+
+	g2 = make([]*G2, len(powers))
+	for i, p := range powers {
+		g2[i] = c.GenG2.Mul(p)
+	}
+
+	return g1, g2
+}
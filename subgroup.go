@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/IBM/mathlib/driver"
+)
+
+// batchCoefficientSize is the size in bytes of the random coefficients of
+// the batch subgroup checks.
+const batchCoefficientSize = 16
+
+// BatchInSubgroupG1 reports whether all of pts lie in the subgroup of prime
+// order GroupOrder. If CofactorG1 is one, or a prime larger than the
+// coefficients, it does so at the cost of a single check: it tests the sum
+// of the points with random 128-bit coefficients drawn from rng. A point
+// outside the subgroup only goes unnoticed if its coefficient cancels its
+// component outside the subgroup, i.e. with probability at most 2^-128.
+// Other cofactors, such as those of the BLS curves, have small prime
+// factors q, for which that probability would be 1/q: on those curves it
+// checks each point on its own, with the subgroup test of the driver if it
+// implements driver.SubgroupChecker. Either way, it returns false if
+// reading rng fails.
+func (c *Curve) BatchInSubgroupG1(pts []*G1, rng io.Reader) bool {
+	if len(pts) == 0 {
+		return true
+	}
+
+	coeffs, err := c.batchCoefficients(len(pts), rng)
+	if err != nil {
+		return false
+	}
+
+	if !batchable(c.CofactorG1()) {
+		for _, p := range pts {
+			if !c.inSubgroupG1(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return c.inSubgroupG1(c.LinCombG1(pts, coeffs))
+}
+
+// BatchInSubgroupG2 is BatchInSubgroupG1 for G2, with CofactorG2.
+func (c *Curve) BatchInSubgroupG2(pts []*G2, rng io.Reader) bool {
+	if len(pts) == 0 {
+		return true
+	}
+
+	coeffs, err := c.batchCoefficients(len(pts), rng)
+	if err != nil {
+		return false
+	}
+
+	if !batchable(c.CofactorG2()) {
+		for _, p := range pts {
+			if !c.inSubgroupG2(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	sum := c.InfinityG2()
+	for i, p := range pts {
+		sum.Add(p.Mul(coeffs[i]))
+	}
+
+	return c.inSubgroupG2(sum)
+}
+
+// batchable reports whether the cofactor h leaves no room for a random
+// linear combination to cancel components outside the subgroup: h is one,
+// or a prime larger than the coefficients. An unknown cofactor is not.
+func batchable(h *big.Int) bool {
+	if h == nil {
+		return false
+	}
+
+	return h.Cmp(big.NewInt(1)) == 0 || (h.BitLen() > 8*batchCoefficientSize && h.ProbablyPrime(20))
+}
+
+func (c *Curve) inSubgroupG1(p *G1) bool {
+	if sc, ok := p.g1.(driver.SubgroupChecker); ok {
+		return sc.IsInSubGroup()
+	}
+
+	res := p.Mul(c.orderMinusOne())
+	res.Add(p)
+	return res.IsInfinity()
+}
+
+func (c *Curve) inSubgroupG2(p *G2) bool {
+	if sc, ok := p.g2.(driver.SubgroupChecker); ok {
+		return sc.IsInSubGroup()
+	}
+
+	res := p.Mul(c.orderMinusOne())
+	res.Add(p)
+	return res.Equals(c.InfinityG2())
+}
+
+func (c *Curve) batchCoefficients(n int, rng io.Reader) ([]*Zr, error) {
+	b := make([]byte, n*batchCoefficientSize)
+	if _, err := io.ReadFull(rng, b); err != nil {
+		return nil, err
+	}
+
+	coeffs := make([]*Zr, n)
+	for i := range coeffs {
+		coeffs[i] = c.NewZrFromBytes(b[i*batchCoefficientSize : (i+1)*batchCoefficientSize])
+	}
+
+	return coeffs, nil
+}
+
+// inGt reports whether g^GroupOrder is the unity, computed as
+// g^(GroupOrder-1) * g.
+func (c *Curve) inGt(g driver.Gt) bool {
+	t := g.Exp(c.orderMinusOne().zr)
+	t.Mul(g)
+	return t.IsUnity()
+}
+
+// orderMinusOne returns GroupOrder - 1: multiplying by the order itself
+// does not work, as drivers reduce scalars first.
+func (c *Curve) orderMinusOne() *Zr {
+	return c.GroupOrder.Minus(c.NewZrFromInt(1))
+}
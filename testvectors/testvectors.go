@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package testvectors provides known-answer test vectors for the curves of
+// mathlib, so that applications can check their use of the library against
+// fixed values. The vectors are generated by Generate and checked in as
+// vectors.json; the tests of this package make sure that they still match
+// the output of the library.
+package testvectors
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	math "github.com/IBM/mathlib"
+)
+
+// HashMessage and HashDomain are the inputs of the HashToG1 vectors
+var (
+	HashMessage = []byte("abc")
+	HashDomain  = []byte("DST")
+)
+
+// Vector holds the known answers for a curve. Fields that do not apply to
+// a curve, e.g. the pairing for a curve that does not support it, are nil.
+type Vector struct {
+	// Curve is the name returned by math.CurveIDToString
+	Curve string `json:"curve"`
+	// GroupOrder is Curve.Order on ScalarByteSize bytes
+	GroupOrder Bytes `json:"group_order"`
+	// GenG1 is GenG1.Compressed()
+	GenG1 Bytes `json:"gen_g1"`
+	// GenG2 is GenG2.Compressed()
+	GenG2 Bytes `json:"gen_g2,omitempty"`
+	// HashToG1 is HashToG1WithDomain(HashMessage, HashDomain).Compressed()
+	HashToG1 Bytes `json:"hash_to_g1"`
+	// Pairing is FExp(Pairing(GenG2, GenG1)).Bytes()
+	Pairing Bytes `json:"pairing,omitempty"`
+}
+
+// Bytes is a byte slice encoded in hex in JSON
+type Bytes []byte
+
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (b *Bytes) UnmarshalText(text []byte) error {
+	d, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*b = d
+	return nil
+}
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+var vectors []Vector
+
+func init() {
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		panic(fmt.Sprintf("invalid test vectors [%s]", err.Error()))
+	}
+}
+
+// All returns the vectors of all the curves in math.Curves, in the same order.
+func All() []Vector {
+	res := make([]Vector, len(vectors))
+	copy(res, vectors)
+	return res
+}
+
+// ForCurve returns the vector of the curve with the given id.
+func ForCurve(id math.CurveID) (Vector, error) {
+	if id < 0 || int(id) >= len(vectors) {
+		return Vector{}, fmt.Errorf("no test vector for curve %d", id)
+	}
+
+	return vectors[id], nil
+}
+
+// Generate computes the vector of c from the live code.
+func Generate(c *math.Curve) Vector {
+	v := Vector{
+		Curve:      math.CurveIDToString(c.GenG1.CurveID()),
+		GroupOrder: c.Order().FillBytes(make([]byte, c.ScalarByteSize)),
+		GenG1:      c.GenG1.Compressed(),
+		HashToG1:   c.HashToG1WithDomain(HashMessage, HashDomain).Compressed(),
+	}
+
+	if c.SupportsPairing() {
+		v.GenG2 = c.GenG2.Compressed()
+		v.Pairing = c.FExp(c.Pairing(c.GenG2, c.GenG1)).Bytes()
+	}
+
+	return v
+}
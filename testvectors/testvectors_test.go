@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package testvectors
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	math "github.com/IBM/mathlib"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors.json")
+
+func TestVectors(t *testing.T) {
+	generated := make([]Vector, len(math.Curves))
+	for i, c := range math.Curves {
+		if c == nil {
+			// not part of this build, see math.Curves
+			generated[i] = vectors[i]
+			continue
+		}
+		generated[i] = Generate(c)
+	}
+
+	if *update {
+		b, err := json.MarshalIndent(generated, "", "\t")
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile("vectors.json", append(b, '\n'), 0o644))
+		return
+	}
+
+	assert.Equal(t, generated, All())
+
+	for _, c := range math.Curves {
+		if c == nil {
+			continue
+		}
+
+		v, err := ForCurve(c.GenG1.CurveID())
+		assert.NoError(t, err)
+		assert.Equal(t, Generate(c), v)
+	}
+
+	_, err := ForCurve(math.CurveID(len(math.Curves)))
+	assert.Error(t, err)
+}
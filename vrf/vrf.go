@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package vrf implements a verifiable random function on pairing-friendly
+// curves: the proof for msg is the BLS signature sk * H(msg) in G1, checked
+// against the public key sk * g2 with a pairing, and the output is the hash
+// of the proof. As BLS signatures are unique, so are the outputs.
+package vrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	math "github.com/IBM/mathlib"
+)
+
+var (
+	// hashToG1Domain separates the hash to G1 of the VRF from other uses
+	hashToG1Domain = []byte("MATHLIB-VRF-V01-H2G1")
+
+	// outputDomain separates the hash of the proof from other uses
+	outputDomain = []byte("MATHLIB-VRF-V01-OUTPUT")
+)
+
+// Prove returns the output of the VRF keyed by sk on msg, together with the
+// proof that it was computed correctly. The curve is the one of sk.
+func Prove(sk *math.Zr, msg []byte) (output []byte, proof *math.G1) {
+	c := math.Curves[sk.CurveID()]
+
+	proof = c.HashToG1WithDomain(msg, hashToG1Domain).Mul(sk)
+
+	return hashProof(proof), proof
+}
+
+// Verify checks that output is the output of the VRF on msg for the public
+// key pk = sk * g2, using proof. It returns false if the curve of pk does
+// not support pairings.
+func Verify(pk *math.G2, msg, output []byte, proof *math.G1) bool {
+	if pk == nil || proof == nil || pk.CurveID() != proof.CurveID() {
+		return false
+	}
+
+	c := math.Curves[pk.CurveID()]
+	if !c.SupportsPairing() || proof.IsInfinity() {
+		return false
+	}
+
+	if !hmac.Equal(output, hashProof(proof)) {
+		return false
+	}
+
+	// e(pk, H(msg)) * e(g2, -proof) == 1
+	negProof := proof.Copy()
+	negProof.Neg()
+	h := c.HashToG1WithDomain(msg, hashToG1Domain)
+
+	return c.FExp(c.Pairing2(pk, h, c.GenG2, negProof)).IsUnity()
+}
+
+func hashProof(proof *math.G1) []byte {
+	h := sha256.New()
+	h.Write(outputDomain)
+	h.Write(proof.Bytes())
+
+	return h.Sum(nil)
+}
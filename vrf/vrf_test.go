@@ -0,0 +1,61 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vrf
+
+import (
+	"fmt"
+	"testing"
+
+	math "github.com/IBM/mathlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVRF(t *testing.T) {
+	for _, c := range math.Curves {
+		if c == nil || !c.SupportsPairing() {
+			continue
+		}
+
+		t.Run(math.CurveIDToString(c.GenG1.CurveID()), func(t *testing.T) {
+			rng, err := c.Rand()
+			assert.NoError(t, err)
+
+			sk := c.NewRandomZr(rng)
+			pk := c.GenG2.Mul(sk)
+			msg := []byte("msg")
+
+			output, proof := Prove(sk, msg)
+			assert.Len(t, output, 32)
+			assert.True(t, Verify(pk, msg, output, proof), fmt.Sprintf("failed with curve %s", math.CurveIDToString(c.GenG1.CurveID())))
+
+			// the output is deterministic
+			output2, proof2 := Prove(sk, msg)
+			assert.Equal(t, output, output2)
+			assert.True(t, proof.Equals(proof2))
+
+			// and depends on the message and on the key
+			other, _ := Prove(sk, []byte("other msg"))
+			assert.NotEqual(t, output, other)
+			other, _ = Prove(c.NewRandomZr(rng), msg)
+			assert.NotEqual(t, output, other)
+
+			// tampered proofs are rejected
+			tampered := proof.Copy()
+			tampered.Add(c.GenG1)
+			assert.False(t, Verify(pk, msg, output, tampered))
+			assert.False(t, Verify(pk, msg, hashProof(tampered), tampered))
+			assert.False(t, Verify(pk, msg, output, c.NewG1()))
+
+			// as are wrong outputs, messages and keys
+			bad := append([]byte{}, output...)
+			bad[0] ^= 1
+			assert.False(t, Verify(pk, msg, bad, proof))
+			assert.False(t, Verify(pk, []byte("other msg"), output, proof))
+			assert.False(t, Verify(c.GenG2, msg, output, proof))
+		})
+	}
+}
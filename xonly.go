@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package math
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BytesXOnly returns the affine x coordinate of g, CoordByteSize bytes
+// big-endian, which NewG1FromXOnly reads back together with the parity of
+// y, g.Y().Bytes()[CoordByteSize-1]&1. It returns nil for the point at
+// infinity and on curves whose encoding does not hold the coordinates.
+func (g *G1) BytesXOnly() []byte {
+	x := g.X()
+	if x == nil {
+		return nil
+	}
+
+	return x.Bytes()
+}
+
+// NewG1FromXOnly returns the point of G1 with x coordinate x, as returned by
+// BytesXOnly, and the y coordinate of parity yIsOdd, which it recovers from
+// the curve equation. It fails with ErrNotOnCurve if no point has that x,
+// and with ErrNotInSubgroup if the point lies outside G1. It returns
+// ErrUnsupported on curves whose encoding does not hold the coordinates.
+func (c *Curve) NewG1FromXOnly(x []byte, yIsOdd bool) (*G1, error) {
+	if !c.G1Layout.XY {
+		return nil, ErrUnsupported
+	}
+
+	fx, err := c.NewFpFromBytes(x)
+	if err != nil {
+		return nil, err
+	}
+
+	a, b := c.equation()
+	if a == nil {
+		return nil, ErrUnsupported
+	}
+
+	y, ok := fx.Mul(fx).Add(a).Mul(fx).Add(b).Sqrt()
+	if !ok {
+		return nil, fmt.Errorf("mathlib: G1 decode on %s: %w: no point has this x", curveName(c.curveID), ErrNotOnCurve)
+	}
+
+	if yb := y.Bytes(); (yb[len(yb)-1]&1 == 1) != yIsOdd {
+		y = y.Neg()
+	}
+
+	return c.NewG1FromXY(new(big.Int).SetBytes(fx.Bytes()), new(big.Int).SetBytes(y.Bytes()))
+}
+
+// equation returns the coefficients a and b of y^2 = x^3 + a*x + b, which
+// the generator and its double determine, or nils if the driver provides no
+// base field arithmetic.
+func (c *Curve) equation() (a, b *Fp) {
+	c.equationOnce.Do(func() {
+		p, q := c.GenG1, c.GenG1.Mul(c.NewZrFromInt(2))
+		x1, y1, x2, y2 := p.X(), p.Y(), q.X(), q.Y()
+		if x1 == nil || x2 == nil {
+			return
+		}
+
+		// y^2 - x^3 = a*x + b at both points
+		d1 := y1.Mul(y1).Sub(x1.Mul(x1).Mul(x1))
+		d2 := y2.Mul(y2).Sub(x2.Mul(x2).Mul(x2))
+		inv, err := x1.Sub(x2).Inverse()
+		if err != nil {
+			return
+		}
+
+		c.equationA = d1.Sub(d2).Mul(inv)
+		c.equationB = d1.Sub(c.equationA.Mul(x1))
+	})
+
+	return c.equationA, c.equationB
+}